@@ -6,3 +6,12 @@ data "ipam_environment" "example" {
 output "environment_name" {
   value = data.ipam_environment.example.name
 }
+
+# Or by name, with its blocks and utilization.
+data "ipam_environment" "prod" {
+  name = "prod"
+}
+
+output "prod_utilization" {
+  value = data.ipam_environment.prod.utilization_percent
+}
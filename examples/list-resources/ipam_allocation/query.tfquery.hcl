@@ -0,0 +1,6 @@
+list "ipam_allocation" "all" {
+  provider = ipam
+  config {
+    block_name = "prod-vpc"
+  }
+}
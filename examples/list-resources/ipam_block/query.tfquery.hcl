@@ -0,0 +1,6 @@
+list "ipam_block" "all" {
+  provider = ipam
+  config {
+    orphaned_only = true
+  }
+}
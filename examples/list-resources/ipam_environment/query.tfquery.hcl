@@ -0,0 +1,6 @@
+list "ipam_environment" "all" {
+  provider = ipam
+  config {
+    name = "prod"
+  }
+}
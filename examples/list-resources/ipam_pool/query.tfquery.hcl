@@ -0,0 +1,6 @@
+list "ipam_pool" "all" {
+  provider = ipam
+  config {
+    environment_id = "550e8400-e29b-41d4-a716-446655440000"
+  }
+}
@@ -0,0 +1,4 @@
+list "ipam_reserved_block" "all" {
+  provider = ipam
+  config {}
+}
@@ -1,3 +1,5 @@
-# Import an existing allocation by UUID.
+# Import an existing allocation by UUID, as block-name/allocation-name, or by CIDR.
 # terraform import ipam_allocation.example <allocation-uuid>
 terraform import ipam_allocation.example 550e8400-e29b-41d4-a716-446655440000
+terraform import ipam_allocation.example prod-vpc/app-subnet
+terraform import ipam_allocation.example 10.0.1.0/24
@@ -46,6 +46,19 @@ resource "ipam_allocation" "auto_cluster" {
   prefix_length  = 24
 }
 
+# Auto-allocate example 3: constrained placement. The provider picks a /24 in the
+# upper half of the block, never in 10.200.0.0/16, in the smallest free range that
+# fits, with at least 256 free addresses on either side.
+resource "ipam_allocation" "auto_constrained" {
+  name          = "cluster-us-west-1b"
+  block_name    = ipam_block.example.name
+  prefix_length = 24
+  within_cidr   = "10.128.0.0/9"
+  exclude_cidrs = ["10.200.0.0/16"]
+  strategy      = "best_fit"
+  min_gap       = 256
+}
+
 # IPv6 ULA allocation (explicit CIDR)
 resource "ipam_allocation" "ula_subnet" {
   name       = "prod-ula-subnet"
@@ -53,7 +66,7 @@ resource "ipam_allocation" "ula_subnet" {
   cidr       = "fd00::/64"
 }
 
-# Auto-allocate example 3: next available /64 in the IPv6 ULA block.
+# Auto-allocate example 4: next available /64 in the IPv6 ULA block.
 resource "ipam_allocation" "auto_ula_subnet" {
   name           = "prod-ula-auto-subnet"
   block_name     = ipam_block.example_ula.name
@@ -0,0 +1,2 @@
+# Import existing allocations in block prod-vpc as the members of a set.
+terraform import ipam_allocation_set.vpc prod-vpc/transit,public-1,public-2
@@ -0,0 +1,36 @@
+# Carve a VPC layout out of one block in a single operation.
+resource "ipam_environment" "example" {
+  name = "prod"
+  pools = [
+    {
+      name = "prod-pool"
+      cidr = "10.0.0.0/8"
+    }
+  ]
+}
+
+resource "ipam_block" "example" {
+  name           = "prod-vpc"
+  cidr           = "10.0.0.0/16"
+  environment_id = ipam_environment.example.id
+  pool_id        = ipam_environment.example.pool_ids[0]
+}
+
+resource "ipam_allocation_set" "vpc" {
+  block_name = ipam_block.example.name
+
+  # Named members: name => prefix length.
+  allocations = {
+    "transit" = 28
+  }
+
+  # Tiers: creates public-1..public-3 (/24) and private-1..private-3 (/20).
+  tiers = [
+    { name = "public", prefix_length = 24, count = 3 },
+    { name = "private", prefix_length = 20, count = 3 },
+  ]
+}
+
+output "subnet_cidrs" {
+  value = ipam_allocation_set.vpc.cidrs
+}
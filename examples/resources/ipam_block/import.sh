@@ -1,3 +1,5 @@
-# Import an existing block by UUID.
+# Import an existing block by UUID, as env-name/block-name, or by CIDR.
 # terraform import ipam_block.example <block-uuid>
 terraform import ipam_block.example 550e8400-e29b-41d4-a716-446655440000
+terraform import ipam_block.example prod/prod-vpc
+terraform import ipam_block.example 10.0.0.0/16
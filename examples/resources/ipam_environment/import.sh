@@ -1,3 +1,5 @@
-# Import an existing environment by UUID.
+# Import an existing environment by UUID or by name, optionally followed by the pools it manages.
 # terraform import ipam_environment.example <environment-uuid>
 terraform import ipam_environment.example 550e8400-e29b-41d4-a716-446655440000
+terraform import ipam_environment.example prod
+terraform import ipam_environment.example prod/prod-pool,prod-v6
@@ -1,3 +1,4 @@
-# Import an existing reserved block by UUID (admin only).
+# Import an existing reserved block by UUID or by CIDR (admin only).
 # terraform import ipam_reserved_block.example <reserved-block-uuid>
 terraform import ipam_reserved_block.example 550e8400-e29b-41d4-a716-446655440000
+terraform import ipam_reserved_block.example 10.255.0.0/16
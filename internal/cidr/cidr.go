@@ -0,0 +1,422 @@
+// Package cidr implements the address arithmetic and packing used by the provider when it
+// has to place subnets itself (auto-allocation constraints, allocation sets, plan-time
+// previews). Addresses are handled as math/big integers so IPv4 and IPv6 share one code path.
+package cidr
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Strategy selects where in the free space a new prefix is placed.
+type Strategy string
+
+const (
+	// FirstFit places the prefix at the lowest free aligned address (the API's bin-packing default).
+	FirstFit Strategy = "first_fit"
+	// BestFit places the prefix in the smallest free range that can hold it, keeping large ranges intact.
+	BestFit Strategy = "best_fit"
+	// LastFit places the prefix at the highest free aligned address.
+	LastFit Strategy = "last_fit"
+	// Hash places the prefix at a candidate chosen by a stable hash of Options.Key.
+	Hash Strategy = "hash"
+)
+
+// Strategies lists the supported placement strategies.
+var Strategies = []Strategy{FirstFit, BestFit, LastFit, Hash}
+
+// ValidStrategy reports whether s is a supported placement strategy.
+func ValidStrategy(s string) bool {
+	for _, v := range Strategies {
+		if string(v) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrExhausted is returned when no free range can hold the requested prefix.
+var ErrExhausted = errors.New("no free space for the requested prefix length")
+
+// Options constrains where Find may place a prefix.
+type Options struct {
+	// Within limits placement to a sub-range of the parent. The zero value means the whole parent.
+	Within netip.Prefix
+	// Exclude lists ranges that must never be used.
+	Exclude []netip.Prefix
+	// Strategy selects the placement; empty means FirstFit.
+	Strategy Strategy
+	// Key seeds the Hash strategy (typically the allocation name).
+	Key string
+	// MinGap is the minimum number of free addresses kept between the new prefix and any used prefix.
+	MinGap uint64
+}
+
+// Parse parses s as a CIDR prefix and rejects prefixes with host bits set (e.g. 10.0.0.1/24).
+func Parse(s string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: %w", s, err)
+	}
+	if p.Masked() != p {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: host bits set (network address is %s)", s, p.Masked())
+	}
+	return p, nil
+}
+
+// ParseAll parses every entry of list with Parse.
+func ParseAll(list []string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		p, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// interval is an inclusive address range held as integers.
+type interval struct {
+	first, last *big.Int
+}
+
+func addrToInt(a netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(a.AsSlice())
+}
+
+func intToAddr(n *big.Int, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		n.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	n.FillBytes(b[:])
+	return netip.AddrFrom16(b)
+}
+
+// prefixSize returns the number of addresses in a prefix of the given length in family is4.
+func prefixSize(bits int, is4 bool) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(addrBits(is4)-bits))
+}
+
+func addrBits(is4 bool) int {
+	if is4 {
+		return 32
+	}
+	return 128
+}
+
+func toInterval(p netip.Prefix) interval {
+	p = p.Masked()
+	first := addrToInt(p.Addr())
+	last := new(big.Int).Add(first, prefixSize(p.Bits(), p.Addr().Is4()))
+	last.Sub(last, big.NewInt(1))
+	return interval{first: first, last: last}
+}
+
+// sameFamily reports whether p is in the same address family as parent.
+func sameFamily(parent, p netip.Prefix) bool {
+	return p.IsValid() && parent.Addr().Is4() == p.Addr().Is4()
+}
+
+// merge sorts intervals and coalesces overlapping or adjacent ones.
+func merge(in []interval) []interval {
+	if len(in) == 0 {
+		return nil
+	}
+	sort.Slice(in, func(i, j int) bool { return in[i].first.Cmp(in[j].first) < 0 })
+	out := []interval{{first: new(big.Int).Set(in[0].first), last: new(big.Int).Set(in[0].last)}}
+	one := big.NewInt(1)
+	for _, iv := range in[1:] {
+		cur := &out[len(out)-1]
+		next := new(big.Int).Add(cur.last, one)
+		if iv.first.Cmp(next) <= 0 {
+			if iv.last.Cmp(cur.last) > 0 {
+				cur.last.Set(iv.last)
+			}
+			continue
+		}
+		out = append(out, interval{first: new(big.Int).Set(iv.first), last: new(big.Int).Set(iv.last)})
+	}
+	return out
+}
+
+// subtract returns the parts of bounds not covered by the (merged, sorted) blocked intervals.
+func subtract(bounds interval, blocked []interval) []interval {
+	var out []interval
+	one := big.NewInt(1)
+	cursor := new(big.Int).Set(bounds.first)
+	for _, b := range blocked {
+		if b.last.Cmp(cursor) < 0 {
+			continue
+		}
+		if b.first.Cmp(bounds.last) > 0 {
+			break
+		}
+		if b.first.Cmp(cursor) > 0 {
+			out = append(out, interval{first: new(big.Int).Set(cursor), last: new(big.Int).Sub(b.first, one)})
+		}
+		cursor = new(big.Int).Add(b.last, one)
+		if cursor.Cmp(bounds.last) > 0 {
+			return out
+		}
+	}
+	if cursor.Cmp(bounds.last) <= 0 {
+		out = append(out, interval{first: cursor, last: new(big.Int).Set(bounds.last)})
+	}
+	return out
+}
+
+// slots describes the aligned positions for a prefix of one size inside a free interval.
+type slots struct {
+	free        interval
+	first, last *big.Int // first and last aligned start address
+	count       *big.Int
+}
+
+func alignedSlots(free interval, size *big.Int) (slots, bool) {
+	first := new(big.Int).Add(free.first, size)
+	first.Sub(first, big.NewInt(1))
+	first.Div(first, size)
+	first.Mul(first, size)
+	last := new(big.Int).Add(free.last, big.NewInt(1))
+	last.Div(last, size)
+	last.Mul(last, size)
+	last.Sub(last, size)
+	if first.Cmp(last) > 0 {
+		return slots{}, false
+	}
+	count := new(big.Int).Sub(last, first)
+	count.Div(count, size)
+	count.Add(count, big.NewInt(1))
+	return slots{free: free, first: first, last: last, count: count}, true
+}
+
+// Find returns a free prefix of length bits inside parent that does not overlap any used prefix,
+// honouring opts. Used and excluded prefixes of a different address family are ignored.
+func Find(parent netip.Prefix, used []netip.Prefix, bits int, opts Options) (netip.Prefix, error) {
+	parent = parent.Masked()
+	is4 := parent.Addr().Is4()
+	if bits < parent.Bits() || bits > addrBits(is4) {
+		return netip.Prefix{}, fmt.Errorf("prefix length /%d does not fit in %s", bits, parent)
+	}
+	bounds := toInterval(parent)
+	if opts.Within.IsValid() {
+		within := opts.Within.Masked()
+		if !sameFamily(parent, within) || within.Bits() < parent.Bits() || !parent.Contains(within.Addr()) {
+			return netip.Prefix{}, fmt.Errorf("%s is not within %s", opts.Within, parent)
+		}
+		bounds = toInterval(within)
+	}
+
+	gap := new(big.Int).SetUint64(opts.MinGap)
+	var blocked []interval
+	for _, u := range used {
+		if !sameFamily(parent, u) {
+			continue
+		}
+		iv := toInterval(u)
+		iv.first.Sub(iv.first, gap)
+		iv.last.Add(iv.last, gap)
+		blocked = append(blocked, iv)
+	}
+	for _, x := range opts.Exclude {
+		if sameFamily(parent, x) {
+			blocked = append(blocked, toInterval(x))
+		}
+	}
+
+	size := prefixSize(bits, is4)
+	var candidates []slots
+	for _, free := range subtract(bounds, merge(blocked)) {
+		if s, ok := alignedSlots(free, size); ok {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return netip.Prefix{}, ErrExhausted
+	}
+
+	var start *big.Int
+	switch opts.Strategy {
+	case "", FirstFit:
+		start = candidates[0].first
+	case LastFit:
+		start = candidates[len(candidates)-1].last
+	case BestFit:
+		best := candidates[0]
+		bestLen := new(big.Int).Sub(best.free.last, best.free.first)
+		for _, c := range candidates[1:] {
+			l := new(big.Int).Sub(c.free.last, c.free.first)
+			if l.Cmp(bestLen) < 0 {
+				best, bestLen = c, l
+			}
+		}
+		start = best.first
+	case Hash:
+		total := new(big.Int)
+		for _, c := range candidates {
+			total.Add(total, c.count)
+		}
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(opts.Key))
+		idx := new(big.Int).Mod(new(big.Int).SetUint64(h.Sum64()), total)
+		for _, c := range candidates {
+			if idx.Cmp(c.count) < 0 {
+				start = new(big.Int).Mul(idx, size)
+				start.Add(start, c.first)
+				break
+			}
+			idx.Sub(idx, c.count)
+		}
+	default:
+		return netip.Prefix{}, fmt.Errorf("unknown strategy %q", opts.Strategy)
+	}
+	return netip.PrefixFrom(intToAddr(start, is4), bits), nil
+}
+
+// Request is one named prefix to place with Carve.
+type Request struct {
+	Name string
+	Bits int
+}
+
+// Carve places every request inside parent without overlapping used or each other. Larger
+// prefixes are placed first (ties broken by name) using first-fit, so the result depends only
+// on the inputs and not on the order they were given in.
+func Carve(parent netip.Prefix, used []netip.Prefix, reqs []Request) (map[string]netip.Prefix, error) {
+	ordered := append([]Request(nil), reqs...)
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Bits != ordered[j].Bits {
+			return ordered[i].Bits < ordered[j].Bits
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+	taken := append([]netip.Prefix(nil), used...)
+	out := make(map[string]netip.Prefix, len(ordered))
+	for _, r := range ordered {
+		if _, dup := out[r.Name]; dup {
+			return nil, fmt.Errorf("duplicate name %q", r.Name)
+		}
+		p, err := Find(parent, taken, r.Bits, Options{})
+		if err != nil {
+			return nil, fmt.Errorf("%s (/%d): %w", r.Name, r.Bits, err)
+		}
+		out[r.Name] = p
+		taken = append(taken, p)
+	}
+	return out, nil
+}
+
+// Free returns the space of parent not covered by any used prefix, as the fewest prefixes that
+// cover it exactly, in address order. Used prefixes of a different address family are ignored.
+func Free(parent netip.Prefix, used []netip.Prefix) []netip.Prefix {
+	parent = parent.Masked()
+	is4 := parent.Addr().Is4()
+	var blocked []interval
+	for _, u := range used {
+		if sameFamily(parent, u) {
+			blocked = append(blocked, toInterval(u))
+		}
+	}
+	var out []netip.Prefix
+	for _, free := range subtract(toInterval(parent), merge(blocked)) {
+		out = append(out, cover(free, is4)...)
+	}
+	return out
+}
+
+// cover splits an interval into the fewest aligned prefixes, largest first at each address.
+func cover(iv interval, is4 bool) []netip.Prefix {
+	width := addrBits(is4)
+	one := big.NewInt(1)
+	var out []netip.Prefix
+	cur := new(big.Int).Set(iv.first)
+	for cur.Cmp(iv.last) <= 0 {
+		// The largest prefix starting at cur is limited by its alignment and the space left.
+		host := width
+		if cur.Sign() != 0 {
+			host = int(cur.TrailingZeroBits())
+		}
+		remaining := new(big.Int).Sub(iv.last, cur)
+		remaining.Add(remaining, one)
+		for host > 0 && prefixSize(width-host, is4).Cmp(remaining) > 0 {
+			host--
+		}
+		out = append(out, netip.PrefixFrom(intToAddr(cur, is4), width-host))
+		cur.Add(cur, prefixSize(width-host, is4))
+	}
+	return out
+}
+
+// Near reports whether a and b overlap or fewer than gap free addresses separate them, which is
+// the guard band Find keeps around used prefixes. Prefixes of different families are never near.
+func Near(a, b netip.Prefix, gap uint64) bool {
+	if !sameFamily(a, b) {
+		return false
+	}
+	ia, ib := toInterval(a), toInterval(b)
+	g := new(big.Int).SetUint64(gap)
+	ib.first.Sub(ib.first, g)
+	ib.last.Add(ib.last, g)
+	return ia.first.Cmp(ib.last) <= 0 && ib.first.Cmp(ia.last) <= 0
+}
+
+// Size returns the number of addresses in p.
+func Size(p netip.Prefix) *big.Int {
+	return prefixSize(p.Bits(), p.Addr().Is4())
+}
+
+// Add returns the address n addresses after a (before it when n is negative). It fails when the
+// result falls outside a's address family.
+func Add(a netip.Addr, n *big.Int) (netip.Addr, error) {
+	is4 := a.Is4()
+	v := new(big.Int).Add(addrToInt(a), n)
+	if v.Sign() < 0 || v.BitLen() > addrBits(is4) {
+		return netip.Addr{}, fmt.Errorf("%s%+d is outside the %d-bit address space", a, n, addrBits(is4))
+	}
+	return intToAddr(v, is4), nil
+}
+
+// Range returns the fewest prefixes covering exactly the addresses from first to last inclusive.
+func Range(first, last netip.Addr) ([]netip.Prefix, error) {
+	if first.Is4() != last.Is4() {
+		return nil, fmt.Errorf("%s and %s are different address families", first, last)
+	}
+	if last.Less(first) {
+		return nil, fmt.Errorf("%s comes after %s", first, last)
+	}
+	return cover(interval{first: addrToInt(first), last: addrToInt(last)}, first.Is4()), nil
+}
+
+// Summarize returns the fewest prefixes covering exactly the addresses of the given prefixes,
+// IPv4 before IPv6 and each in address order. Overlapping and adjacent prefixes are merged.
+func Summarize(prefixes []netip.Prefix) []netip.Prefix {
+	var v4, v6 []interval
+	for _, p := range prefixes {
+		if !p.IsValid() {
+			continue
+		}
+		if p.Addr().Is4() {
+			v4 = append(v4, toInterval(p))
+		} else {
+			v6 = append(v6, toInterval(p))
+		}
+	}
+	var out []netip.Prefix
+	for _, iv := range merge(v4) {
+		out = append(out, cover(iv, true)...)
+	}
+	for _, iv := range merge(v6) {
+		out = append(out, cover(iv, false)...)
+	}
+	return out
+}
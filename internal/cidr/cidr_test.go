@@ -0,0 +1,331 @@
+package cidr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"testing"
+)
+
+func mustPrefixes(t *testing.T, list ...string) []netip.Prefix {
+	t.Helper()
+	out, err := ParseAll(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	if _, err := Parse("10.0.0.0/24"); err != nil {
+		t.Errorf("valid CIDR rejected: %v", err)
+	}
+	if _, err := Parse(" 2001:db8::/32 "); err != nil {
+		t.Errorf("valid IPv6 CIDR rejected: %v", err)
+	}
+	for _, s := range []string{"10.0.0.1/24", "10.0.0.0/33", "10.0.0.0", "not-a-cidr", "2001:db8::1/32"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q): expected error", s)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/16")
+	tests := []struct {
+		name string
+		used []string
+		bits int
+		opts Options
+		want string
+	}{
+		{name: "empty first fit", bits: 24, want: "10.0.0.0/24"},
+		{name: "skips used", used: []string{"10.0.0.0/24", "10.0.1.0/25"}, bits: 24, want: "10.0.2.0/24"},
+		{name: "fills gap before used", used: []string{"10.0.1.0/24"}, bits: 24, want: "10.0.0.0/24"},
+		{name: "last fit", used: []string{"10.0.255.0/24"}, bits: 24, opts: Options{Strategy: LastFit}, want: "10.0.254.0/24"},
+		{
+			name: "best fit prefers smallest hole",
+			used: []string{"10.0.0.0/24", "10.0.2.0/24", "10.0.4.0/22"},
+			bits: 24,
+			opts: Options{Strategy: BestFit},
+			want: "10.0.1.0/24",
+		},
+		{name: "within", bits: 24, opts: Options{Within: netip.MustParsePrefix("10.0.128.0/17")}, want: "10.0.128.0/24"},
+		{name: "exclude", bits: 24, opts: Options{Exclude: mustPrefixes(t, "10.0.0.0/23")}, want: "10.0.2.0/24"},
+		{name: "min gap", used: []string{"10.0.0.0/24"}, bits: 24, opts: Options{MinGap: 1}, want: "10.0.2.0/24"},
+		{name: "other family ignored", used: []string{"2001:db8::/32"}, bits: 24, want: "10.0.0.0/24"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Find(parent, mustPrefixes(t, tt.used...), tt.bits, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindWithinOutsideParent(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/16")
+	// Each of these overlaps or misses the parent without being contained in it.
+	for _, within := range []string{"10.0.0.0/8", "10.1.0.0/24", "2001:db8::/32"} {
+		_, err := Find(parent, nil, 24, Options{Within: netip.MustParsePrefix(within)})
+		if err == nil {
+			t.Errorf("within %s: expected error", within)
+		}
+	}
+}
+
+func TestFindIPv6(t *testing.T) {
+	parent := netip.MustParsePrefix("fd00::/32")
+	got, err := Find(parent, mustPrefixes(t, "fd00::/64"), 64, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "fd00:0:0:1::/64" {
+		t.Errorf("got %s", got)
+	}
+	got, err = Find(parent, nil, 64, Options{Strategy: LastFit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "fd00:0:ffff:ffff::/64" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestFindHashIsStable(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/16")
+	a, err := Find(parent, nil, 24, Options{Strategy: Hash, Key: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Find(parent, nil, 24, Options{Strategy: Hash, Key: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("hash placement not stable: %s vs %s", a, b)
+	}
+	// Taking the hashed slot moves the placement to another free slot.
+	c, err := Find(parent, []netip.Prefix{a}, 24, Options{Strategy: Hash, Key: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == a {
+		t.Errorf("hash placement returned used prefix %s", c)
+	}
+}
+
+func TestFindExhausted(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/24")
+	_, err := Find(parent, mustPrefixes(t, "10.0.0.0/25", "10.0.0.128/26"), 25, Options{})
+	if !errors.Is(err, ErrExhausted) {
+		t.Errorf("expected ErrExhausted, got %v", err)
+	}
+	if _, err := Find(parent, nil, 16, Options{}); err == nil {
+		t.Error("expected error for prefix larger than parent")
+	}
+}
+
+func TestCarve(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/16")
+	reqs := []Request{
+		{Name: "public-1", Bits: 24},
+		{Name: "private-1", Bits: 20},
+		{Name: "public-2", Bits: 24},
+		{Name: "private-2", Bits: 20},
+	}
+	got, err := Carve(parent, mustPrefixes(t, "10.0.0.0/24"), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"private-1": "10.0.16.0/20",
+		"private-2": "10.0.32.0/20",
+		"public-1":  "10.0.1.0/24",
+		"public-2":  "10.0.2.0/24",
+	}
+	for name, w := range want {
+		if got[name].String() != w {
+			t.Errorf("%s: got %s, want %s", name, got[name], w)
+		}
+	}
+	// Input order must not change the layout.
+	reversed := []Request{reqs[3], reqs[2], reqs[1], reqs[0]}
+	again, err := Carve(parent, mustPrefixes(t, "10.0.0.0/24"), reversed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range want {
+		if again[name] != got[name] {
+			t.Errorf("%s: layout depends on input order (%s vs %s)", name, again[name], got[name])
+		}
+	}
+	if _, err := Carve(parent, nil, []Request{{Name: "a", Bits: 24}, {Name: "a", Bits: 24}}); err == nil {
+		t.Error("expected error for duplicate names")
+	}
+	if _, err := Carve(netip.MustParsePrefix("10.0.0.0/24"), nil, []Request{{Name: "a", Bits: 25}, {Name: "b", Bits: 25}, {Name: "c", Bits: 25}}); err == nil {
+		t.Error("expected error when the parent is exhausted")
+	}
+}
+
+func TestFree(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent string
+		used   []string
+		want   []string
+	}{
+		{name: "empty parent", parent: "10.0.0.0/24", want: []string{"10.0.0.0/24"}},
+		{name: "full parent", parent: "10.0.0.0/24", used: []string{"10.0.0.0/25", "10.0.0.128/25"}},
+		{name: "used covers parent", parent: "10.0.0.0/24", used: []string{"10.0.0.0/16"}},
+		{
+			name:   "hole in the middle",
+			parent: "10.0.0.0/24",
+			used:   []string{"10.0.0.64/26"},
+			want:   []string{"10.0.0.0/26", "10.0.0.128/25"},
+		},
+		{
+			name:   "unaligned gap",
+			parent: "10.0.0.0/24",
+			used:   []string{"10.0.0.0/30", "10.0.0.128/25"},
+			want:   []string{"10.0.0.4/30", "10.0.0.8/29", "10.0.0.16/28", "10.0.0.32/27", "10.0.0.64/26"},
+		},
+		{name: "whole IPv4 space", parent: "0.0.0.0/0", used: []string{"128.0.0.0/1"}, want: []string{"0.0.0.0/1"}},
+		{
+			name:   "IPv6",
+			parent: "2001:db8::/32",
+			used:   []string{"2001:db8::/34", "2001:db8:8000::/33"},
+			want:   []string{"2001:db8:4000::/34"},
+		},
+		{name: "other family ignored", parent: "10.0.0.0/24", used: []string{"::/0"}, want: []string{"10.0.0.0/24"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Free(netip.MustParsePrefix(tt.parent), mustPrefixes(t, tt.used...))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].String() != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNear(t *testing.T) {
+	tests := []struct {
+		a, b string
+		gap  uint64
+		want bool
+	}{
+		{"10.0.0.0/25", "10.0.0.64/26", 0, true},
+		{"10.0.0.0/25", "10.0.0.128/25", 0, false},
+		{"10.0.0.0/25", "10.0.0.128/25", 1, true},
+		{"10.0.0.0/25", "10.0.1.0/24", 128, false},
+		{"10.0.0.0/25", "10.0.1.0/24", 129, true},
+		{"10.0.1.0/24", "10.0.0.0/25", 129, true},
+		{"10.0.0.0/24", "fd00::/64", 1 << 40, false},
+	}
+	for _, tt := range tests {
+		if got := Near(netip.MustParsePrefix(tt.a), netip.MustParsePrefix(tt.b), tt.gap); got != tt.want {
+			t.Errorf("Near(%s, %s, %d) = %v, want %v", tt.a, tt.b, tt.gap, got, tt.want)
+		}
+	}
+}
+
+func TestSize(t *testing.T) {
+	for cidr, want := range map[string]string{
+		"10.0.0.0/24":   "256",
+		"10.0.0.1/32":   "1",
+		"0.0.0.0/0":     "4294967296",
+		"2001:db8::/64": "18446744073709551616",
+		"::/0":          "340282366920938463463374607431768211456",
+	} {
+		if got := Size(netip.MustParsePrefix(cidr)).String(); got != want {
+			t.Errorf("Size(%s) = %s, want %s", cidr, got, want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		addr string
+		n    int64
+		want string // empty when an error is expected
+	}{
+		{"10.0.0.1", 1, "10.0.0.2"},
+		{"10.0.0.255", 1, "10.0.1.0"},
+		{"10.0.1.0", -1, "10.0.0.255"},
+		{"10.0.0.1", 0, "10.0.0.1"},
+		{"255.255.255.255", 1, ""},
+		{"0.0.0.0", -1, ""},
+		{"2001:db8::ffff", 1, "2001:db8::1:0"},
+		{"2001:db8::", -1, "2001:db7:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", 1, ""},
+	}
+	for _, tt := range tests {
+		got, err := Add(netip.MustParseAddr(tt.addr), big.NewInt(tt.n))
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Add(%s, %d): expected error, got %s", tt.addr, tt.n, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("Add(%s, %d) = %s, %v; want %s", tt.addr, tt.n, got, err, tt.want)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		first, last string
+		want        []string
+	}{
+		{"10.0.0.0", "10.0.0.255", []string{"10.0.0.0/24"}},
+		{"10.0.0.5", "10.0.0.5", []string{"10.0.0.5/32"}},
+		{"10.0.0.1", "10.0.0.6", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"0.0.0.0", "255.255.255.255", []string{"0.0.0.0/0"}},
+		{"2001:db8::", "2001:db8::1:ffff", []string{"2001:db8::/111"}},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", []string{"::/0"}},
+	}
+	for _, tt := range tests {
+		got, err := Range(netip.MustParseAddr(tt.first), netip.MustParseAddr(tt.last))
+		if err != nil {
+			t.Errorf("Range(%s, %s): %v", tt.first, tt.last, err)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(mustPrefixes(t, tt.want...)) {
+			t.Errorf("Range(%s, %s) = %v, want %v", tt.first, tt.last, got, tt.want)
+		}
+	}
+	if _, err := Range(netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.0.1")); err == nil {
+		t.Error("expected error for a reversed range")
+	}
+	if _, err := Range(netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("::1")); err == nil {
+		t.Error("expected error for mixed families")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	got := Summarize(mustPrefixes(t,
+		"2001:db8:1::/48", "10.0.1.0/24", "10.0.0.0/24", "2001:db8::/48",
+		"10.0.0.128/25", "192.168.0.0/24", "10.0.3.0/24",
+	))
+	want := mustPrefixes(t, "10.0.0.0/23", "10.0.3.0/24", "192.168.0.0/24", "2001:db8::/47")
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := Summarize(nil); len(got) != 0 {
+		t.Errorf("empty input: got %v", got)
+	}
+}
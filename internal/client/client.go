@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -37,7 +38,7 @@ type apiError struct {
 	Error string `json:"error"`
 }
 
-func (c *Client) do(method, path string, body interface{}, result interface{}) error {
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
 	var bodyReader io.Reader
 	if body != nil {
 		payload, err := json.Marshal(body)
@@ -46,7 +47,7 @@ func (c *Client) do(method, path string, body interface{}, result interface{}) e
 		}
 		bodyReader = bytes.NewReader(payload)
 	}
-	req, err := http.NewRequest(method, c.baseURL+path, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
 	if err != nil {
 		return err
 	}
@@ -84,24 +85,24 @@ func (c *Client) do(method, path string, body interface{}, result interface{}) e
 	return nil
 }
 
-func (c *Client) get(path string, result interface{}) error {
-	return c.do(http.MethodGet, path, nil, result)
+func (c *Client) get(ctx context.Context, path string, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, nil, result)
 }
 
-func (c *Client) post(path string, body interface{}, result interface{}) error {
-	return c.do(http.MethodPost, path, body, result)
+func (c *Client) post(ctx context.Context, path string, body interface{}, result interface{}) error {
+	return c.do(ctx, http.MethodPost, path, body, result)
 }
 
-func (c *Client) put(path string, body interface{}, result interface{}) error {
-	return c.do(http.MethodPut, path, body, result)
+func (c *Client) put(ctx context.Context, path string, body interface{}, result interface{}) error {
+	return c.do(ctx, http.MethodPut, path, body, result)
 }
 
-func (c *Client) delete(path string) error {
-	return c.do(http.MethodDelete, path, nil, nil)
+func (c *Client) delete(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
 }
 
 // ListEnvironments returns environments with optional name filter and pagination.
-func (c *Client) ListEnvironments(name string, limit, offset int) (*EnvListResponse, error) {
+func (c *Client) ListEnvironments(ctx context.Context, name string, limit, offset int) (*EnvListResponse, error) {
 	path := "/api/environments?"
 	if limit > 0 {
 		path += "limit=" + url.QueryEscape(fmt.Sprintf("%d", limit)) + "&"
@@ -113,16 +114,31 @@ func (c *Client) ListEnvironments(name string, limit, offset int) (*EnvListRespo
 		path += "name=" + url.QueryEscape(name)
 	}
 	var out EnvListResponse
-	if err := c.get(strings.TrimSuffix(path, "&"), &out); err != nil {
+	if err := c.get(ctx, strings.TrimSuffix(path, "&"), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
+// ListAllEnvironments returns every environment matching the name filter, following pagination.
+func (c *Client) ListAllEnvironments(ctx context.Context, name string) ([]EnvResponse, error) {
+	var all []EnvResponse
+	for offset := 0; ; offset += listPageSize {
+		page, err := c.ListEnvironments(ctx, name, listPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Environments...)
+		if len(page.Environments) < listPageSize || (page.Total > 0 && len(all) >= page.Total) {
+			return all, nil
+		}
+	}
+}
+
 // GetEnvironment returns a single environment by ID (includes blocks).
-func (c *Client) GetEnvironment(id string) (*EnvDetailResponse, error) {
+func (c *Client) GetEnvironment(ctx context.Context, id string) (*EnvDetailResponse, error) {
 	var out EnvDetailResponse
-	if err := c.get("/api/environments/"+url.PathEscape(id), &out); err != nil {
+	if err := c.get(ctx, "/api/environments/"+url.PathEscape(id), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -135,7 +151,7 @@ type PoolInput struct {
 }
 
 // CreateEnvironment creates an environment with one or more pools.
-func (c *Client) CreateEnvironment(name string, pools []PoolInput) (*EnvResponse, error) {
+func (c *Client) CreateEnvironment(ctx context.Context, name string, pools []PoolInput) (*EnvResponse, error) {
 	if len(pools) == 0 {
 		return nil, fmt.Errorf("at least one pool is required")
 	}
@@ -151,29 +167,29 @@ func (c *Client) CreateEnvironment(name string, pools []PoolInput) (*EnvResponse
 		"pools": poolMaps,
 	}
 	var out EnvResponse
-	if err := c.post("/api/environments", body, &out); err != nil {
+	if err := c.post(ctx, "/api/environments", body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // UpdateEnvironment updates an environment. API requires id and name in body.
-func (c *Client) UpdateEnvironment(id, name string) (*EnvResponse, error) {
+func (c *Client) UpdateEnvironment(ctx context.Context, id, name string) (*EnvResponse, error) {
 	body := map[string]string{"id": id, "name": name}
 	var out EnvResponse
-	if err := c.put("/api/environments/"+url.PathEscape(id), body, &out); err != nil {
+	if err := c.put(ctx, "/api/environments/"+url.PathEscape(id), body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // DeleteEnvironment deletes an environment.
-func (c *Client) DeleteEnvironment(id string) error {
-	return c.delete("/api/environments/" + url.PathEscape(id))
+func (c *Client) DeleteEnvironment(ctx context.Context, id string) error {
+	return c.delete(ctx, "/api/environments/"+url.PathEscape(id))
 }
 
 // ListBlocks returns blocks with optional filters.
-func (c *Client) ListBlocks(name, environmentID string, orphanedOnly bool, limit, offset int) (*BlockListResponse, error) {
+func (c *Client) ListBlocks(ctx context.Context, name, environmentID string, orphanedOnly bool, limit, offset int) (*BlockListResponse, error) {
 	params := url.Values{}
 	if limit > 0 {
 		params.Set("limit", fmt.Sprintf("%d", limit))
@@ -195,23 +211,38 @@ func (c *Client) ListBlocks(name, environmentID string, orphanedOnly bool, limit
 		path += "?" + q
 	}
 	var out BlockListResponse
-	if err := c.get(path, &out); err != nil {
+	if err := c.get(ctx, path, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
+// ListAllBlocks returns every block matching the filters, following pagination.
+func (c *Client) ListAllBlocks(ctx context.Context, name, environmentID string, orphanedOnly bool) ([]BlockResponse, error) {
+	var all []BlockResponse
+	for offset := 0; ; offset += listPageSize {
+		page, err := c.ListBlocks(ctx, name, environmentID, orphanedOnly, listPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Blocks...)
+		if len(page.Blocks) < listPageSize || (page.Total > 0 && len(all) >= page.Total) {
+			return all, nil
+		}
+	}
+}
+
 // GetBlock returns a single block by ID.
-func (c *Client) GetBlock(id string) (*BlockResponse, error) {
+func (c *Client) GetBlock(ctx context.Context, id string) (*BlockResponse, error) {
 	var out BlockResponse
-	if err := c.get("/api/blocks/"+url.PathEscape(id), &out); err != nil {
+	if err := c.get(ctx, "/api/blocks/"+url.PathEscape(id), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // CreateBlock creates a network block.
-func (c *Client) CreateBlock(name, cidr, environmentID string, poolID *string) (*BlockResponse, error) {
+func (c *Client) CreateBlock(ctx context.Context, name, cidr, environmentID string, poolID *string) (*BlockResponse, error) {
 	body := map[string]interface{}{"name": name, "cidr": cidr}
 	if environmentID != "" {
 		body["environment_id"] = environmentID
@@ -220,14 +251,14 @@ func (c *Client) CreateBlock(name, cidr, environmentID string, poolID *string) (
 		body["pool_id"] = *poolID
 	}
 	var out BlockResponse
-	if err := c.post("/api/blocks", body, &out); err != nil {
+	if err := c.post(ctx, "/api/blocks", body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // UpdateBlock updates a block. API requires id and name in body.
-func (c *Client) UpdateBlock(id, name string, environmentID, poolID *string) (*BlockResponse, error) {
+func (c *Client) UpdateBlock(ctx context.Context, id, name string, environmentID, poolID *string) (*BlockResponse, error) {
 	body := map[string]interface{}{"id": id, "name": name}
 	if environmentID != nil {
 		body["environment_id"] = *environmentID
@@ -236,63 +267,63 @@ func (c *Client) UpdateBlock(id, name string, environmentID, poolID *string) (*B
 		body["pool_id"] = *poolID
 	}
 	var out BlockResponse
-	if err := c.put("/api/blocks/"+url.PathEscape(id), body, &out); err != nil {
+	if err := c.put(ctx, "/api/blocks/"+url.PathEscape(id), body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // DeleteBlock deletes a block.
-func (c *Client) DeleteBlock(id string) error {
-	return c.delete("/api/blocks/" + url.PathEscape(id))
+func (c *Client) DeleteBlock(ctx context.Context, id string) error {
+	return c.delete(ctx, "/api/blocks/"+url.PathEscape(id))
 }
 
 // CreatePool creates an environment pool.
-func (c *Client) CreatePool(environmentID, name, cidr string) (*PoolResponse, error) {
+func (c *Client) CreatePool(ctx context.Context, environmentID, name, cidr string) (*PoolResponse, error) {
 	body := map[string]string{"environment_id": environmentID, "name": name, "cidr": cidr}
 	var out PoolResponse
-	if err := c.post("/api/pools", body, &out); err != nil {
+	if err := c.post(ctx, "/api/pools", body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // GetPool returns a pool by ID.
-func (c *Client) GetPool(id string) (*PoolResponse, error) {
+func (c *Client) GetPool(ctx context.Context, id string) (*PoolResponse, error) {
 	var out PoolResponse
-	if err := c.get("/api/pools/"+url.PathEscape(id), &out); err != nil {
+	if err := c.get(ctx, "/api/pools/"+url.PathEscape(id), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // ListPools returns pools for an environment.
-func (c *Client) ListPools(environmentID string) (*PoolListResponse, error) {
+func (c *Client) ListPools(ctx context.Context, environmentID string) (*PoolListResponse, error) {
 	path := "/api/pools?environment_id=" + url.QueryEscape(environmentID)
 	var out PoolListResponse
-	if err := c.get(path, &out); err != nil {
+	if err := c.get(ctx, path, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // UpdatePool updates a pool.
-func (c *Client) UpdatePool(id, name, cidr string) (*PoolResponse, error) {
+func (c *Client) UpdatePool(ctx context.Context, id, name, cidr string) (*PoolResponse, error) {
 	body := map[string]string{"name": name, "cidr": cidr}
 	var out PoolResponse
-	if err := c.put("/api/pools/"+url.PathEscape(id), body, &out); err != nil {
+	if err := c.put(ctx, "/api/pools/"+url.PathEscape(id), body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // DeletePool deletes a pool.
-func (c *Client) DeletePool(id string) error {
-	return c.delete("/api/pools/" + url.PathEscape(id))
+func (c *Client) DeletePool(ctx context.Context, id string) error {
+	return c.delete(ctx, "/api/pools/"+url.PathEscape(id))
 }
 
-// ListAllocations returns allocations with optional filters.
-func (c *Client) ListAllocations(name, blockName string, limit, offset int) (*AllocationListResponse, error) {
+// ListAllocations returns allocations with optional filters. blockName and blockID both select the parent block.
+func (c *Client) ListAllocations(ctx context.Context, name, blockName, blockID string, limit, offset int) (*AllocationListResponse, error) {
 	params := url.Values{}
 	if limit > 0 {
 		params.Set("limit", fmt.Sprintf("%d", limit))
@@ -306,101 +337,145 @@ func (c *Client) ListAllocations(name, blockName string, limit, offset int) (*Al
 	if blockName != "" {
 		params.Set("block_name", blockName)
 	}
+	if blockID != "" {
+		params.Set("block_id", blockID)
+	}
 	path := "/api/allocations"
 	if q := params.Encode(); q != "" {
 		path += "?" + q
 	}
 	var out AllocationListResponse
-	if err := c.get(path, &out); err != nil {
+	if err := c.get(ctx, path, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
+// listPageSize is the page size used by helpers that need every matching object.
+const listPageSize = 500
+
+// ListAllAllocations returns every allocation matching the filters, following pagination.
+func (c *Client) ListAllAllocations(ctx context.Context, name, blockName, blockID string) ([]AllocationResponse, error) {
+	var all []AllocationResponse
+	for offset := 0; ; offset += listPageSize {
+		page, err := c.ListAllocations(ctx, name, blockName, blockID, listPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Allocations...)
+		if len(page.Allocations) < listPageSize || (page.Total > 0 && len(all) >= page.Total) {
+			return all, nil
+		}
+	}
+}
+
 // GetAllocation returns a single allocation by ID. ID is normalized to lowercase for the request (UUIDs are case-insensitive per RFC 4122).
-func (c *Client) GetAllocation(id string) (*AllocationResponse, error) {
+func (c *Client) GetAllocation(ctx context.Context, id string) (*AllocationResponse, error) {
 	var out AllocationResponse
-	if err := c.get("/api/allocations/"+url.PathEscape(strings.ToLower(id)), &out); err != nil {
+	if err := c.get(ctx, "/api/allocations/"+url.PathEscape(strings.ToLower(id)), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
-// CreateAllocation creates an allocation.
-func (c *Client) CreateAllocation(name, blockName, cidr string) (*AllocationResponse, error) {
-	body := map[string]string{"name": name, "block_name": blockName, "cidr": cidr}
+// CreateAllocation creates an allocation. The parent block is identified by blockName or blockID.
+func (c *Client) CreateAllocation(ctx context.Context, name, blockName, blockID, cidr string) (*AllocationResponse, error) {
+	body := map[string]string{"name": name, "cidr": cidr}
+	setBlockRef(body, blockName, blockID)
 	var out AllocationResponse
-	if err := c.post("/api/allocations", body, &out); err != nil {
+	if err := c.post(ctx, "/api/allocations", body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
+// setBlockRef adds the parent block reference to an allocation request body.
+func setBlockRef(body map[string]string, blockName, blockID string) {
+	if blockID != "" {
+		body["block_id"] = blockID
+	}
+	if blockName != "" {
+		body["block_name"] = blockName
+	}
+}
+
 // AutoAllocate finds the next available CIDR in a block using bin-packing and creates an allocation.
-func (c *Client) AutoAllocate(name, blockName string, prefixLength int) (*AllocationResponse, error) {
-	body := map[string]interface{}{"name": name, "block_name": blockName, "prefix_length": prefixLength}
+// The parent block is identified by blockName or blockID.
+func (c *Client) AutoAllocate(ctx context.Context, name, blockName, blockID string, prefixLength int) (*AllocationResponse, error) {
+	body := map[string]interface{}{"name": name, "prefix_length": prefixLength}
+	if blockID != "" {
+		body["block_id"] = blockID
+	}
+	if blockName != "" {
+		body["block_name"] = blockName
+	}
 	var out AllocationResponse
-	if err := c.post("/api/allocations/auto", body, &out); err != nil {
+	if err := c.post(ctx, "/api/allocations/auto", body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
-// UpdateAllocation updates an allocation (name only). API requires id and name in body. ID is normalized to lowercase.
-func (c *Client) UpdateAllocation(id, name string) (*AllocationResponse, error) {
+// UpdateAllocation updates an allocation's name and, when cidr is non-empty, resizes it in place.
+// API requires id and name in body; cidr is only sent for a resize and requires a server that
+// accepts it in PUT /api/allocations/{id}. ID is normalized to lowercase.
+func (c *Client) UpdateAllocation(ctx context.Context, id, name, cidr string) (*AllocationResponse, error) {
 	id = strings.ToLower(id)
 	body := map[string]string{"id": id, "name": name}
+	if cidr != "" {
+		body["cidr"] = cidr
+	}
 	var out AllocationResponse
-	if err := c.put("/api/allocations/"+url.PathEscape(id), body, &out); err != nil {
+	if err := c.put(ctx, "/api/allocations/"+url.PathEscape(id), body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // DeleteAllocation deletes an allocation. ID is normalized to lowercase for the request.
-func (c *Client) DeleteAllocation(id string) error {
-	return c.delete("/api/allocations/" + url.PathEscape(strings.ToLower(id)))
+func (c *Client) DeleteAllocation(ctx context.Context, id string) error {
+	return c.delete(ctx, "/api/allocations/"+url.PathEscape(strings.ToLower(id)))
 }
 
 // ListReservedBlocks returns reserved blocks (admin only). Pass a non-empty organizationID to filter by organization.
-func (c *Client) ListReservedBlocks(organizationID string) (*ReservedBlockListResponse, error) {
+func (c *Client) ListReservedBlocks(ctx context.Context, organizationID string) (*ReservedBlockListResponse, error) {
 	path := "/api/reserved-blocks"
 	if organizationID != "" {
 		path += "?organization_id=" + url.QueryEscape(organizationID)
 	}
 	var out ReservedBlockListResponse
-	if err := c.get(path, &out); err != nil {
+	if err := c.get(ctx, path, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // CreateReservedBlock creates a reserved block (admin only).
-func (c *Client) CreateReservedBlock(name, cidr, reason string) (*ReservedBlockResponse, error) {
+func (c *Client) CreateReservedBlock(ctx context.Context, name, cidr, reason string) (*ReservedBlockResponse, error) {
 	body := map[string]string{"name": name, "cidr": cidr, "reason": reason}
 	var out ReservedBlockResponse
-	if err := c.post("/api/reserved-blocks", body, &out); err != nil {
+	if err := c.post(ctx, "/api/reserved-blocks", body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // UpdateReservedBlock updates a reserved block's metadata (name). Admin only.
-func (c *Client) UpdateReservedBlock(id, name string) (*ReservedBlockResponse, error) {
+func (c *Client) UpdateReservedBlock(ctx context.Context, id, name string) (*ReservedBlockResponse, error) {
 	body := map[string]interface{}{"id": id}
 	if name != "" {
 		body["name"] = name
 	}
 	var out ReservedBlockResponse
-	if err := c.put("/api/reserved-blocks/"+url.PathEscape(id), body, &out); err != nil {
+	if err := c.put(ctx, "/api/reserved-blocks/"+url.PathEscape(id), body, &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
 // DeleteReservedBlock deletes a reserved block (admin only).
-func (c *Client) DeleteReservedBlock(id string) error {
-	return c.delete("/api/reserved-blocks/" + url.PathEscape(id))
+func (c *Client) DeleteReservedBlock(ctx context.Context, id string) error {
+	return c.delete(ctx, "/api/reserved-blocks/"+url.PathEscape(id))
 }
 
 // API response types (match server JSON; use json tags for lowercase).
@@ -414,14 +489,14 @@ type EnvResponse struct {
 
 type EnvListResponse struct {
 	Environments []EnvResponse `json:"environments"`
-	Total        int          `json:"total"`
+	Total        int           `json:"total"`
 }
 
 type BlockRef struct {
 	ID             string `json:"id"`
 	Name           string `json:"name"`
 	CIDR           string `json:"cidr"`
-	TotalIPs       string `json:"total_ips"`   // derive-only; string supports IPv6 /64 etc.
+	TotalIPs       string `json:"total_ips"` // derive-only; string supports IPv6 /64 etc.
 	UsedIPs        string `json:"used_ips"`
 	Available      string `json:"available_ips"`
 	EnvironmentID  string `json:"environment_id,omitempty"`
@@ -438,12 +513,15 @@ type BlockResponse struct {
 	ID             string  `json:"id"`
 	Name           string  `json:"name"`
 	CIDR           string  `json:"cidr"`
-	TotalIPs       string  `json:"total_ips"`   // derive-only; string supports IPv6 /64 etc.
+	TotalIPs       string  `json:"total_ips"` // derive-only; string supports IPv6 /64 etc.
 	UsedIPs        string  `json:"used_ips"`
 	Available      string  `json:"available_ips"`
 	EnvironmentID  string  `json:"environment_id,omitempty"`
 	OrganizationID string  `json:"organization_id,omitempty"` // for orphan blocks
 	PoolID         *string `json:"pool_id,omitempty"`
+
+	// DeletionProtection is reported by servers that support protecting objects from deletion.
+	DeletionProtection bool `json:"deletion_protection,omitempty"`
 }
 
 type BlockListResponse struct {
@@ -457,6 +535,9 @@ type PoolResponse struct {
 	EnvironmentID  string `json:"environment_id"`
 	Name           string `json:"name"`
 	CIDR           string `json:"cidr"`
+
+	// DeletionProtection is reported by servers that support protecting objects from deletion.
+	DeletionProtection bool `json:"deletion_protection,omitempty"`
 }
 
 type PoolListResponse struct {
@@ -467,12 +548,16 @@ type AllocationResponse struct {
 	Id        string `json:"id"`
 	Name      string `json:"name"`
 	BlockName string `json:"block_name"`
+	BlockID   string `json:"block_id,omitempty"`
 	CIDR      string `json:"cidr"`
+
+	// DeletionProtection is reported by servers that support protecting objects from deletion.
+	DeletionProtection bool `json:"deletion_protection,omitempty"`
 }
 
 type AllocationListResponse struct {
 	Allocations []AllocationResponse `json:"allocations"`
-	Total      int                  `json:"total"`
+	Total       int                  `json:"total"`
 }
 
 type ReservedBlockResponse struct {
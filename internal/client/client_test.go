@@ -1,7 +1,14 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -24,3 +31,152 @@ func TestNew(t *testing.T) {
 		t.Errorf("token: got %s", c.token)
 	}
 }
+
+func TestContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL, "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.GetBlock(ctx, "id")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	poolID := "pool-1"
+	reply := func(v interface{}) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(v)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/reserved-blocks", reply(ReservedBlockListResponse{ReservedBlocks: []ReservedBlockResponse{
+		{ID: "r1", Name: "vpn", CIDR: "10.0.0.192/26"},
+	}}))
+	mux.Handle("/api/blocks", reply(BlockListResponse{Blocks: []BlockResponse{
+		{ID: "b1", Name: "app", CIDR: "10.0.0.0/24", EnvironmentID: "env-1", PoolID: &poolID},
+		{ID: "b2", Name: "orphan", CIDR: "192.168.0.0/24"},
+	}}))
+	mux.HandleFunc("/api/allocations", func(w http.ResponseWriter, r *http.Request) {
+		// Only the allocations of the containing block are listed.
+		switch r.URL.Query().Get("block_id") {
+		case "b1":
+		case "b2":
+			_ = json.NewEncoder(w).Encode(AllocationListResponse{})
+			return
+		default:
+			t.Errorf("allocations listed without a block filter: %s", r.URL)
+		}
+		_ = json.NewEncoder(w).Encode(AllocationListResponse{Allocations: []AllocationResponse{
+			{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"},
+		}})
+	})
+	mux.Handle("/api/pools/pool-1", reply(PoolResponse{ID: "pool-1", EnvironmentID: "env-1", Name: "prod-pool", CIDR: "10.0.0.0/16"}))
+	mux.Handle("/api/pools", reply(PoolListResponse{Pools: []PoolResponse{
+		{ID: "pool-1", EnvironmentID: "env-1", Name: "prod-pool", CIDR: "10.0.0.0/16"},
+	}}))
+	mux.Handle("/api/environments/env-1", reply(EnvDetailResponse{Id: "env-1", Name: "prod"}))
+	mux.Handle("/api/environments", reply(EnvListResponse{Environments: []EnvResponse{{Id: "env-1", Name: "prod"}}}))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c, err := New(srv.URL, "secret", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := func(v interface{}) string {
+		switch o := v.(type) {
+		case *ReservedBlockResponse:
+			if o != nil {
+				return o.Name
+			}
+		case *AllocationResponse:
+			if o != nil {
+				return o.Name
+			}
+		case *BlockResponse:
+			if o != nil {
+				return o.Name
+			}
+		case *PoolResponse:
+			if o != nil {
+				return o.Name
+			}
+		case *EnvResponse:
+			if o != nil {
+				return o.Name
+			}
+		}
+		return ""
+	}
+	tests := []struct {
+		query string
+		cidr  string
+		want  [5]string // reserved block, allocation, block, pool, environment
+	}{
+		{"10.0.0.10", "10.0.0.10/32", [5]string{"", "web", "app", "prod-pool", "prod"}},
+		{"10.0.0.0/27", "10.0.0.0/27", [5]string{"", "web", "app", "prod-pool", "prod"}},
+		{"10.0.0.0/25", "10.0.0.0/25", [5]string{"", "", "app", "prod-pool", "prod"}},
+		{"10.0.0.200", "10.0.0.200/32", [5]string{"vpn", "", "app", "prod-pool", "prod"}},
+		{"10.0.5.1", "10.0.5.1/32", [5]string{"", "", "", "prod-pool", "prod"}},
+		{"192.168.0.1", "192.168.0.1/32", [5]string{"", "", "orphan", "", ""}},
+		{"172.16.0.1", "172.16.0.1/32", [5]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			res, err := c.Lookup(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := [5]string{name(res.ReservedBlock), name(res.Allocation), name(res.Block), name(res.Pool), name(res.Environment)}
+			if res.CIDR != tt.cidr || got != tt.want {
+				t.Errorf("got %s %v, want %s %v", res.CIDR, got, tt.cidr, tt.want)
+			}
+		})
+	}
+	if _, err := c.Lookup(context.Background(), "not-an-ip"); err == nil {
+		t.Error("expected error for an invalid query")
+	}
+}
+
+func TestUpdateAllocationBody(t *testing.T) {
+	var bodies []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/allocations/a1" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		bodies = append(bodies, body)
+		_ = json.NewEncoder(w).Encode(AllocationResponse{Id: "a1", Name: body["name"], CIDR: body["cidr"]})
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL, "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// A rename sends only id and name; a resize adds cidr.
+	if _, err := c.UpdateAllocation(ctx, "A1", "web", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateAllocation(ctx, "a1", "web", "10.0.0.0/25"); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"id": "a1", "name": "web"},
+		{"id": "a1", "name": "web", "cidr": "10.0.0.0/25"},
+	}
+	if !reflect.DeepEqual(bodies, want) {
+		t.Errorf("bodies = %v, want %v", bodies, want)
+	}
+}
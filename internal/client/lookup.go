@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// LookupResult is the chain of objects that contain an address or CIDR. A nil field means no
+// object of that kind contains it (or, for ReservedBlock, that the token cannot list reserved
+// blocks).
+type LookupResult struct {
+	// CIDR is the query as a prefix: a single address becomes a /32 or /128.
+	CIDR          string
+	ReservedBlock *ReservedBlockResponse
+	Allocation    *AllocationResponse
+	Block         *BlockResponse
+	Pool          *PoolResponse
+	Environment   *EnvResponse
+}
+
+// Lookup finds the reserved block, allocation, block, pool and environment that contain query,
+// an IP address or CIDR. The API has no search endpoint, so Lookup walks the tree: it lists
+// blocks, then only the allocations of the block that contains query. Environment pools are
+// only listed when no block contains query. An object contains query when its CIDR covers all of
+// it; a CIDR spanning two allocations has no allocation.
+func (c *Client) Lookup(ctx context.Context, query string) (*LookupResult, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	out := &LookupResult{CIDR: q.String()}
+
+	// Listing reserved blocks is admin only; other tokens just get no reserved block.
+	if reserved, err := c.ListReservedBlocks(ctx, ""); err == nil {
+		for i := range reserved.ReservedBlocks {
+			if covers(reserved.ReservedBlocks[i].CIDR, q) {
+				out.ReservedBlock = &reserved.ReservedBlocks[i]
+				break
+			}
+		}
+	}
+
+	blocks, err := c.ListAllBlocks(ctx, "", "", false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		if covers(blocks[i].CIDR, q) && (out.Block == nil || bits(blocks[i].CIDR) > bits(out.Block.CIDR)) {
+			out.Block = &blocks[i]
+		}
+	}
+	if out.Block == nil {
+		return out, c.lookupPool(ctx, q, out)
+	}
+
+	allocs, err := c.ListAllAllocations(ctx, "", "", out.Block.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range allocs {
+		if covers(allocs[i].CIDR, q) {
+			out.Allocation = &allocs[i]
+			break
+		}
+	}
+	envID := out.Block.EnvironmentID
+	if out.Block.PoolID != nil && *out.Block.PoolID != "" {
+		if out.Pool, err = c.GetPool(ctx, *out.Block.PoolID); err != nil {
+			return nil, err
+		}
+		if envID == "" {
+			envID = out.Pool.EnvironmentID
+		}
+	}
+	if envID != "" {
+		env, err := c.GetEnvironment(ctx, envID)
+		if err != nil {
+			return nil, err
+		}
+		out.Environment = &EnvResponse{Id: env.Id, Name: env.Name}
+	}
+	return out, nil
+}
+
+// lookupPool fills in the pool and environment of a query that no block contains, by listing
+// the pools of every environment.
+func (c *Client) lookupPool(ctx context.Context, q netip.Prefix, out *LookupResult) error {
+	envs, err := c.ListAllEnvironments(ctx, "")
+	if err != nil {
+		return err
+	}
+	for i := range envs {
+		pools, err := c.ListPools(ctx, envs[i].Id)
+		if err != nil {
+			return err
+		}
+		for j := range pools.Pools {
+			if covers(pools.Pools[j].CIDR, q) {
+				out.Pool = &pools.Pools[j]
+				out.Environment = &envs[i]
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// parseQuery parses an IP address or CIDR. Addresses become single-address prefixes.
+func parseQuery(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if a, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(a.WithZone(""), a.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%q is not a valid IP address or CIDR", s)
+	}
+	return p.Masked(), nil
+}
+
+// covers reports whether cidr contains all of q. Unparsable CIDRs contain nothing.
+func covers(cidr string, q netip.Prefix) bool {
+	p, err := netip.ParsePrefix(cidr)
+	return err == nil && p.Bits() <= q.Bits() && p.Contains(q.Addr())
+}
+
+func bits(cidr string) int {
+	p, _ := netip.ParsePrefix(cidr)
+	return p.Bits()
+}
@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// appBlock is block "app" (10.0.0.0/24), the parent of the allocation set tests.
+var appBlock = client.BlockResponse{ID: "b1", Name: "app", CIDR: "10.0.0.0/24", EnvironmentID: "env-1"}
+
+// memberState returns the name => CIDR and name => ID maps of the named allocations in f.
+func memberState(f *fakeAPI, names ...string) (cidrs, ids map[string]string) {
+	cidrs, ids = map[string]string{}, map[string]string{}
+	for _, a := range f.Allocations {
+		for _, n := range names {
+			if a.Name == n {
+				cidrs[n], ids[n] = a.CIDR, a.Id
+			}
+		}
+	}
+	return cidrs, ids
+}
+
+func memberNames(members map[string]client.AllocationResponse) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestAllocationSetUpdateResizesBeforeCreating(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeAPI{Blocks: []client.BlockResponse{appBlock}, Allocations: []client.AllocationResponse{
+		{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"},
+		{Id: "a2", Name: "db", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.64/26"},
+		{Id: "a3", Name: "old", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.128/27"},
+	}}
+	r := &AllocationSetResource{api: newFakeAPI(t, f)}
+	cidrs, ids := memberState(f, "web", "db", "old")
+
+	// web shrinks to a /27 under the same name, db is kept, old is dropped and new is added.
+	desired := []cidr.Request{{Name: "web", Bits: 27}, {Name: "db", Bits: 26}, {Name: "new", Bits: 28}}
+	members, err := r.updateMembers(ctx, "app", "app", desired, cidrs, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := memberNames(members), []string{"db", "new", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("members %v, want %v", got, want)
+	}
+	if members["db"].Id != "a2" || members["web"].Id == "a1" {
+		t.Errorf("db must keep its allocation and web must get a new one: %v", members)
+	}
+	// The resized member is deleted first; the dropped one only after the additions.
+	ops := f.operations()
+	if len(ops) != 4 || ops[0] != "delete allocation web" || ops[3] != "delete allocation old" {
+		t.Errorf("operations %v, want delete web first and delete old last", ops)
+	}
+}
+
+func TestAllocationSetUpdateCreateFailure(t *testing.T) {
+	ctx := context.Background()
+	// "new" is taken by an allocation outside the set, so adding it fails.
+	f := &fakeAPI{Blocks: []client.BlockResponse{appBlock}, Allocations: []client.AllocationResponse{
+		{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"},
+		{Id: "a3", Name: "old", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.128/27"},
+		{Id: "a8", Name: "new", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.224/28"},
+	}}
+	r := &AllocationSetResource{api: newFakeAPI(t, f)}
+	cidrs, ids := memberState(f, "web", "old")
+
+	desired := []cidr.Request{{Name: "web", Bits: 27}, {Name: "new", Bits: 28}}
+	members, err := r.updateMembers(ctx, "app", "app", desired, cidrs, ids)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("got %v, want the name clash", err)
+	}
+	// web was deleted for the resize, its replacement rolled back and its old CIDR re-created;
+	// old was never deleted.
+	if got, want := memberNames(members), []string{"old", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("members %v, want %v", got, want)
+	}
+	if members["web"].CIDR != "10.0.0.0/26" {
+		t.Errorf("web restored as %s, want 10.0.0.0/26", members["web"].CIDR)
+	}
+	want := []string{"delete allocation web", "create allocation web", "delete allocation web", "create allocation web"}
+	if !reflect.DeepEqual(f.operations(), want) {
+		t.Errorf("operations %v, want %v", f.operations(), want)
+	}
+}
+
+func TestAllocationSetUpdateDeleteFailure(t *testing.T) {
+	ctx := context.Background()
+	seed := func() *fakeAPI {
+		return &fakeAPI{Blocks: []client.BlockResponse{appBlock}, Allocations: []client.AllocationResponse{
+			{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"},
+			{Id: "a2", Name: "db", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.64/26"},
+			{Id: "a3", Name: "old", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.128/27"},
+		}}
+	}
+
+	t.Run("removed member", func(t *testing.T) {
+		f := seed()
+		f.Fail = map[string]bool{"DELETE /api/allocations/a3": true}
+		r := &AllocationSetResource{api: newFakeAPI(t, f)}
+		cidrs, ids := memberState(f, "web", "db", "old")
+		members, err := r.updateMembers(ctx, "app", "app", []cidr.Request{{Name: "web", Bits: 26}, {Name: "db", Bits: 26}}, cidrs, ids)
+		if err == nil {
+			t.Fatal("want the delete failure")
+		}
+		// old could not be deleted, so it stays in state.
+		if got, want := memberNames(members), []string{"db", "old", "web"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("members %v, want %v", got, want)
+		}
+	})
+
+	t.Run("resized member", func(t *testing.T) {
+		f := seed()
+		f.Fail = map[string]bool{"DELETE /api/allocations/a1": true}
+		r := &AllocationSetResource{api: newFakeAPI(t, f)}
+		cidrs, ids := memberState(f, "web", "db", "old")
+		desired := []cidr.Request{{Name: "web", Bits: 27}, {Name: "db", Bits: 26}, {Name: "new", Bits: 28}}
+		members, err := r.updateMembers(ctx, "app", "app", desired, cidrs, ids)
+		if err == nil {
+			t.Fatal("want the delete failure")
+		}
+		// Nothing was created or deleted, and every member stays in state unchanged.
+		if got, want := memberNames(members), []string{"db", "old", "web"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("members %v, want %v", got, want)
+		}
+		if members["web"].Id != "a1" {
+			t.Errorf("web is %v, want the old allocation", members["web"])
+		}
+		if ops := f.operations(); len(ops) != 0 {
+			t.Errorf("operations %v, want none", ops)
+		}
+	})
+}
+
+func TestAllocationSetValidateConfig(t *testing.T) {
+	num := func(v interface{}) tftypes.Value { return tftypes.NewValue(tftypes.Number, v) }
+	allocations := func(vals map[string]tftypes.Value) tftypes.Value {
+		return tftypes.NewValue(tftypes.Map{ElementType: tftypes.Number}, vals)
+	}
+	tierType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"name": tftypes.String, "prefix_length": tftypes.Number, "count": tftypes.Number}}
+	tiers := func(name string, bits, count interface{}) tftypes.Value {
+		return tftypes.NewValue(tftypes.List{ElementType: tierType}, []tftypes.Value{
+			tftypes.NewValue(tierType, map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, name), "prefix_length": num(bits), "count": num(count)}),
+		})
+	}
+	tests := []struct {
+		name   string
+		config map[string]tftypes.Value
+		want   []string
+	}{
+		{
+			name:   "valid",
+			config: map[string]tftypes.Value{"allocations": allocations(map[string]tftypes.Value{"web": num(24)}), "tiers": tiers("public", 26, 3)},
+		},
+		{
+			name:   "name repeated by a tier",
+			config: map[string]tftypes.Value{"allocations": allocations(map[string]tftypes.Value{"public-2": num(24)}), "tiers": tiers("public", 26, 3)},
+			want:   []string{"Duplicate allocation name"},
+		},
+		{
+			name:   "prefix length out of range",
+			config: map[string]tftypes.Value{"allocations": allocations(map[string]tftypes.Value{"web": num(129), "db": num(-1)})},
+			want:   []string{"Invalid prefix length", "Invalid prefix length"},
+		},
+		{
+			name:   "tier count",
+			config: map[string]tftypes.Value{"tiers": tiers("public", 26, 0)},
+			want:   []string{"Invalid tier"},
+		},
+		{
+			name:   "unknown prefix length",
+			config: map[string]tftypes.Value{"allocations": allocations(map[string]tftypes.Value{"web": num(tftypes.UnknownValue)})},
+		},
+		{
+			name:   "unknown tier count",
+			config: map[string]tftypes.Value{"tiers": tiers("public", 26, tftypes.UnknownValue)},
+		},
+		{
+			name: "empty",
+			want: []string{"Missing allocations"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, raw := resourceValue(t, NewAllocationSetResource(), tt.config)
+			var resp resource.ValidateConfigResponse
+			NewAllocationSetResource().(*AllocationSetResource).ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: s, Raw: raw}}, &resp)
+			var got []string
+			for _, d := range resp.Diagnostics.Errors() {
+				got = append(got, d.Summary())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+)
+
+// blockByName resolves a block by exact name. The API's name filter may match substrings. Block
+// names can repeat across environments, so a name matching more than one block is an error.
+func blockByName(ctx context.Context, api *client.Client, name string) (*client.BlockResponse, error) {
+	blocks, err := api.ListAllBlocks(ctx, name, "", false)
+	if err != nil {
+		return nil, err
+	}
+	var found []client.BlockResponse
+	var candidates []importCandidate
+	for _, b := range blocks {
+		if b.Name == name {
+			found = append(found, b)
+			candidates = append(candidates, importCandidate{ID: b.ID, Label: fmt.Sprintf("%s %s", b.Name, b.CIDR)})
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("block %q not found", name)
+	case 1:
+		return &found[0], nil
+	}
+	_, err = uniqueMatch("block", name, candidates)
+	return nil, err
+}
+
+// resolveBlock loads the parent block of an allocation from its ID when known, otherwise by name.
+func resolveBlock(ctx context.Context, api *client.Client, blockName, blockID string) (*client.BlockResponse, error) {
+	if blockID != "" {
+		return api.GetBlock(ctx, blockID)
+	}
+	return blockByName(ctx, api, blockName)
+}
+
+// usedInBlock returns the prefixes already taken inside a block: its allocations plus any
+// reserved ranges visible to the token. Listing reserved blocks is admin only; when it fails
+// the server still rejects overlapping allocations, so the error is not fatal here.
+func usedInBlock(ctx context.Context, api *client.Client, block *client.BlockResponse) ([]netip.Prefix, error) {
+	allocs, err := blockAllocations(ctx, api, block)
+	if err != nil {
+		return nil, err
+	}
+	used := make([]netip.Prefix, 0, len(allocs))
+	for _, a := range allocs {
+		if p, err := netip.ParsePrefix(a.CIDR); err == nil {
+			used = append(used, p.Masked())
+		}
+	}
+	return appendReserved(ctx, api, used), nil
+}
+
+// blockAllocations lists the allocations of a block by its ID, so blocks sharing a name in other
+// environments are not mixed in.
+func blockAllocations(ctx context.Context, api *client.Client, block *client.BlockResponse) ([]client.AllocationResponse, error) {
+	allocs, err := api.ListAllAllocations(ctx, "", "", block.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]client.AllocationResponse, 0, len(allocs))
+	for _, a := range allocs {
+		if strings.EqualFold(a.BlockID, block.ID) || (a.BlockID == "" && a.BlockName == block.Name) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// usedInPool returns the prefixes already taken inside a pool: the blocks overlapping it, in any
+// environment, plus reserved ranges visible to the token, as in usedInBlock.
+func usedInPool(ctx context.Context, api *client.Client, pool *client.PoolResponse) ([]netip.Prefix, error) {
+	parent, err := netip.ParsePrefix(pool.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("pool %q has invalid CIDR %q: %w", pool.Name, pool.CIDR, err)
+	}
+	blocks, err := api.ListAllBlocks(ctx, "", "", false)
+	if err != nil {
+		return nil, err
+	}
+	var used []netip.Prefix
+	for _, b := range blocks {
+		if p, err := netip.ParsePrefix(b.CIDR); err == nil && p.Overlaps(parent) {
+			used = append(used, p.Masked())
+		}
+	}
+	return appendReserved(ctx, api, used), nil
+}
+
+// appendReserved adds the reserved ranges visible to the token to used. Listing reserved blocks
+// is admin only, so a failure leaves used unchanged.
+func appendReserved(ctx context.Context, api *client.Client, used []netip.Prefix) []netip.Prefix {
+	reserved, err := api.ListReservedBlocks(ctx, "")
+	if err != nil {
+		return used
+	}
+	for _, rb := range reserved.ReservedBlocks {
+		if p, err := netip.ParsePrefix(rb.CIDR); err == nil {
+			used = append(used, p.Masked())
+		}
+	}
+	return used
+}
+
+// findInBlock runs the provider-side packing for a block and returns the chosen prefix.
+func findInBlock(ctx context.Context, api *client.Client, blockName, blockID string, prefixLength int, opts cidr.Options) (netip.Prefix, error) {
+	block, err := resolveBlock(ctx, api, blockName, blockID)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return placeInBlock(ctx, api, block, prefixLength, opts, nil)
+}
+
+// placeInBlock packs a prefix into a resolved block. With a ledger, the block is packed as it was
+// before this provider process changed it, which is how plan-time predictions are repeated.
+func placeInBlock(ctx context.Context, api *client.Client, block *client.BlockResponse, prefixLength int, opts cidr.Options, ledger *predictionLedger) (netip.Prefix, error) {
+	parent, err := netip.ParsePrefix(block.CIDR)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("block %q has invalid CIDR %q: %w", block.Name, block.CIDR, err)
+	}
+	used, err := usedInBlock(ctx, api, block)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	p, err := cidr.Find(parent, ledger.beforeRun(block.ID, used), prefixLength, opts)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("block %q (%s): %w", block.Name, block.CIDR, err)
+	}
+	return p, nil
+}
+
+// canResizeInPlace reports whether an allocation can move from one prefix to another without
+// being recreated, and why. Shrinking is possible when the new prefix keeps the allocation's
+// network address; growing is possible when the enclosing supernet is inside the block and no
+// other allocation or reserved block overlaps it. Either way the new prefix must honour the
+// allocation's placement constraints in opts. selfID identifies the allocation being resized.
+func canResizeInPlace(ctx context.Context, api *client.Client, blockName, blockID, selfID string, from, to netip.Prefix, opts cidr.Options) (bool, string, error) {
+	if from.Addr().Is4() != to.Addr().Is4() {
+		return false, fmt.Sprintf("%s and %s are different address families", from, to), nil
+	}
+	if w := opts.Within; w.IsValid() && (w.Addr().Is4() != to.Addr().Is4() || to.Bits() < w.Bits() || !w.Contains(to.Addr())) {
+		return false, fmt.Sprintf("%s is outside within_cidr %s", to, w), nil
+	}
+	for _, x := range opts.Exclude {
+		if x.Overlaps(to) {
+			return false, fmt.Sprintf("%s overlaps excluded range %s", to, x), nil
+		}
+	}
+	if to.Bits() > from.Bits() {
+		if to.Addr() != from.Addr() {
+			return false, fmt.Sprintf("%s does not start at the network address of %s", to, from), nil
+		}
+		return true, fmt.Sprintf("shrinking %s to %s keeps its network address", from, to), nil
+	}
+	if !to.Contains(from.Addr()) {
+		return false, fmt.Sprintf("%s does not contain %s", to, from), nil
+	}
+	block, err := resolveBlock(ctx, api, blockName, blockID)
+	if err != nil {
+		return false, "", err
+	}
+	parent, err := netip.ParsePrefix(block.CIDR)
+	if err != nil {
+		return false, "", fmt.Errorf("block %q has invalid CIDR %q: %w", block.Name, block.CIDR, err)
+	}
+	if !parent.Contains(to.Addr()) || to.Bits() < parent.Bits() {
+		return false, fmt.Sprintf("%s is outside block %q (%s)", to, block.Name, block.CIDR), nil
+	}
+	allocs, err := blockAllocations(ctx, api, block)
+	if err != nil {
+		return false, "", err
+	}
+	for _, a := range allocs {
+		if strings.EqualFold(a.Id, selfID) {
+			continue
+		}
+		if p, err := netip.ParsePrefix(a.CIDR); err == nil && cidr.Near(to, p, opts.MinGap) {
+			return false, fmt.Sprintf("%s %s allocation %q (%s)", to, nearVerb(opts.MinGap), a.Name, a.CIDR), nil
+		}
+	}
+	if reserved, err := api.ListReservedBlocks(ctx, ""); err == nil {
+		for _, rb := range reserved.ReservedBlocks {
+			if p, err := netip.ParsePrefix(rb.CIDR); err == nil && cidr.Near(to, p, opts.MinGap) {
+				return false, fmt.Sprintf("%s %s reserved block %q (%s)", to, nearVerb(opts.MinGap), rb.Name, rb.CIDR), nil
+			}
+		}
+	}
+	return true, fmt.Sprintf("growing %s to %s: the enclosing range is free in block %q", from, to, block.Name), nil
+}
+
+// nearVerb describes a cidr.Near conflict with the given gap.
+func nearVerb(gap uint64) string {
+	if gap == 0 {
+		return "overlaps"
+	}
+	return fmt.Sprintf("overlaps or is within min_gap %d of", gap)
+}
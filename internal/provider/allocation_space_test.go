@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+)
+
+// sharedNameFixture is two blocks named "app" with the same CIDR in different environments,
+// each with one allocation.
+func sharedNameFixture() *fakeAPI {
+	return &fakeAPI{
+		Blocks: []client.BlockResponse{
+			{ID: "b1", Name: "app", CIDR: "10.0.0.0/24", EnvironmentID: "env-1"},
+			{ID: "b9", Name: "app", CIDR: "10.0.0.0/24", EnvironmentID: "env-2"},
+		},
+		Allocations: []client.AllocationResponse{
+			{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"},
+			{Id: "a9", Name: "other", BlockName: "app", BlockID: "b9", CIDR: "10.0.0.64/26"},
+		},
+	}
+}
+
+func TestBlockByName(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, sharedNameFixture())
+	_, err := blockByName(ctx, api, "app")
+	if err == nil || !strings.Contains(err.Error(), "matches 2 blocks") {
+		t.Errorf("ambiguous name: got %v, want an error listing both blocks", err)
+	}
+	if _, err := blockByName(ctx, api, "missing"); err == nil || !isNotFound(err) {
+		t.Errorf("unknown name: got %v, want a not found error", err)
+	}
+}
+
+func TestBlockAllocationsByID(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, sharedNameFixture())
+	block, err := api.GetBlock(ctx, "b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	used, err := usedInBlock(ctx, api, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/26")}; !reflect.DeepEqual(used, want) {
+		t.Errorf("usedInBlock: got %v, want %v", used, want)
+	}
+	// 10.0.0.64/26 is taken in the other "app" block only.
+	conflicts, err := allocationConflicts(ctx, api, netip.MustParsePrefix("10.0.0.64/26"), "", "b1", "")
+	if err != nil || len(conflicts) != 0 {
+		t.Errorf("allocationConflicts: got %v, %v; want no conflicts", conflicts, err)
+	}
+	ok, reason, err := canResizeInPlace(ctx, api, "", "b1", "a1", netip.MustParsePrefix("10.0.0.0/26"), netip.MustParsePrefix("10.0.0.0/25"), cidr.Options{})
+	if err != nil || !ok {
+		t.Errorf("canResizeInPlace: got %v (%s), %v; want true", ok, reason, err)
+	}
+}
+
+func TestCanResizeInPlace(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, prodFixture())
+	// Block "app" is 10.0.0.0/24 with allocation a1 "web" at 10.0.0.0/26 and reserved block
+	// "vpn" at 10.0.0.192/26.
+	tests := []struct {
+		name   string
+		self   string
+		from   string
+		to     string
+		opts   cidr.Options
+		want   bool
+		reason string
+	}{
+		{name: "shrink keeps address", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/27", want: true, reason: "keeps its network address"},
+		{name: "shrink moves address", self: "a1", from: "10.0.0.0/26", to: "10.0.0.32/27", reason: "does not start at the network address"},
+		{name: "grow into free range", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/25", want: true, reason: "the enclosing range is free"},
+		{name: "grow over reserved block", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/24", reason: `overlaps reserved block "vpn"`},
+		{name: "grow over allocation", self: "a2", from: "10.0.0.64/26", to: "10.0.0.0/25", reason: `overlaps allocation "web"`},
+		{name: "grow outside block", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/23", reason: `outside block "app"`},
+		{name: "new range elsewhere", self: "a1", from: "10.0.0.0/26", to: "10.0.0.128/25", reason: "does not contain"},
+		{name: "other family", self: "a1", from: "10.0.0.0/26", to: "fd00::/64", reason: "different address families"},
+		{name: "shrink within within_cidr", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/27", opts: cidr.Options{Within: netip.MustParsePrefix("10.0.0.0/26")}, want: true, reason: "keeps its network address"},
+		{name: "grow out of within_cidr", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/25", opts: cidr.Options{Within: netip.MustParsePrefix("10.0.0.0/26")}, reason: "outside within_cidr 10.0.0.0/26"},
+		{name: "grow into excluded range", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/25", opts: cidr.Options{Exclude: []netip.Prefix{netip.MustParsePrefix("10.0.0.96/27")}}, reason: "overlaps excluded range 10.0.0.96/27"},
+		{name: "grow keeping min_gap", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/25", opts: cidr.Options{MinGap: 64}, want: true, reason: "the enclosing range is free"},
+		{name: "grow inside min_gap", self: "a1", from: "10.0.0.0/26", to: "10.0.0.0/25", opts: cidr.Options{MinGap: 65}, reason: `within min_gap 65 of reserved block "vpn"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason, err := canResizeInPlace(ctx, api, "app", "", tt.self, netip.MustParsePrefix(tt.from), netip.MustParsePrefix(tt.to), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.want || !strings.Contains(reason, tt.reason) {
+				t.Errorf("got %v (%s), want %v (%s)", ok, reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
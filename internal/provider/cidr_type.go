@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// cidrType is the string type of every resource cidr attribute. Values that do not parse as a
+// prefix are rejected when the configuration is validated, and values naming the same network
+// are semantically equal, so a differently spelled CIDR from the API (IPv6 case or zero
+// compression, or a masked network address) never shows as a diff.
+type cidrType struct {
+	basetypes.StringType
+}
+
+var _ basetypes.StringTypable = cidrType{}
+
+func (t cidrType) Equal(o attr.Type) bool {
+	other, ok := o.(cidrType)
+	return ok && t.StringType.Equal(other.StringType)
+}
+
+func (t cidrType) String() string {
+	return "cidrType"
+}
+
+func (t cidrType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return cidrValue{StringValue: in}, nil
+}
+
+func (t cidrType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	v, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type %T", v)
+	}
+	return cidrValue{StringValue: s}, nil
+}
+
+func (t cidrType) ValueType(ctx context.Context) attr.Value {
+	return cidrValue{}
+}
+
+// cidrValue is a value of cidrType.
+type cidrValue struct {
+	basetypes.StringValue
+}
+
+var (
+	_ basetypes.StringValuableWithSemanticEquals = cidrValue{}
+	_ xattr.ValidateableAttribute                = cidrValue{}
+)
+
+func cidrStringValue(s string) cidrValue {
+	return cidrValue{StringValue: basetypes.NewStringValue(s)}
+}
+
+func cidrNull() cidrValue {
+	return cidrValue{StringValue: basetypes.NewStringNull()}
+}
+
+func cidrUnknown() cidrValue {
+	return cidrValue{StringValue: basetypes.NewStringUnknown()}
+}
+
+func (v cidrValue) Equal(o attr.Value) bool {
+	other, ok := o.(cidrValue)
+	return ok && v.StringValue.Equal(other.StringValue)
+}
+
+func (v cidrValue) Type(ctx context.Context) attr.Type {
+	return cidrType{}
+}
+
+// StringSemanticEquals reports whether both values name the same network. Host bits are
+// ignored: they only get past planning with normalize_cidrs, where the API stores the network.
+func (v cidrValue) StringSemanticEquals(ctx context.Context, o basetypes.StringValuable) (bool, diag.Diagnostics) {
+	other, ok := o.(cidrValue)
+	if !ok {
+		return false, nil
+	}
+	return sameNetwork(v.ValueString(), other.ValueString()), nil
+}
+
+func (v cidrValue) ValidateAttribute(ctx context.Context, req xattr.ValidateAttributeRequest, resp *xattr.ValidateAttributeResponse) {
+	if v.IsNull() || v.IsUnknown() {
+		return
+	}
+	if _, err := netip.ParsePrefix(v.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid CIDR",
+			fmt.Sprintf("%q is not a valid CIDR prefix (for example 10.0.0.0/16 or 2001:db8::/32): %s", v.ValueString(), err))
+	}
+}
+
+// checkHostBits rejects a planned CIDR with host bits set, such as 10.0.0.1/24, unless the
+// provider normalizes CIDRs to their network address.
+func checkHostBits(p path.Path, v cidrValue, normalize bool, diags *diag.Diagnostics) {
+	if normalize || v.IsNull() || v.IsUnknown() {
+		return
+	}
+	prefix, err := netip.ParsePrefix(v.ValueString())
+	if err != nil || prefix == prefix.Masked() {
+		return
+	}
+	diags.AddAttributeError(p, "CIDR has host bits set",
+		fmt.Sprintf("%s is not a network address; did you mean %s? Set normalize_cidrs = true in the provider block to use the network address automatically.", v.ValueString(), prefix.Masked()))
+}
+
+// networkCIDR is the CIDR sent to the API: the network address when normalize is set, or the
+// value as configured otherwise.
+func networkCIDR(s string, normalize bool) string {
+	if !normalize {
+		return s
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return s
+	}
+	return prefix.Masked().String()
+}
+
+// checkPlannedCIDR runs checkHostBits on the planned top-level cidr attribute.
+func checkPlannedCIDR(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, normalize bool) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var v cidrValue
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("cidr"), &v)...)
+	checkHostBits(path.Root("cidr"), v, normalize, &resp.Diagnostics)
+}
+
+// cidrRequiresReplace forces replacement when a cidr changes, but not when it is only respelled
+// (for example 2001:DB8::/32 to 2001:db8::/32).
+func cidrRequiresReplace() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+		resp.RequiresReplace = !sameNetwork(req.StateValue.ValueString(), req.PlanValue.ValueString())
+	}, "Changing the network forces replacement.", "Changing the network forces replacement.")
+}
+
+// sameNetwork reports whether two CIDR strings name the same network.
+func sameNetwork(a, b string) bool {
+	if a == b {
+		return true
+	}
+	pa, errA := netip.ParsePrefix(a)
+	pb, errB := netip.ParsePrefix(b)
+	return errA == nil && errB == nil && pa.Masked() == pb.Masked()
+}
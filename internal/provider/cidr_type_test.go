@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestCIDRSemanticEquals(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.0.0.0/24", "10.0.0.0/24", true},
+		{"2001:DB8::/32", "2001:db8::/32", true},
+		{"2001:db8:0:0::/64", "2001:db8::/64", true},
+		{"10.0.0.1/24", "10.0.0.0/24", true},
+		{"10.0.0.0/24", "10.0.0.0/25", false},
+		{"10.0.1.0/24", "10.0.0.0/24", false},
+		{"not-a-cidr", "not-a-cidr", true},
+		{"not-a-cidr", "10.0.0.0/24", false},
+	}
+	for _, tt := range tests {
+		got, diags := cidrStringValue(tt.a).StringSemanticEquals(ctx, cidrStringValue(tt.b))
+		if diags.HasError() {
+			t.Fatalf("%s vs %s: %v", tt.a, tt.b, diags)
+		}
+		if got != tt.want {
+			t.Errorf("%s vs %s: got %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCIDRValidateAttribute(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		v       cidrValue
+		wantErr bool
+	}{
+		{cidrStringValue("10.0.0.0/16"), false},
+		{cidrStringValue("2001:db8::/32"), false},
+		{cidrStringValue("10.0.0.1/24"), false}, // host bits are checked at plan time
+		{cidrStringValue("10.0.0.0/33"), true},
+		{cidrStringValue("10.0.0.0"), true},
+		{cidrStringValue("10.0.0.256/24"), true},
+		{cidrNull(), false},
+		{cidrUnknown(), false},
+	} {
+		var resp xattr.ValidateAttributeResponse
+		tt.v.ValidateAttribute(ctx, xattr.ValidateAttributeRequest{Path: path.Root("cidr")}, &resp)
+		if resp.Diagnostics.HasError() != tt.wantErr {
+			t.Errorf("%s: got errors %v, want error %v", tt.v, resp.Diagnostics, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckHostBits(t *testing.T) {
+	var diags diag.Diagnostics
+	checkHostBits(path.Root("cidr"), cidrStringValue("10.0.0.0/24"), false, &diags)
+	checkHostBits(path.Root("cidr"), cidrStringValue("10.0.0.1/24"), true, &diags)
+	checkHostBits(path.Root("cidr"), cidrUnknown(), false, &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	checkHostBits(path.Root("cidr"), cidrStringValue("10.0.0.1/24"), false, &diags)
+	if !diags.HasError() {
+		t.Fatal("expected error for host bits without normalize_cidrs")
+	}
+	if got := diags[0].Detail(); !strings.Contains(got, "did you mean 10.0.0.0/24") {
+		t.Errorf("detail %q does not suggest the network address", got)
+	}
+}
+
+func TestNetworkCIDR(t *testing.T) {
+	for _, tt := range []struct {
+		in        string
+		normalize bool
+		want      string
+	}{
+		{"10.0.0.1/24", true, "10.0.0.0/24"},
+		{"10.0.0.1/24", false, "10.0.0.1/24"},
+		{"2001:DB8::1/32", true, "2001:db8::/32"},
+		{"bogus", true, "bogus"},
+	} {
+		if got := networkCIDR(tt.in, tt.normalize); got != tt.want {
+			t.Errorf("networkCIDR(%q, %v) = %q, want %q", tt.in, tt.normalize, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &AddressLookupDataSource{}
+
+func NewAddressLookupDataSource() datasource.DataSource {
+	return &AddressLookupDataSource{}
+}
+
+type AddressLookupDataSource struct {
+	api *client.Client
+}
+
+type AddressLookupDataSourceModel struct {
+	Address       types.String `tfsdk:"address"`
+	Cidr          types.String `tfsdk:"cidr"`
+	ReservedBlock types.Object `tfsdk:"reserved_block"`
+	Allocation    types.Object `tfsdk:"allocation"`
+	Block         types.Object `tfsdk:"block"`
+	Pool          types.Object `tfsdk:"pool"`
+	Environment   types.Object `tfsdk:"environment"`
+}
+
+// lookupOwnerModel is one link of the containment chain. Environments have no CIDR.
+type lookupOwnerModel struct {
+	Id   types.String `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+	Cidr types.String `tfsdk:"cidr"`
+}
+
+var lookupOwnerAttrTypes = map[string]attr.Type{
+	"id":   types.StringType,
+	"name": types.StringType,
+	"cidr": types.StringType,
+}
+
+var lookupEnvironmentAttrTypes = map[string]attr.Type{
+	"id":   types.StringType,
+	"name": types.StringType,
+}
+
+func lookupOwnerAttribute(description string) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Computed:            true,
+		MarkdownDescription: description,
+		Attributes: map[string]schema.Attribute{
+			"id":   schema.StringAttribute{Computed: true, MarkdownDescription: "UUID."},
+			"name": schema.StringAttribute{Computed: true, MarkdownDescription: "Name."},
+			"cidr": schema.StringAttribute{Computed: true, MarkdownDescription: "CIDR."},
+		},
+	}
+}
+
+func (d *AddressLookupDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_address_lookup"
+}
+
+func (d *AddressLookupDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Find which reserved block, allocation, block, pool and environment an IP address or CIDR belongs to. Each object is null when none contains the whole address or CIDR.",
+		Attributes: map[string]schema.Attribute{
+			"address": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "IP address (e.g. `10.0.1.17`) or CIDR (e.g. `10.0.1.0/28`) to look up.",
+			},
+			"cidr": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "`address` as a CIDR: a single address becomes a /32 (IPv4) or /128 (IPv6).",
+			},
+			"reserved_block": lookupOwnerAttribute("Reserved block containing the address. Always null when the token cannot list reserved blocks (admin only)."),
+			"allocation":     lookupOwnerAttribute("Allocation containing the address."),
+			"block":          lookupOwnerAttribute("Block containing the address."),
+			"pool":           lookupOwnerAttribute("Environment pool containing the address."),
+			"environment": schema.SingleNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Environment of the block or pool.",
+				Attributes: map[string]schema.Attribute{
+					"id":   schema.StringAttribute{Computed: true, MarkdownDescription: "UUID."},
+					"name": schema.StringAttribute{Computed: true, MarkdownDescription: "Name."},
+				},
+			},
+		},
+	}
+}
+
+func (d *AddressLookupDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	api, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		return
+	}
+	d.api = api
+}
+
+func (d *AddressLookupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config AddressLookupDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	res, err := d.api.Lookup(ctx, config.Address.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(config.fromLookup(ctx, res)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
+
+// fromLookup sets the computed attributes from a lookup result.
+func (m *AddressLookupDataSourceModel) fromLookup(ctx context.Context, res *client.LookupResult) diag.Diagnostics {
+	var diags diag.Diagnostics
+	owner := func(id, name, cidr string) types.Object {
+		v, d := types.ObjectValueFrom(ctx, lookupOwnerAttrTypes, lookupOwnerModel{
+			Id:   types.StringValue(id),
+			Name: types.StringValue(name),
+			Cidr: types.StringValue(cidr),
+		})
+		diags.Append(d...)
+		return v
+	}
+	m.Cidr = types.StringValue(res.CIDR)
+	m.ReservedBlock = types.ObjectNull(lookupOwnerAttrTypes)
+	if r := res.ReservedBlock; r != nil {
+		m.ReservedBlock = owner(r.ID, r.Name, r.CIDR)
+	}
+	m.Allocation = types.ObjectNull(lookupOwnerAttrTypes)
+	if a := res.Allocation; a != nil {
+		m.Allocation = owner(a.Id, a.Name, a.CIDR)
+	}
+	m.Block = types.ObjectNull(lookupOwnerAttrTypes)
+	if b := res.Block; b != nil {
+		m.Block = owner(b.ID, b.Name, b.CIDR)
+	}
+	m.Pool = types.ObjectNull(lookupOwnerAttrTypes)
+	if p := res.Pool; p != nil {
+		m.Pool = owner(p.ID, p.Name, p.CIDR)
+	}
+	m.Environment = types.ObjectNull(lookupEnvironmentAttrTypes)
+	if e := res.Environment; e != nil {
+		v, d := types.ObjectValue(lookupEnvironmentAttrTypes, map[string]attr.Value{
+			"id":   types.StringValue(e.Id),
+			"name": types.StringValue(e.Name),
+		})
+		diags.Append(d...)
+		m.Environment = v
+	}
+	return diags
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+func TestAddressLookupFromLookup(t *testing.T) {
+	ctx := context.Background()
+	res := &client.LookupResult{
+		CIDR:       "10.0.0.5/32",
+		Allocation: &client.AllocationResponse{Id: "a1", Name: "web", CIDR: "10.0.0.0/26"},
+		Block:      &client.BlockResponse{ID: "b1", Name: "app", CIDR: "10.0.0.0/24"},
+		Pool:       &client.PoolResponse{ID: "pool-1", Name: "prod-pool", CIDR: "10.0.0.0/16"},
+	}
+	var m AddressLookupDataSourceModel
+	if diags := m.fromLookup(ctx, res); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if m.Cidr.ValueString() != "10.0.0.5/32" {
+		t.Errorf("cidr = %s, want 10.0.0.5/32", m.Cidr)
+	}
+	for _, tt := range []struct {
+		attr string
+		obj  basetypes.ObjectValue
+		want string
+	}{
+		{"allocation", m.Allocation, "a1 web 10.0.0.0/26"},
+		{"block", m.Block, "b1 app 10.0.0.0/24"},
+		{"pool", m.Pool, "pool-1 prod-pool 10.0.0.0/16"},
+	} {
+		var owner lookupOwnerModel
+		if diags := tt.obj.As(ctx, &owner, basetypes.ObjectAsOptions{}); diags.HasError() {
+			t.Fatalf("%s: %v", tt.attr, diags)
+		}
+		if got := owner.Id.ValueString() + " " + owner.Name.ValueString() + " " + owner.Cidr.ValueString(); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.attr, got, tt.want)
+		}
+	}
+	if !m.ReservedBlock.IsNull() || !m.Environment.IsNull() {
+		t.Errorf("reserved_block = %s, environment = %s, want null", m.ReservedBlock, m.Environment)
+	}
+}
@@ -25,6 +25,7 @@ type AllocationDataSourceModel struct {
 	Id        types.String `tfsdk:"id"`
 	Name      types.String `tfsdk:"name"`
 	BlockName types.String `tfsdk:"block_name"`
+	BlockId   types.String `tfsdk:"block_id"`
 	Cidr      types.String `tfsdk:"cidr"`
 }
 
@@ -34,11 +35,11 @@ func (d *AllocationDataSource) Metadata(ctx context.Context, req datasource.Meta
 
 func (d *AllocationDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Fetch a single allocation by ID or by block name and allocation name.",
+		MarkdownDescription: "Fetch a single allocation by ID or by parent block (name or ID) and allocation name.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: "Allocation UUID. Provide either `id` or both `block_name` and `name`.",
+				MarkdownDescription: "Allocation UUID. Provide either `id` or `name` with `block_name` or `block_id`.",
 			},
 			"name": schema.StringAttribute{
 				Optional:            true,
@@ -46,8 +47,14 @@ func (d *AllocationDataSource) Schema(ctx context.Context, req datasource.Schema
 			},
 			"block_name": schema.StringAttribute{
 				Optional:            true,
+				Computed:            true,
 				MarkdownDescription: "Parent block name. Use with `name` when `id` is not set or when the API does not support GET by id.",
 			},
+			"block_id": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Parent block UUID. Alternative to `block_name`.",
+			},
 			"cidr": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "CIDR range.",
@@ -76,18 +83,20 @@ func (d *AllocationDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	}
 	idSet := !config.Id.IsNull() && config.Id.ValueString() != ""
 	nameSet := !config.Name.IsNull() && config.Name.ValueString() != ""
-	blockSet := !config.BlockName.IsNull() && config.BlockName.ValueString() != ""
+	blockName := config.BlockName.ValueString()
+	blockID := config.BlockId.ValueString()
+	blockSet := blockName != "" || blockID != ""
 	if !idSet && !(nameSet && blockSet) {
-		resp.Diagnostics.AddError("Invalid configuration", "Provide either `id` or both `block_name` and `name`.")
+		resp.Diagnostics.AddError("Invalid configuration", "Provide either `id` or `name` with `block_name` or `block_id`.")
 		return
 	}
 	var out *client.AllocationResponse
 	if idSet {
 		var err error
-		out, err = d.api.GetAllocation(strings.ToLower(config.Id.ValueString()))
+		out, err = d.api.GetAllocation(ctx, strings.ToLower(config.Id.ValueString()))
 		if err != nil {
 			if strings.Contains(strings.ToLower(err.Error()), "not found") && nameSet && blockSet {
-				list, listErr := d.api.ListAllocations(config.Name.ValueString(), config.BlockName.ValueString(), 0, 0)
+				list, listErr := d.api.ListAllocations(ctx, config.Name.ValueString(), blockName, blockID, 0, 0)
 				if listErr != nil {
 					resp.Diagnostics.AddError("API error", listErr.Error())
 					return
@@ -103,13 +112,13 @@ func (d *AllocationDataSource) Read(ctx context.Context, req datasource.ReadRequ
 			}
 		}
 	} else {
-		list, err := d.api.ListAllocations(config.Name.ValueString(), config.BlockName.ValueString(), 0, 0)
+		list, err := d.api.ListAllocations(ctx, config.Name.ValueString(), blockName, blockID, 0, 0)
 		if err != nil {
 			resp.Diagnostics.AddError("API error", err.Error())
 			return
 		}
 		if len(list.Allocations) != 1 {
-			resp.Diagnostics.AddError("No allocation found", "List by block_name and name did not return exactly one allocation.")
+			resp.Diagnostics.AddError("No allocation found", "List by block and name did not return exactly one allocation.")
 			return
 		}
 		out = &list.Allocations[0]
@@ -117,6 +126,11 @@ func (d *AllocationDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	config.Id = types.StringValue(strings.ToLower(out.Id))
 	config.Name = types.StringValue(out.Name)
 	config.BlockName = types.StringValue(out.BlockName)
+	if out.BlockID != "" {
+		config.BlockId = types.StringValue(out.BlockID)
+	} else {
+		config.BlockId = types.StringValue(blockID)
+	}
 	config.Cidr = types.StringValue(out.CIDR)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
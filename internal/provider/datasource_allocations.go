@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &AllocationsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &AllocationsDataSource{}
 
 func NewAllocationsDataSource() datasource.DataSource {
 	return &AllocationsDataSource{}
@@ -21,15 +22,19 @@ type AllocationsDataSource struct {
 }
 
 type AllocationsDataSourceModel struct {
-	Name        types.String           `tfsdk:"name"`
-	BlockName   types.String           `tfsdk:"block_name"`
-	Allocations []AllocationRefModel   `tfsdk:"allocations"`
+	Name        types.String         `tfsdk:"name"`
+	BlockName   types.String         `tfsdk:"block_name"`
+	BlockId     types.String         `tfsdk:"block_id"`
+	Filter      types.Object         `tfsdk:"filter"`
+	SortBy      types.String         `tfsdk:"sort_by"`
+	Allocations []AllocationRefModel `tfsdk:"allocations"`
 }
 
 type AllocationRefModel struct {
 	Id        types.String `tfsdk:"id"`
 	Name      types.String `tfsdk:"name"`
 	BlockName types.String `tfsdk:"block_name"`
+	BlockId   types.String `tfsdk:"block_id"`
 	Cidr      types.String `tfsdk:"cidr"`
 }
 
@@ -39,10 +44,13 @@ func (d *AllocationsDataSource) Metadata(ctx context.Context, req datasource.Met
 
 func (d *AllocationsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "List allocations with optional filters.",
+		MarkdownDescription: "List allocations with optional filters. `name`, `block_name` and `block_id` are sent to the API; `filter` is applied by the provider.",
 		Attributes: map[string]schema.Attribute{
 			"name":       schema.StringAttribute{Optional: true, MarkdownDescription: "Filter by allocation name."},
 			"block_name": schema.StringAttribute{Optional: true, MarkdownDescription: "Filter by block name."},
+			"block_id":   schema.StringAttribute{Optional: true, MarkdownDescription: "Filter by block UUID."},
+			"filter":     listFilterAttribute(filterNameRegex, filterCidrContains, filterCidrWithin, filterIPVersion, filterPoolID),
+			"sort_by":    sortByAttribute(sortByName, sortByCIDR),
 			"allocations": schema.ListNestedAttribute{
 				Computed:            true,
 				MarkdownDescription: "List of allocations matching the filters.",
@@ -60,6 +68,10 @@ func (d *AllocationsDataSource) Schema(ctx context.Context, req datasource.Schem
 							Computed:            true,
 							MarkdownDescription: "Parent block name.",
 						},
+						"block_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Parent block UUID (empty if the API does not report it).",
+						},
 						"cidr": schema.StringAttribute{
 							Computed:            true,
 							MarkdownDescription: "CIDR range.",
@@ -83,25 +95,65 @@ func (d *AllocationsDataSource) Configure(ctx context.Context, req datasource.Co
 	d.api = api
 }
 
+func (d *AllocationsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config AllocationsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	validateSortBy(&resp.Diagnostics, config.SortBy, sortByName, sortByCIDR)
+}
+
 func (d *AllocationsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config AllocationsDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := d.api.ListAllocations(config.Name.ValueString(), config.BlockName.ValueString(), 500, 0)
+	filter, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	out, err := d.api.ListAllAllocations(ctx, config.Name.ValueString(), config.BlockName.ValueString(), config.BlockId.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	config.Allocations = make([]AllocationRefModel, len(out.Allocations))
-	for i, a := range out.Allocations {
-		config.Allocations[i] = AllocationRefModel{
+	// Allocations do not report their pool; it is the pool of their block.
+	var poolBlockIDs, poolBlockNames map[string]bool
+	if filter.poolID != "" {
+		blocks, err := d.api.ListAllBlocks(ctx, "", "", false)
+		if err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+		poolBlockIDs, poolBlockNames = map[string]bool{}, map[string]bool{}
+		for _, b := range blocks {
+			if b.PoolID != nil && *b.PoolID == filter.poolID {
+				poolBlockIDs[b.ID] = true
+				poolBlockNames[b.Name] = true
+			}
+		}
+	}
+	config.Allocations = make([]AllocationRefModel, 0, len(out))
+	for _, a := range out {
+		if !filter.matchName(a.Name) || !filter.matchCIDR(a.CIDR) || (filter.poolID != "" && !poolBlockIDs[a.BlockID] && !poolBlockNames[a.BlockName]) {
+			continue
+		}
+		config.Allocations = append(config.Allocations, AllocationRefModel{
 			Id:        types.StringValue(a.Id),
 			Name:      types.StringValue(a.Name),
 			BlockName: types.StringValue(a.BlockName),
+			BlockId:   types.StringValue(a.BlockID),
 			Cidr:      types.StringValue(a.CIDR),
-		}
+		})
 	}
+	sortListed(config.Allocations, config.SortBy.ValueString(),
+		func(a AllocationRefModel) string { return a.Name.ValueString() },
+		func(a AllocationRefModel) string { return a.Cidr.ValueString() },
+		nil)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &BlockDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &BlockDataSource{}
 
 func NewBlockDataSource() datasource.DataSource {
 	return &BlockDataSource{}
@@ -21,13 +22,14 @@ type BlockDataSource struct {
 }
 
 type BlockDataSourceModel struct {
-	Id            types.String `tfsdk:"id"`
-	Name          types.String `tfsdk:"name"`
-	Cidr          types.String `tfsdk:"cidr"`
-	TotalIps      types.String `tfsdk:"total_ips"`
-	UsedIps       types.String `tfsdk:"used_ips"`
-	AvailableIps  types.String `tfsdk:"available_ips"`
-	EnvironmentId types.String `tfsdk:"environment_id"`
+	Id              types.String `tfsdk:"id"`
+	Name            types.String `tfsdk:"name"`
+	Cidr            types.String `tfsdk:"cidr"`
+	TotalIps        types.String `tfsdk:"total_ips"`
+	UsedIps         types.String `tfsdk:"used_ips"`
+	AvailableIps    types.String `tfsdk:"available_ips"`
+	EnvironmentId   types.String `tfsdk:"environment_id"`
+	EnvironmentName types.String `tfsdk:"environment_name"`
 }
 
 func (d *BlockDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -36,19 +38,22 @@ func (d *BlockDataSource) Metadata(ctx context.Context, req datasource.MetadataR
 
 func (d *BlockDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Fetch a single network block by ID.",
+		MarkdownDescription: "Fetch a single network block by ID, or by name and/or CIDR (optionally within an environment). A lookup without `id` must match exactly one block.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Block UUID.",
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Block UUID. Set `id`, or look the block up by `name`, `cidr`, `environment_id` or `environment_name`.",
 			},
 			"name": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "Block name.",
+				MarkdownDescription: "Block name. When looking up, matched exactly.",
 			},
 			"cidr": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "CIDR range.",
+				MarkdownDescription: "CIDR range. When looking up, matched by the network it names (`10.0.0.1/24` matches `10.0.0.0/24`).",
 			},
 			"total_ips": schema.StringAttribute{
 				Computed:            true,
@@ -63,8 +68,13 @@ func (d *BlockDataSource) Schema(ctx context.Context, req datasource.SchemaReque
 				MarkdownDescription: "Available IPs.",
 			},
 			"environment_id": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "Environment UUID, or empty for orphaned blocks.",
+				MarkdownDescription: "Environment UUID, or empty for orphaned blocks. When looking up by name or CIDR, only blocks of this environment match.",
+			},
+			"environment_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Environment name, as an alternative to `environment_id` when looking up.",
 			},
 		},
 	}
@@ -82,13 +92,33 @@ func (d *BlockDataSource) Configure(ctx context.Context, req datasource.Configur
 	d.api = api
 }
 
+func (d *BlockDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config BlockDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	validateLookupKey(&resp.Diagnostics, config.Id, "`name`, `cidr`, `environment_id` or `environment_name`", config.Name, config.Cidr, config.EnvironmentId, config.EnvironmentName)
+	if !config.EnvironmentId.IsNull() && !config.EnvironmentName.IsNull() {
+		resp.Diagnostics.AddError("Invalid configuration", "Set at most one of `environment_id` or `environment_name`.")
+	}
+}
+
 func (d *BlockDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config BlockDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := d.api.GetBlock(config.Id.ValueString())
+	id := config.Id.ValueString()
+	if config.Id.IsNull() {
+		var err error
+		if id, err = resolveBlockKey(ctx, d.api, config.EnvironmentId, config.EnvironmentName, config.Name, config.Cidr); err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+	}
+	out, err := d.api.GetBlock(ctx, id)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &BlockFreeSpaceDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &BlockFreeSpaceDataSource{}
+
+func NewBlockFreeSpaceDataSource() datasource.DataSource {
+	return &BlockFreeSpaceDataSource{}
+}
+
+type BlockFreeSpaceDataSource struct {
+	api *client.Client
+}
+
+type BlockFreeSpaceDataSourceModel struct {
+	BlockId           types.String  `tfsdk:"block_id"`
+	BlockName         types.String  `tfsdk:"block_name"`
+	Cidr              types.String  `tfsdk:"cidr"`
+	FreeCidrs         types.List    `tfsdk:"free_cidrs"`
+	FreeIps           types.String  `tfsdk:"free_ips"`
+	LargestFreePrefix types.Int64   `tfsdk:"largest_free_prefix"`
+	Capacity          types.List    `tfsdk:"capacity"`
+	Fragmentation     types.Float64 `tfsdk:"fragmentation"`
+}
+
+func (d *BlockFreeSpaceDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_block_free_space"
+}
+
+func (d *BlockFreeSpaceDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attrs := freeSpaceAttributes("block")
+	attrs["block_id"] = schema.StringAttribute{
+		Optional:            true,
+		Computed:            true,
+		MarkdownDescription: "Block UUID. Set `block_id` or `block_name`.",
+	}
+	attrs["block_name"] = schema.StringAttribute{
+		Optional:            true,
+		Computed:            true,
+		MarkdownDescription: "Block name. Set `block_id` or `block_name`.",
+	}
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Report where the free space of a network block is: the free CIDRs, the largest prefix that still fits, how many prefixes of each length fit, and a fragmentation score. Allocations and the reserved blocks visible to the token count as used.",
+		Attributes:          attrs,
+	}
+}
+
+func (d *BlockFreeSpaceDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	api, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		return
+	}
+	d.api = api
+}
+
+func (d *BlockFreeSpaceDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config BlockFreeSpaceDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if config.BlockId.IsNull() && config.BlockName.IsNull() {
+		resp.Diagnostics.AddError("Invalid configuration", "Set `block_id` or `block_name`.")
+	}
+}
+
+func (d *BlockFreeSpaceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config BlockFreeSpaceDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	block, err := resolveBlock(ctx, d.api, config.BlockName.ValueString(), config.BlockId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	parent, err := netip.ParsePrefix(block.CIDR)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid block CIDR", fmt.Sprintf("block %q has invalid CIDR %q: %s", block.Name, block.CIDR, err))
+		return
+	}
+	used, err := usedInBlock(ctx, d.api, block)
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	space, diags := computeFreeSpace(ctx, parent.Masked(), used)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.BlockId = types.StringValue(block.ID)
+	config.BlockName = types.StringValue(block.Name)
+	config.Cidr = types.StringValue(block.CIDR)
+	config.FreeCidrs = space.FreeCidrs
+	config.FreeIps = space.FreeIps
+	config.LargestFreePrefix = space.LargestFreePrefix
+	config.Capacity = space.Capacity
+	config.Fragmentation = space.Fragmentation
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
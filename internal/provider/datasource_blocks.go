@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &BlocksDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &BlocksDataSource{}
 
 func NewBlocksDataSource() datasource.DataSource {
 	return &BlocksDataSource{}
@@ -21,20 +22,24 @@ type BlocksDataSource struct {
 }
 
 type BlocksDataSourceModel struct {
-	Name          types.String   `tfsdk:"name"`
-	EnvironmentId types.String   `tfsdk:"environment_id"`
-	OrphanedOnly  types.Bool     `tfsdk:"orphaned_only"`
+	Name          types.String    `tfsdk:"name"`
+	EnvironmentId types.String    `tfsdk:"environment_id"`
+	OrphanedOnly  types.Bool      `tfsdk:"orphaned_only"`
+	Filter        types.Object    `tfsdk:"filter"`
+	SortBy        types.String    `tfsdk:"sort_by"`
 	Blocks        []BlockRefModel `tfsdk:"blocks"`
 }
 
 type BlockRefModel struct {
-	Id            types.String `tfsdk:"id"`
-	Name          types.String `tfsdk:"name"`
-	Cidr          types.String `tfsdk:"cidr"`
-	TotalIps      types.String `tfsdk:"total_ips"`
-	UsedIps       types.String `tfsdk:"used_ips"`
-	AvailableIps  types.String `tfsdk:"available_ips"`
-	EnvironmentId types.String `tfsdk:"environment_id"`
+	Id                 types.String  `tfsdk:"id"`
+	Name               types.String  `tfsdk:"name"`
+	Cidr               types.String  `tfsdk:"cidr"`
+	TotalIps           types.String  `tfsdk:"total_ips"`
+	UsedIps            types.String  `tfsdk:"used_ips"`
+	AvailableIps       types.String  `tfsdk:"available_ips"`
+	EnvironmentId      types.String  `tfsdk:"environment_id"`
+	PoolId             types.String  `tfsdk:"pool_id"`
+	UtilizationPercent types.Float64 `tfsdk:"utilization_percent"`
 }
 
 func (d *BlocksDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -43,11 +48,13 @@ func (d *BlocksDataSource) Metadata(ctx context.Context, req datasource.Metadata
 
 func (d *BlocksDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "List network blocks with optional filters.",
+		MarkdownDescription: "List network blocks with optional filters. `name`, `environment_id` and `orphaned_only` are sent to the API; `filter` is applied by the provider.",
 		Attributes: map[string]schema.Attribute{
 			"name":           schema.StringAttribute{Optional: true, MarkdownDescription: "Filter by name."},
 			"environment_id": schema.StringAttribute{Optional: true, MarkdownDescription: "Filter by environment UUID."},
 			"orphaned_only":  schema.BoolAttribute{Optional: true, MarkdownDescription: "Only blocks not assigned to an environment."},
+			"filter":         listFilterAttribute(filterNameRegex, filterCidrContains, filterCidrWithin, filterIPVersion, filterPoolID, filterMinAvailableIPs),
+			"sort_by":        sortByAttribute(sortByName, sortByCIDR, sortByUtilization),
 			"blocks": schema.ListNestedAttribute{
 				Computed:            true,
 				MarkdownDescription: "List of network blocks matching the filters.",
@@ -81,6 +88,14 @@ func (d *BlocksDataSource) Schema(ctx context.Context, req datasource.SchemaRequ
 							Computed:            true,
 							MarkdownDescription: "Environment UUID, or empty for orphaned blocks.",
 						},
+						"pool_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Pool UUID, or empty when the block is not in a pool.",
+						},
+						"utilization_percent": schema.Float64Attribute{
+							Computed:            true,
+							MarkdownDescription: "`used_ips` as a percentage of `total_ips`.",
+						},
 					},
 				},
 			},
@@ -100,30 +115,59 @@ func (d *BlocksDataSource) Configure(ctx context.Context, req datasource.Configu
 	d.api = api
 }
 
+func (d *BlocksDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config BlocksDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	validateSortBy(&resp.Diagnostics, config.SortBy, sortByName, sortByCIDR, sortByUtilization)
+}
+
 func (d *BlocksDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config BlocksDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	filter, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	envID := config.EnvironmentId.ValueString()
 	orphanedOnly := config.OrphanedOnly.ValueBool()
-	out, err := d.api.ListBlocks(config.Name.ValueString(), envID, orphanedOnly, 500, 0)
+	out, err := d.api.ListAllBlocks(ctx, config.Name.ValueString(), envID, orphanedOnly)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	config.Blocks = make([]BlockRefModel, len(out.Blocks))
-	for i, b := range out.Blocks {
-		config.Blocks[i] = BlockRefModel{
-			Id:            types.StringValue(b.ID),
-			Name:          types.StringValue(b.Name),
-			Cidr:          types.StringValue(b.CIDR),
-			TotalIps:      types.StringValue(b.TotalIPs),
-			UsedIps:       types.StringValue(b.UsedIPs),
-			AvailableIps:  types.StringValue(b.Available),
-			EnvironmentId: types.StringValue(b.EnvironmentID),
+	config.Blocks = make([]BlockRefModel, 0, len(out))
+	for _, b := range out {
+		poolID := ""
+		if b.PoolID != nil {
+			poolID = *b.PoolID
+		}
+		if !filter.matchName(b.Name) || !filter.matchCIDR(b.CIDR) || !filter.matchAvailable(b.Available) || (filter.poolID != "" && poolID != filter.poolID) {
+			continue
 		}
+		config.Blocks = append(config.Blocks, BlockRefModel{
+			Id:                 types.StringValue(b.ID),
+			Name:               types.StringValue(b.Name),
+			Cidr:               types.StringValue(b.CIDR),
+			TotalIps:           types.StringValue(b.TotalIPs),
+			UsedIps:            types.StringValue(b.UsedIPs),
+			AvailableIps:       types.StringValue(b.Available),
+			EnvironmentId:      types.StringValue(b.EnvironmentID),
+			PoolId:             types.StringValue(poolID),
+			UtilizationPercent: types.Float64Value(utilization(b.UsedIPs, b.TotalIPs)),
+		})
 	}
+	sortListed(config.Blocks, config.SortBy.ValueString(),
+		func(b BlockRefModel) string { return b.Name.ValueString() },
+		func(b BlockRefModel) string { return b.Cidr.ValueString() },
+		func(b BlockRefModel) float64 { return b.UtilizationPercent.ValueFloat64() })
 	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
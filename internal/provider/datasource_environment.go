@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -11,6 +12,7 @@ import (
 )
 
 var _ datasource.DataSource = &EnvironmentDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &EnvironmentDataSource{}
 
 func NewEnvironmentDataSource() datasource.DataSource {
 	return &EnvironmentDataSource{}
@@ -21,8 +23,31 @@ type EnvironmentDataSource struct {
 }
 
 type EnvironmentDataSourceModel struct {
+	Id                 types.String            `tfsdk:"id"`
+	Name               types.String            `tfsdk:"name"`
+	Pools              []environmentPoolModel  `tfsdk:"pools"`
+	Blocks             []environmentBlockModel `tfsdk:"blocks"`
+	TotalIps           types.String            `tfsdk:"total_ips"`
+	UsedIps            types.String            `tfsdk:"used_ips"`
+	AvailableIps       types.String            `tfsdk:"available_ips"`
+	UtilizationPercent types.Float64           `tfsdk:"utilization_percent"`
+}
+
+type environmentPoolModel struct {
 	Id   types.String `tfsdk:"id"`
 	Name types.String `tfsdk:"name"`
+	Cidr types.String `tfsdk:"cidr"`
+}
+
+type environmentBlockModel struct {
+	Id                 types.String  `tfsdk:"id"`
+	Name               types.String  `tfsdk:"name"`
+	Cidr               types.String  `tfsdk:"cidr"`
+	TotalIps           types.String  `tfsdk:"total_ips"`
+	UsedIps            types.String  `tfsdk:"used_ips"`
+	AvailableIps       types.String  `tfsdk:"available_ips"`
+	UtilizationPercent types.Float64 `tfsdk:"utilization_percent"`
+	PoolId             types.String  `tfsdk:"pool_id"`
 }
 
 func (d *EnvironmentDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -31,15 +56,66 @@ func (d *EnvironmentDataSource) Metadata(ctx context.Context, req datasource.Met
 
 func (d *EnvironmentDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Fetch a single IPAM environment by ID.",
+		MarkdownDescription: "Fetch a single IPAM environment by ID or name, with its pools, its blocks and their utilization.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Environment UUID.",
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Environment UUID. Set `id` or `name`.",
 			},
 			"name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Environment name. Set `id` or `name`; a name must match exactly one environment.",
+			},
+			"pools": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Pools of the environment.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id":   schema.StringAttribute{Computed: true, MarkdownDescription: "Pool UUID."},
+						"name": schema.StringAttribute{Computed: true, MarkdownDescription: "Pool name."},
+						"cidr": schema.StringAttribute{Computed: true, MarkdownDescription: "Pool CIDR."},
+					},
+				},
+			},
+			"blocks": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Blocks of the environment.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id":            schema.StringAttribute{Computed: true, MarkdownDescription: "Block UUID."},
+						"name":          schema.StringAttribute{Computed: true, MarkdownDescription: "Block name."},
+						"cidr":          schema.StringAttribute{Computed: true, MarkdownDescription: "CIDR range."},
+						"total_ips":     schema.StringAttribute{Computed: true, MarkdownDescription: "Total IP count in the block (string; supports IPv6 /64 etc.)."},
+						"used_ips":      schema.StringAttribute{Computed: true, MarkdownDescription: "IPs used by allocations."},
+						"available_ips": schema.StringAttribute{Computed: true, MarkdownDescription: "Available IPs."},
+						"utilization_percent": schema.Float64Attribute{
+							Computed:            true,
+							MarkdownDescription: "`used_ips` as a percentage of `total_ips`.",
+						},
+						"pool_id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "UUID of the block's pool, or null when the block has no pool.",
+						},
+					},
+				},
+			},
+			"total_ips": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Total IP count of all blocks in the environment (string; supports IPv6).",
+			},
+			"used_ips": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "IPs used by allocations across all blocks.",
+			},
+			"available_ips": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Available IPs across all blocks.",
+			},
+			"utilization_percent": schema.Float64Attribute{
 				Computed:            true,
-				MarkdownDescription: "Environment name.",
+				MarkdownDescription: "`used_ips` as a percentage of `total_ips`; 0 when the environment has no blocks.",
 			},
 		},
 	}
@@ -57,18 +133,76 @@ func (d *EnvironmentDataSource) Configure(ctx context.Context, req datasource.Co
 	d.api = api
 }
 
+func (d *EnvironmentDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config EnvironmentDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	validateLookupKey(&resp.Diagnostics, config.Id, "`name`", config.Name)
+}
+
 func (d *EnvironmentDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config EnvironmentDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := d.api.GetEnvironment(config.Id.ValueString())
+	id := config.Id.ValueString()
+	if config.Id.IsNull() {
+		var err error
+		if id, err = resolveEnvironmentImport(ctx, d.api, config.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+	}
+	tree, err := fetchEnvironmentTree(ctx, d.api, id, false)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	config.Id = types.StringValue(out.Id)
-	config.Name = types.StringValue(out.Name)
+	config.Id = types.StringValue(id)
+	config.Name = types.StringValue(tree.Name)
+	config.fromTree(tree)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
+
+// fromTree sets the pools, blocks and aggregate utilization from an environment tree.
+func (m *EnvironmentDataSourceModel) fromTree(tree *environmentTree) {
+	m.Pools = make([]environmentPoolModel, 0, len(tree.Pools))
+	for _, p := range tree.Pools {
+		m.Pools = append(m.Pools, environmentPoolModel{Id: p.Id, Name: p.Name, Cidr: p.Cidr})
+	}
+	total, used, available := new(big.Int), new(big.Int), new(big.Int)
+	m.Blocks = make([]environmentBlockModel, 0, len(tree.Blocks))
+	for _, b := range tree.Blocks {
+		poolID := types.StringNull()
+		if id, ok := tree.BlockPools[b.Id.ValueString()]; ok {
+			poolID = types.StringValue(id)
+		}
+		m.Blocks = append(m.Blocks, environmentBlockModel{
+			Id:                 b.Id,
+			Name:               b.Name,
+			Cidr:               b.Cidr,
+			TotalIps:           b.TotalIps,
+			UsedIps:            b.UsedIps,
+			AvailableIps:       b.AvailableIps,
+			UtilizationPercent: b.UtilizationPercent,
+			PoolId:             poolID,
+		})
+		addCount(total, b.TotalIps.ValueString())
+		addCount(used, b.UsedIps.ValueString())
+		addCount(available, b.AvailableIps.ValueString())
+	}
+	m.TotalIps = types.StringValue(total.String())
+	m.UsedIps = types.StringValue(used.String())
+	m.AvailableIps = types.StringValue(available.String())
+	m.UtilizationPercent = types.Float64Value(utilization(used.String(), total.String()))
+}
+
+// addCount adds a decimal count as the API reports it to sum, ignoring malformed counts.
+func addCount(sum *big.Int, s string) {
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		sum.Add(sum, n)
+	}
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &EnvironmentTreeDataSource{}
+
+func NewEnvironmentTreeDataSource() datasource.DataSource {
+	return &EnvironmentTreeDataSource{}
+}
+
+type EnvironmentTreeDataSource struct {
+	api *client.Client
+}
+
+type EnvironmentTreeDataSourceModel struct {
+	Id             types.String     `tfsdk:"id"`
+	Name           types.String     `tfsdk:"name"`
+	Pools          []treePoolModel  `tfsdk:"pools"`
+	UnpooledBlocks []treeBlockModel `tfsdk:"unpooled_blocks"`
+}
+
+func (d *EnvironmentTreeDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environment_tree"
+}
+
+func (d *EnvironmentTreeDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Fetch an environment with its pools, the blocks in each pool (with utilization) and the allocations in each block, in one read. Requests are made concurrently.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Environment UUID.",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Environment name.",
+			},
+			"pools": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Pools of the environment.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id":   schema.StringAttribute{Computed: true, MarkdownDescription: "Pool UUID."},
+						"name": schema.StringAttribute{Computed: true, MarkdownDescription: "Pool name."},
+						"cidr": schema.StringAttribute{Computed: true, MarkdownDescription: "Pool CIDR."},
+						"blocks": schema.ListNestedAttribute{
+							Computed:            true,
+							MarkdownDescription: "Blocks in the pool.",
+							NestedObject:        schema.NestedAttributeObject{Attributes: treeBlockAttributes()},
+						},
+					},
+				},
+			},
+			"unpooled_blocks": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Blocks of the environment without a pool.",
+				NestedObject:        schema.NestedAttributeObject{Attributes: treeBlockAttributes()},
+			},
+		},
+	}
+}
+
+func (d *EnvironmentTreeDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	api, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		return
+	}
+	d.api = api
+}
+
+func (d *EnvironmentTreeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config EnvironmentTreeDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tree, err := fetchEnvironmentTree(ctx, d.api, config.Id.ValueString(), true)
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	config.Name = types.StringValue(tree.Name)
+	config.Pools = tree.Pools
+	config.UnpooledBlocks = tree.Unpooled
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
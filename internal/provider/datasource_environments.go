@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &EnvironmentsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &EnvironmentsDataSource{}
 
 func NewEnvironmentsDataSource() datasource.DataSource {
 	return &EnvironmentsDataSource{}
@@ -22,6 +23,8 @@ type EnvironmentsDataSource struct {
 
 type EnvironmentsDataSourceModel struct {
 	Name         types.String          `tfsdk:"name"`
+	Filter       types.Object          `tfsdk:"filter"`
+	SortBy       types.String          `tfsdk:"sort_by"`
 	Environments []EnvironmentRefModel `tfsdk:"environments"`
 }
 
@@ -36,12 +39,14 @@ func (d *EnvironmentsDataSource) Metadata(ctx context.Context, req datasource.Me
 
 func (d *EnvironmentsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "List IPAM environments with optional name filter.",
+		MarkdownDescription: "List IPAM environments with optional filters. `name` is sent to the API; `filter` is applied by the provider.",
 		Attributes: map[string]schema.Attribute{
 			"name": schema.StringAttribute{
 				Optional:            true,
 				MarkdownDescription: "Filter by name (substring).",
 			},
+			"filter":  listFilterAttribute(filterNameRegex, filterPoolID),
+			"sort_by": sortByAttribute(sortByName),
 			"environments": schema.ListNestedAttribute{
 				Computed:            true,
 				MarkdownDescription: "List of environments matching the filter.",
@@ -74,23 +79,58 @@ func (d *EnvironmentsDataSource) Configure(ctx context.Context, req datasource.C
 	d.api = api
 }
 
+func (d *EnvironmentsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config EnvironmentsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	validateSortBy(&resp.Diagnostics, config.SortBy, sortByName)
+}
+
 func (d *EnvironmentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config EnvironmentsDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := d.api.ListEnvironments(config.Name.ValueString(), 500, 0)
+	filter, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	out, err := d.api.ListAllEnvironments(ctx, config.Name.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	config.Environments = make([]EnvironmentRefModel, len(out.Environments))
-	for i, e := range out.Environments {
-		config.Environments[i] = EnvironmentRefModel{
+	// An environment has the pool when it is the pool's environment.
+	poolEnvID := ""
+	if filter.poolID != "" {
+		pool, err := d.api.GetPool(ctx, filter.poolID)
+		if err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+		poolEnvID = pool.EnvironmentID
+	}
+	config.Environments = make([]EnvironmentRefModel, 0, len(out))
+	for _, e := range out {
+		if !filter.matchName(e.Name) {
+			continue
+		}
+		if filter.poolID != "" && e.Id != poolEnvID {
+			continue
+		}
+		config.Environments = append(config.Environments, EnvironmentRefModel{
 			Id:   types.StringValue(e.Id),
 			Name: types.StringValue(e.Name),
-		}
+		})
 	}
+	sortListed(config.Environments, config.SortBy.ValueString(),
+		func(e EnvironmentRefModel) string { return e.Name.ValueString() },
+		nil, nil)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &NextAvailableCIDRDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &NextAvailableCIDRDataSource{}
+
+func NewNextAvailableCIDRDataSource() datasource.DataSource {
+	return &NextAvailableCIDRDataSource{}
+}
+
+type NextAvailableCIDRDataSource struct {
+	api *client.Client
+}
+
+type NextAvailableCIDRDataSourceModel struct {
+	BlockName      types.String `tfsdk:"block_name"`
+	BlockId        types.String `tfsdk:"block_id"`
+	PoolId         types.String `tfsdk:"pool_id"`
+	PrefixLength   types.Int64  `tfsdk:"prefix_length"`
+	CandidateCount types.Int64  `tfsdk:"candidate_count"`
+	ParentCidr     types.String `tfsdk:"parent_cidr"`
+	Cidr           types.String `tfsdk:"cidr"`
+	Cidrs          types.List   `tfsdk:"cidrs"`
+}
+
+func (d *NextAvailableCIDRDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_next_available_cidr"
+}
+
+func (d *NextAvailableCIDRDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Preview the next free CIDR of a given size in a block or pool, without allocating it.
+
+Candidates are chosen like ` + "`ipam_allocation`" + ` with ` + "`prefix_length`" + `: the lowest free aligned range (first fit) that overlaps no allocation or reserved block in the block. In a pool, existing blocks take the place of allocations. Nothing is reserved, so the result can be taken by someone else before it is used.`,
+		Attributes: map[string]schema.Attribute{
+			"block_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the block to search. Set exactly one of `block_name`, `block_id` or `pool_id`.",
+			},
+			"block_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "UUID of the block to search.",
+			},
+			"pool_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "UUID of the pool to search, to preview the CIDR of a new block.",
+			},
+			"prefix_length": schema.Int64Attribute{
+				Required:            true,
+				MarkdownDescription: "Prefix length of the candidates (e.g. 24 for /24).",
+			},
+			"candidate_count": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Number of candidates to return. They do not overlap and are the CIDRs that many auto-allocations would get, in order. Defaults to 1.",
+			},
+			"parent_cidr": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "CIDR of the block or pool searched.",
+			},
+			"cidr": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The first candidate: the CIDR the next auto-allocation would get.",
+			},
+			"cidrs": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "All candidates, in allocation order.",
+			},
+		},
+	}
+}
+
+func (d *NextAvailableCIDRDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	api, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		return
+	}
+	d.api = api
+}
+
+func (d *NextAvailableCIDRDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config NextAvailableCIDRDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	set := 0
+	for _, v := range []types.String{config.BlockName, config.BlockId, config.PoolId} {
+		if !v.IsNull() {
+			set++
+		}
+	}
+	if set != 1 {
+		resp.Diagnostics.AddError("Invalid configuration", "Set exactly one of `block_name`, `block_id` or `pool_id`.")
+	}
+	if !config.CandidateCount.IsNull() && !config.CandidateCount.IsUnknown() && config.CandidateCount.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("candidate_count"), "Invalid candidate_count", "candidate_count must be at least 1.")
+	}
+}
+
+func (d *NextAvailableCIDRDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config NextAvailableCIDRDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	count := 1
+	if !config.CandidateCount.IsNull() {
+		count = int(config.CandidateCount.ValueInt64())
+	}
+	name, parent, used, err := searchSpace(ctx, d.api, config.BlockName.ValueString(), config.BlockId.ValueString(), config.PoolId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	bits := int(config.PrefixLength.ValueInt64())
+	candidates, err := nextAvailable(parent, used, bits, count)
+	switch {
+	case errors.Is(err, cidr.ErrExhausted) && len(candidates) == 0:
+		resp.Diagnostics.AddAttributeError(path.Root("prefix_length"), "No CIDR available", fmt.Sprintf("%s (%s) has no free /%d.", name, parent, bits))
+		return
+	case errors.Is(err, cidr.ErrExhausted):
+		resp.Diagnostics.AddAttributeError(path.Root("candidate_count"), "No CIDR available",
+			fmt.Sprintf("%s (%s) only has room for %d of the %d requested /%d prefixes.", name, parent, len(candidates), count, bits))
+		return
+	case err != nil:
+		resp.Diagnostics.AddAttributeError(path.Root("prefix_length"), "Invalid prefix_length", err.Error())
+		return
+	}
+	out := make([]string, 0, len(candidates))
+	for _, p := range candidates {
+		out = append(out, p.String())
+	}
+	cidrs, diags := types.ListValueFrom(ctx, types.StringType, out)
+	resp.Diagnostics.Append(diags...)
+	config.ParentCidr = types.StringValue(parent.String())
+	config.Cidr = types.StringValue(out[0])
+	config.Cidrs = cidrs
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
+
+// searchSpace loads the block or pool to search and the prefixes already used in it. name
+// describes it for messages.
+func searchSpace(ctx context.Context, api *client.Client, blockName, blockID, poolID string) (string, netip.Prefix, []netip.Prefix, error) {
+	if poolID != "" {
+		pool, err := api.GetPool(ctx, poolID)
+		if err != nil {
+			return "", netip.Prefix{}, nil, err
+		}
+		parent, err := netip.ParsePrefix(pool.CIDR)
+		if err != nil {
+			return "", netip.Prefix{}, nil, fmt.Errorf("pool %q has invalid CIDR %q: %w", pool.Name, pool.CIDR, err)
+		}
+		used, err := usedInPool(ctx, api, pool)
+		return fmt.Sprintf("pool %q", pool.Name), parent.Masked(), used, err
+	}
+	block, err := resolveBlock(ctx, api, blockName, blockID)
+	if err != nil {
+		return "", netip.Prefix{}, nil, err
+	}
+	parent, err := netip.ParsePrefix(block.CIDR)
+	if err != nil {
+		return "", netip.Prefix{}, nil, fmt.Errorf("block %q has invalid CIDR %q: %w", block.Name, block.CIDR, err)
+	}
+	used, err := usedInBlock(ctx, api, block)
+	return fmt.Sprintf("block %q", block.Name), parent.Masked(), used, err
+}
+
+// nextAvailable returns the next count first-fit prefixes of length bits in parent, each placed
+// as if the ones before it had been allocated. When space runs out it returns the candidates
+// found so far with cidr.ErrExhausted.
+func nextAvailable(parent netip.Prefix, used []netip.Prefix, bits, count int) ([]netip.Prefix, error) {
+	taken := append([]netip.Prefix(nil), used...)
+	var out []netip.Prefix
+	for len(out) < count {
+		p, err := cidr.Find(parent, taken, bits, cidr.Options{})
+		if err != nil {
+			return out, err
+		}
+		out = append(out, p)
+		taken = append(taken, p)
+	}
+	return out, nil
+}
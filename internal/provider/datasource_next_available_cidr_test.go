@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+)
+
+func TestNextAvailable(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, prodFixture())
+	tests := []struct {
+		name                       string
+		blockName, blockID, poolID string
+		bits, count                int
+		want                       string
+		exhausted                  bool
+	}{
+		// Block app is 10.0.0.0/24 with allocation 10.0.0.0/26 and reserved block 10.0.0.192/26.
+		{name: "block", blockName: "app", bits: 26, count: 1, want: "[10.0.0.64/26]"},
+		{name: "block candidates", blockName: "app", bits: 26, count: 2, want: "[10.0.0.64/26 10.0.0.128/26]"},
+		{name: "smaller prefixes fill in order", blockName: "app", bits: 27, count: 3, want: "[10.0.0.64/27 10.0.0.96/27 10.0.0.128/27]"},
+		{name: "block exhausted", blockName: "app", bits: 26, count: 3, want: "[10.0.0.64/26 10.0.0.128/26]", exhausted: true},
+		{name: "block full for size", blockName: "app", bits: 25, count: 1, want: "[]", exhausted: true},
+		// Pool 10.0.0.0/16 holds blocks 10.0.0.0/24 and 10.0.1.0/24.
+		{name: "pool", poolID: "pool-1", bits: 24, count: 2, want: "[10.0.2.0/24 10.0.3.0/24]"},
+		{name: "pool larger prefix", poolID: "pool-1", bits: 20, count: 1, want: "[10.0.16.0/20]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, parent, used, err := searchSpace(ctx, api, tt.blockName, tt.blockID, tt.poolID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := nextAvailable(parent, used, tt.bits, tt.count)
+			if errors.Is(err, cidr.ErrExhausted) != tt.exhausted {
+				t.Fatalf("got error %v, want exhausted %v", err, tt.exhausted)
+			}
+			if s := fmt.Sprint(got); s != tt.want {
+				t.Errorf("got %s, want %s", s, tt.want)
+			}
+		})
+	}
+	if _, _, _, err := searchSpace(ctx, api, "missing", "", ""); err == nil {
+		t.Error("expected an error for an unknown block")
+	}
+}
@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &PoolDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &PoolDataSource{}
 
 func NewPoolDataSource() datasource.DataSource {
 	return &PoolDataSource{}
@@ -21,10 +22,11 @@ type PoolDataSource struct {
 }
 
 type PoolDataSourceModel struct {
-	Id            types.String `tfsdk:"id"`
-	EnvironmentId types.String `tfsdk:"environment_id"`
-	Name          types.String `tfsdk:"name"`
-	Cidr          types.String `tfsdk:"cidr"`
+	Id              types.String `tfsdk:"id"`
+	EnvironmentId   types.String `tfsdk:"environment_id"`
+	EnvironmentName types.String `tfsdk:"environment_name"`
+	Name            types.String `tfsdk:"name"`
+	Cidr            types.String `tfsdk:"cidr"`
 }
 
 func (d *PoolDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -33,23 +35,31 @@ func (d *PoolDataSource) Metadata(ctx context.Context, req datasource.MetadataRe
 
 func (d *PoolDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Get an IPAM pool by ID.",
+		MarkdownDescription: "Get an IPAM pool by ID, or by name and/or CIDR (optionally within an environment). A lookup without `id` must match exactly one pool.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Pool UUID.",
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Pool UUID. Set `id`, or look the pool up by `name`, `cidr`, `environment_id` or `environment_name`.",
 			},
 			"environment_id": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "Environment UUID.",
+				MarkdownDescription: "Environment UUID. When looking up by name or CIDR, only pools of this environment match.",
+			},
+			"environment_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Environment name, as an alternative to `environment_id` when looking up.",
 			},
 			"name": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "Pool name.",
+				MarkdownDescription: "Pool name. When looking up, matched exactly.",
 			},
 			"cidr": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "Pool CIDR range.",
+				MarkdownDescription: "Pool CIDR range. When looking up, matched by the network it names (`10.0.0.1/8` matches `10.0.0.0/8`).",
 			},
 		},
 	}
@@ -67,13 +77,33 @@ func (d *PoolDataSource) Configure(ctx context.Context, req datasource.Configure
 	d.api = api
 }
 
+func (d *PoolDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config PoolDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	validateLookupKey(&resp.Diagnostics, config.Id, "`name`, `cidr`, `environment_id` or `environment_name`", config.Name, config.Cidr, config.EnvironmentId, config.EnvironmentName)
+	if !config.EnvironmentId.IsNull() && !config.EnvironmentName.IsNull() {
+		resp.Diagnostics.AddError("Invalid configuration", "Set at most one of `environment_id` or `environment_name`.")
+	}
+}
+
 func (d *PoolDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config PoolDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := d.api.GetPool(config.Id.ValueString())
+	id := config.Id.ValueString()
+	if config.Id.IsNull() {
+		var err error
+		if id, err = resolvePoolKey(ctx, d.api, config.EnvironmentId, config.EnvironmentName, config.Name, config.Cidr); err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+	}
+	out, err := d.api.GetPool(ctx, id)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
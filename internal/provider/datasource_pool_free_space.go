@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &PoolFreeSpaceDataSource{}
+
+func NewPoolFreeSpaceDataSource() datasource.DataSource {
+	return &PoolFreeSpaceDataSource{}
+}
+
+type PoolFreeSpaceDataSource struct {
+	api *client.Client
+}
+
+type PoolFreeSpaceDataSourceModel struct {
+	PoolId            types.String  `tfsdk:"pool_id"`
+	Name              types.String  `tfsdk:"name"`
+	Cidr              types.String  `tfsdk:"cidr"`
+	FreeCidrs         types.List    `tfsdk:"free_cidrs"`
+	FreeIps           types.String  `tfsdk:"free_ips"`
+	LargestFreePrefix types.Int64   `tfsdk:"largest_free_prefix"`
+	Capacity          types.List    `tfsdk:"capacity"`
+	Fragmentation     types.Float64 `tfsdk:"fragmentation"`
+}
+
+func (d *PoolFreeSpaceDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pool_free_space"
+}
+
+func (d *PoolFreeSpaceDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attrs := freeSpaceAttributes("pool")
+	attrs["pool_id"] = schema.StringAttribute{
+		Required:            true,
+		MarkdownDescription: "Pool UUID.",
+	}
+	attrs["name"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Pool name.",
+	}
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Report where the free space of an environment pool is: the free CIDRs, the largest block prefix that still fits, how many prefixes of each length fit, and a fragmentation score. Blocks overlapping the pool and the reserved blocks visible to the token count as used.",
+		Attributes:          attrs,
+	}
+}
+
+func (d *PoolFreeSpaceDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	api, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		return
+	}
+	d.api = api
+}
+
+func (d *PoolFreeSpaceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config PoolFreeSpaceDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	pool, err := d.api.GetPool(ctx, config.PoolId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	parent, err := netip.ParsePrefix(pool.CIDR)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid pool CIDR", fmt.Sprintf("pool %q has invalid CIDR %q: %s", pool.Name, pool.CIDR, err))
+		return
+	}
+	used, err := usedInPool(ctx, d.api, pool)
+	if err != nil {
+		resp.Diagnostics.AddError("API error", err.Error())
+		return
+	}
+	space, diags := computeFreeSpace(ctx, parent.Masked(), used)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.Name = types.StringValue(pool.Name)
+	config.Cidr = types.StringValue(pool.CIDR)
+	config.FreeCidrs = space.FreeCidrs
+	config.FreeIps = space.FreeIps
+	config.LargestFreePrefix = space.LargestFreePrefix
+	config.Capacity = space.Capacity
+	config.Fragmentation = space.Fragmentation
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &PoolsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &PoolsDataSource{}
 
 func NewPoolsDataSource() datasource.DataSource {
 	return &PoolsDataSource{}
@@ -21,8 +22,10 @@ type PoolsDataSource struct {
 }
 
 type PoolsDataSourceModel struct {
-	EnvironmentId types.String       `tfsdk:"environment_id"`
-	Pools         []PoolRefModel      `tfsdk:"pools"`
+	EnvironmentId types.String   `tfsdk:"environment_id"`
+	Filter        types.Object   `tfsdk:"filter"`
+	SortBy        types.String   `tfsdk:"sort_by"`
+	Pools         []PoolRefModel `tfsdk:"pools"`
 }
 
 type PoolRefModel struct {
@@ -38,15 +41,17 @@ func (d *PoolsDataSource) Metadata(ctx context.Context, req datasource.MetadataR
 
 func (d *PoolsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "List pools for an environment.",
+		MarkdownDescription: "List pools, of one environment or of all of them. `filter` is applied by the provider.",
 		Attributes: map[string]schema.Attribute{
 			"environment_id": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Environment UUID.",
+				Optional:            true,
+				MarkdownDescription: "Environment UUID. When unset, pools of every environment are listed.",
 			},
+			"filter":  listFilterAttribute(filterNameRegex, filterCidrContains, filterCidrWithin, filterIPVersion),
+			"sort_by": sortByAttribute(sortByName, sortByCIDR),
 			"pools": schema.ListNestedAttribute{
 				Computed:            true,
-				MarkdownDescription: "List of pools in the environment.",
+				MarkdownDescription: "List of pools matching the filters.",
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"id": schema.StringAttribute{
@@ -84,25 +89,62 @@ func (d *PoolsDataSource) Configure(ctx context.Context, req datasource.Configur
 	d.api = api
 }
 
+func (d *PoolsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config PoolsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	validateSortBy(&resp.Diagnostics, config.SortBy, sortByName, sortByCIDR)
+}
+
 func (d *PoolsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config PoolsDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := d.api.ListPools(config.EnvironmentId.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+	filter, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	config.Pools = make([]PoolRefModel, len(out.Pools))
-	for i, p := range out.Pools {
-		config.Pools[i] = PoolRefModel{
-			Id:            types.StringValue(p.ID),
-			EnvironmentId: types.StringValue(p.EnvironmentID),
-			Name:          types.StringValue(p.Name),
-			Cidr:          types.StringValue(p.CIDR),
+	envIDs := []string{config.EnvironmentId.ValueString()}
+	if envIDs[0] == "" {
+		envs, err := d.api.ListAllEnvironments(ctx, "")
+		if err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+		envIDs = envIDs[:0]
+		for _, e := range envs {
+			envIDs = append(envIDs, e.Id)
+		}
+	}
+	config.Pools = []PoolRefModel{}
+	for _, id := range envIDs {
+		out, err := d.api.ListPools(ctx, id)
+		if err != nil {
+			resp.Diagnostics.AddError("API error", err.Error())
+			return
+		}
+		for _, p := range out.Pools {
+			if !filter.matchName(p.Name) || !filter.matchCIDR(p.CIDR) {
+				continue
+			}
+			config.Pools = append(config.Pools, PoolRefModel{
+				Id:            types.StringValue(p.ID),
+				EnvironmentId: types.StringValue(p.EnvironmentID),
+				Name:          types.StringValue(p.Name),
+				Cidr:          types.StringValue(p.CIDR),
+			})
 		}
 	}
+	sortListed(config.Pools, config.SortBy.ValueString(),
+		func(p PoolRefModel) string { return p.Name.ValueString() },
+		func(p PoolRefModel) string { return p.Cidr.ValueString() },
+		nil)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
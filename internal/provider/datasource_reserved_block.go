@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &ReservedBlockDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &ReservedBlockDataSource{}
 
 func NewReservedBlockDataSource() datasource.DataSource {
 	return &ReservedBlockDataSource{}
@@ -34,19 +35,22 @@ func (d *ReservedBlockDataSource) Metadata(ctx context.Context, req datasource.M
 
 func (d *ReservedBlockDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Fetch a single reserved block by ID (admin only).",
+		MarkdownDescription: "Fetch a single reserved block by ID, or by name and/or CIDR (admin only). A lookup without `id` must match exactly one reserved block.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Reserved block UUID.",
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Reserved block UUID. Set `id`, or look the reserved block up by `name` and/or `cidr`.",
 			},
 			"name": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "Optional name for the reserved range.",
+				MarkdownDescription: "Optional name for the reserved range. When looking up, matched exactly.",
 			},
 			"cidr": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "Reserved CIDR range.",
+				MarkdownDescription: "Reserved CIDR range. When looking up, matched by the network it names.",
 			},
 			"reason": schema.StringAttribute{
 				Computed:            true,
@@ -72,28 +76,30 @@ func (d *ReservedBlockDataSource) Configure(ctx context.Context, req datasource.
 	d.api = api
 }
 
+func (d *ReservedBlockDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config ReservedBlockDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	validateLookupKey(&resp.Diagnostics, config.Id, "`name` and/or `cidr`", config.Name, config.Cidr)
+}
+
 func (d *ReservedBlockDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config ReservedBlockDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	list, err := d.api.ListReservedBlocks("")
+	b, err := resolveReservedBlockKey(ctx, d.api, config.Id, config.Name, config.Cidr)
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	id := config.Id.ValueString()
-	for _, b := range list.ReservedBlocks {
-		if b.ID == id {
-			config.Id = types.StringValue(b.ID)
-			config.Name = types.StringValue(b.Name)
-			config.Cidr = types.StringValue(b.CIDR)
-			config.Reason = types.StringValue(b.Reason)
-			config.CreatedAt = types.StringValue(b.CreatedAt)
-			resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
-			return
-		}
-	}
-	resp.Diagnostics.AddError("Not found", "reserved block not found: "+id)
+	config.Id = types.StringValue(b.ID)
+	config.Name = types.StringValue(b.Name)
+	config.Cidr = types.StringValue(b.CIDR)
+	config.Reason = types.StringValue(b.Reason)
+	config.CreatedAt = types.StringValue(b.CreatedAt)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
@@ -11,6 +11,7 @@ import (
 )
 
 var _ datasource.DataSource = &ReservedBlocksDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &ReservedBlocksDataSource{}
 
 func NewReservedBlocksDataSource() datasource.DataSource {
 	return &ReservedBlocksDataSource{}
@@ -21,6 +22,8 @@ type ReservedBlocksDataSource struct {
 }
 
 type ReservedBlocksDataSourceModel struct {
+	Filter         types.Object            `tfsdk:"filter"`
+	SortBy         types.String            `tfsdk:"sort_by"`
 	ReservedBlocks []ReservedBlockRefModel `tfsdk:"reserved_blocks"`
 }
 
@@ -38,8 +41,10 @@ func (d *ReservedBlocksDataSource) Metadata(ctx context.Context, req datasource.
 
 func (d *ReservedBlocksDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "List all reserved blocks (admin only).",
+		MarkdownDescription: "List reserved blocks (admin only), with optional filters applied by the provider.",
 		Attributes: map[string]schema.Attribute{
+			"filter":  listFilterAttribute(filterNameRegex, filterCidrContains, filterCidrWithin, filterIPVersion),
+			"sort_by": sortByAttribute(sortByName, sortByCIDR),
 			"reserved_blocks": schema.ListNestedAttribute{
 				Computed:            true,
 				MarkdownDescription: "List of reserved CIDR blocks.",
@@ -84,26 +89,49 @@ func (d *ReservedBlocksDataSource) Configure(ctx context.Context, req datasource
 	d.api = api
 }
 
+func (d *ReservedBlocksDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config ReservedBlocksDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	validateSortBy(&resp.Diagnostics, config.SortBy, sortByName, sortByCIDR)
+}
+
 func (d *ReservedBlocksDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config ReservedBlocksDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := d.api.ListReservedBlocks("")
+	filter, diags := parseListFilter(config.Filter)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	out, err := d.api.ListReservedBlocks(ctx, "")
 	if err != nil {
 		resp.Diagnostics.AddError("API error", err.Error())
 		return
 	}
-	config.ReservedBlocks = make([]ReservedBlockRefModel, len(out.ReservedBlocks))
-	for i, b := range out.ReservedBlocks {
-		config.ReservedBlocks[i] = ReservedBlockRefModel{
+	config.ReservedBlocks = make([]ReservedBlockRefModel, 0, len(out.ReservedBlocks))
+	for _, b := range out.ReservedBlocks {
+		if !filter.matchName(b.Name) || !filter.matchCIDR(b.CIDR) {
+			continue
+		}
+		config.ReservedBlocks = append(config.ReservedBlocks, ReservedBlockRefModel{
 			Id:        types.StringValue(b.ID),
 			Name:      types.StringValue(b.Name),
 			Cidr:      types.StringValue(b.CIDR),
 			Reason:    types.StringValue(b.Reason),
 			CreatedAt: types.StringValue(b.CreatedAt),
-		}
+		})
 	}
+	sortListed(config.ReservedBlocks, config.SortBy.ValueString(),
+		func(b ReservedBlockRefModel) string { return b.Name.ValueString() },
+		func(b ReservedBlockRefModel) string { return b.Cidr.ValueString() },
+		nil)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
 }
@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// deletionProtectionAttribute is the deletion_protection attribute shared by every resource.
+func deletionProtectionAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional:            true,
+		Computed:            true,
+		Default:             booldefault.StaticBool(false),
+		MarkdownDescription: "When `true`, the provider refuses to destroy or replace this resource, both at plan time and on delete. Set it to `false` and apply before destroying. Defaults to `false`.",
+	}
+}
+
+// deletionProtected returns the error diagnostic for an attempt to delete a protected resource.
+func deletionProtected(typeName, name, action string) diag.Diagnostic {
+	return diag.NewErrorDiagnostic(
+		"Deletion protection enabled",
+		fmt.Sprintf("%s %q has deletion_protection = true and cannot be %s. Set deletion_protection = false and apply before removing it.", typeName, name, action),
+	)
+}
+
+// checkDeletionProtection fails the plan when a protected resource would be destroyed or replaced.
+// A change to any root attribute with a RequiresReplace plan modifier counts as a replacement, as
+// do replacements already requested by the resource's own ModifyPlan (resp.RequiresReplace). Call
+// it after any other plan modification.
+func checkDeletionProtection(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, typeName string) {
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var protected types.Bool
+	var name types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("deletion_protection"), &protected)...)
+	if resp.Diagnostics.HasError() || !protected.ValueBool() {
+		return
+	}
+	label := "name"
+	if _, ok := req.State.Schema.GetAttributes()[label]; !ok {
+		label = "id"
+	}
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(label), &name)...)
+	if req.Plan.Raw.IsNull() {
+		resp.Diagnostics.Append(deletionProtected(typeName, name.ValueString(), "destroyed"))
+		return
+	}
+	if len(resp.RequiresReplace) > 0 {
+		resp.Diagnostics.Append(deletionProtected(typeName, name.ValueString(), "replaced"))
+		return
+	}
+	s, _ := req.State.Schema.(schema.Schema)
+	for _, attr := range replaceAttributes(s) {
+		p := tftypes.NewAttributePath().WithAttributeName(attr)
+		planned, _, err := tftypes.WalkAttributePath(resp.Plan.Raw, p)
+		if err != nil {
+			continue
+		}
+		prior, _, err := tftypes.WalkAttributePath(req.State.Raw, p)
+		if err != nil {
+			continue
+		}
+		pv, ok1 := planned.(tftypes.Value)
+		sv, ok2 := prior.(tftypes.Value)
+		if ok1 && ok2 && !pv.Equal(sv) && !sameNetworkValue(pv, sv) {
+			resp.Diagnostics.Append(deletionProtected(typeName, name.ValueString(), fmt.Sprintf("replaced (%s changes)", attr)))
+			return
+		}
+	}
+}
+
+// replaceAttributes returns the root attributes of s that have a RequiresReplace plan modifier
+// (including the RequiresReplaceIf variants), in name order.
+func replaceAttributes(s schema.Schema) []string {
+	var names []string
+	for name, a := range s.Attributes {
+		var modifiers []interface{}
+		switch a := a.(type) {
+		case schema.StringAttribute:
+			for _, m := range a.PlanModifiers {
+				modifiers = append(modifiers, m)
+			}
+		case schema.Int64Attribute:
+			for _, m := range a.PlanModifiers {
+				modifiers = append(modifiers, m)
+			}
+		case schema.BoolAttribute:
+			for _, m := range a.PlanModifiers {
+				modifiers = append(modifiers, m)
+			}
+		case schema.ListAttribute:
+			for _, m := range a.PlanModifiers {
+				modifiers = append(modifiers, m)
+			}
+		case schema.MapAttribute:
+			for _, m := range a.PlanModifiers {
+				modifiers = append(modifiers, m)
+			}
+		case schema.SetAttribute:
+			for _, m := range a.PlanModifiers {
+				modifiers = append(modifiers, m)
+			}
+		case schema.ListNestedAttribute:
+			for _, m := range a.PlanModifiers {
+				modifiers = append(modifiers, m)
+			}
+		}
+		for _, m := range modifiers {
+			// The framework's RequiresReplace modifiers are all requiresReplaceIf* types.
+			if strings.Contains(fmt.Sprintf("%T", m), "requiresReplace") {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sameNetworkValue reports whether two string values name the same CIDR network, so a respelled
+// cidr is not treated as a replacement.
+func sameNetworkValue(a, b tftypes.Value) bool {
+	var as, bs string
+	if a.As(&as) != nil || b.As(&bs) != nil {
+		return false
+	}
+	return sameNetwork(as, bs)
+}
+
+// protectionLedger records the objects this provider process has seen with deletion_protection =
+// true, so force_destroy does not cascade into a child protected by another resource. A resource
+// cannot read the state of other resources, so the ledger only knows the resources this process
+// has planned, in their state or their plan.
+type protectionLedger struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func newProtectionLedger() *protectionLedger {
+	return &protectionLedger{ids: map[string]bool{}}
+}
+
+// record notes that the object with the given ID is protected.
+func (l *protectionLedger) record(id string) {
+	if l == nil || id == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ids[strings.ToLower(id)] = true
+}
+
+// protected reports whether the object with the given ID was recorded as protected.
+func (l *protectionLedger) protected(id string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ids[strings.ToLower(id)]
+}
+
+// recordPlan records the resource in req when deletion_protection is true in its state or its
+// plan. An ipam_allocation_set records its members.
+func (l *protectionLedger) recordPlan(ctx context.Context, req resource.ModifyPlanRequest) {
+	if l == nil || req.State.Raw.IsNull() {
+		return
+	}
+	var inState, inPlan types.Bool
+	req.State.GetAttribute(ctx, path.Root("deletion_protection"), &inState)
+	if !req.Plan.Raw.IsNull() {
+		req.Plan.GetAttribute(ctx, path.Root("deletion_protection"), &inPlan)
+	}
+	if !inState.ValueBool() && !inPlan.ValueBool() {
+		return
+	}
+	var id types.String
+	req.State.GetAttribute(ctx, path.Root("id"), &id)
+	l.record(id.ValueString())
+	if _, ok := req.State.Schema.GetAttributes()["ids"]; ok {
+		var ids map[string]string
+		req.State.GetAttribute(ctx, path.Root("ids"), &ids)
+		for _, memberID := range ids {
+			l.record(memberID)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestReplaceAttributes(t *testing.T) {
+	tests := []struct {
+		resource resource.Resource
+		want     []string
+	}{
+		{NewAllocationResource(), []string{"block_id", "block_name", "exclude_cidrs", "min_gap", "strategy", "within_cidr"}},
+		{NewAllocationSetResource(), []string{"block_name"}},
+		{NewBlockResource(), []string{"cidr"}},
+		{NewPoolResource(), []string{"environment_id"}},
+		{NewReservedBlockResource(), []string{"cidr"}},
+		{NewEnvironmentResource(), nil},
+	}
+	for _, tt := range tests {
+		var resp resource.SchemaResponse
+		tt.resource.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+		if got := replaceAttributes(resp.Schema); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%T: got %v, want %v", tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestProtectionLedgerRecordPlan(t *testing.T) {
+	boolean := func(v bool) tftypes.Value { return tftypes.NewValue(tftypes.Bool, v) }
+	str := func(v string) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+	ids := tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"web": str("a1"), "db": str("a2")})
+	tests := []struct {
+		name     string
+		resource resource.Resource
+		state    map[string]tftypes.Value
+		plan     map[string]tftypes.Value // nil for a destroy
+		want     []string
+	}{
+		{
+			name:     "protected in state, destroyed",
+			resource: NewBlockResource(),
+			state:    map[string]tftypes.Value{"id": str("B1"), "deletion_protection": boolean(true)},
+			want:     []string{"b1"},
+		},
+		{
+			name:     "protected in config only",
+			resource: NewPoolResource(),
+			state:    map[string]tftypes.Value{"id": str("pool-1"), "deletion_protection": boolean(false)},
+			plan:     map[string]tftypes.Value{"id": str("pool-1"), "deletion_protection": boolean(true)},
+			want:     []string{"pool-1"},
+		},
+		{
+			name:     "not protected",
+			resource: NewAllocationResource(),
+			state:    map[string]tftypes.Value{"id": str("a1"), "deletion_protection": boolean(false)},
+			plan:     map[string]tftypes.Value{"id": str("a1"), "deletion_protection": boolean(false)},
+		},
+		{
+			name:     "allocation set records its members",
+			resource: NewAllocationSetResource(),
+			state:    map[string]tftypes.Value{"id": str("set-1"), "ids": ids, "deletion_protection": boolean(true)},
+			plan:     map[string]tftypes.Value{"id": str("set-1"), "ids": ids, "deletion_protection": boolean(true)},
+			want:     []string{"a1", "a2", "set-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, state := resourceValue(t, tt.resource, tt.state)
+			plan := tftypes.NewValue(state.Type(), nil)
+			if tt.plan != nil {
+				_, plan = resourceValue(t, tt.resource, tt.plan)
+			}
+			l := newProtectionLedger()
+			l.recordPlan(context.Background(), resource.ModifyPlanRequest{
+				State: tfsdk.State{Schema: s, Raw: state},
+				Plan:  tfsdk.Plan{Schema: s, Raw: plan},
+			})
+			var got []string
+			for id := range l.ids {
+				got = append(got, id)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recorded %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
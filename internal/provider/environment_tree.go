@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// treeFetchConcurrency bounds the API requests an environment tree read makes at once.
+const treeFetchConcurrency = 8
+
+type treeAllocationModel struct {
+	Id   types.String `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+	Cidr types.String `tfsdk:"cidr"`
+}
+
+type treeBlockModel struct {
+	Id                 types.String          `tfsdk:"id"`
+	Name               types.String          `tfsdk:"name"`
+	Cidr               types.String          `tfsdk:"cidr"`
+	TotalIps           types.String          `tfsdk:"total_ips"`
+	UsedIps            types.String          `tfsdk:"used_ips"`
+	AvailableIps       types.String          `tfsdk:"available_ips"`
+	UtilizationPercent types.Float64         `tfsdk:"utilization_percent"`
+	Allocations        []treeAllocationModel `tfsdk:"allocations"`
+}
+
+type treePoolModel struct {
+	Id     types.String     `tfsdk:"id"`
+	Name   types.String     `tfsdk:"name"`
+	Cidr   types.String     `tfsdk:"cidr"`
+	Blocks []treeBlockModel `tfsdk:"blocks"`
+}
+
+// environmentTree is an environment with its pools, the blocks in each pool and the
+// allocations in each block. Blocks without a pool are kept in Unpooled. Blocks lists every
+// block in API order, and BlockPools maps a block ID to its pool ID as the API reports it.
+type environmentTree struct {
+	Name       string
+	Pools      []treePoolModel
+	Unpooled   []treeBlockModel
+	Blocks     []treeBlockModel
+	BlockPools map[string]string
+}
+
+// fetchEnvironmentTree loads the environment (which lists its blocks with their utilization),
+// its pools and its blocks' pool IDs at the same time, then the allocations of every block, at
+// most treeFetchConcurrency requests at once. A block belongs to the pool the API reports for
+// it. Without allocations the block allocation lists are nil.
+func fetchEnvironmentTree(ctx context.Context, api *client.Client, id string, allocations bool) (*environmentTree, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var env *client.EnvDetailResponse
+	var pools *client.PoolListResponse
+	var listed []client.BlockResponse
+	var envErr, poolsErr, blocksErr error
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		env, envErr = api.GetEnvironment(ctx, id)
+	}()
+	go func() {
+		defer wg.Done()
+		pools, poolsErr = api.ListPools(ctx, id)
+	}()
+	go func() {
+		defer wg.Done()
+		listed, blocksErr = api.ListAllBlocks(ctx, "", id, false)
+	}()
+	wg.Wait()
+	for _, err := range []error{envErr, poolsErr, blocksErr} {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tree := &environmentTree{
+		Name:       env.Name,
+		Pools:      make([]treePoolModel, 0, len(pools.Pools)),
+		Unpooled:   []treeBlockModel{},
+		BlockPools: map[string]string{},
+	}
+	poolIndex := make(map[string]int, len(pools.Pools))
+	for i, p := range pools.Pools {
+		tree.Pools = append(tree.Pools, treePoolModel{
+			Id:     types.StringValue(p.ID),
+			Name:   types.StringValue(p.Name),
+			Cidr:   types.StringValue(p.CIDR),
+			Blocks: []treeBlockModel{},
+		})
+		poolIndex[strings.ToLower(p.ID)] = i
+	}
+	for _, b := range listed {
+		if b.PoolID != nil && *b.PoolID != "" {
+			tree.BlockPools[b.ID] = *b.PoolID
+		}
+	}
+	blocks := make([]treeBlockModel, len(env.Blocks))
+	for i, b := range env.Blocks {
+		blocks[i] = treeBlockModel{
+			Id:                 types.StringValue(b.ID),
+			Name:               types.StringValue(b.Name),
+			Cidr:               types.StringValue(b.CIDR),
+			TotalIps:           types.StringValue(b.TotalIPs),
+			UsedIps:            types.StringValue(b.UsedIPs),
+			AvailableIps:       types.StringValue(b.Available),
+			UtilizationPercent: types.Float64Value(utilization(b.UsedIPs, b.TotalIPs)),
+		}
+	}
+	if allocations {
+		if err := fetchBlockAllocations(ctx, api, env.Blocks, blocks); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, b := range env.Blocks {
+		if pool, ok := poolIndex[strings.ToLower(tree.BlockPools[b.ID])]; ok {
+			tree.Pools[pool].Blocks = append(tree.Pools[pool].Blocks, blocks[i])
+		} else {
+			tree.Unpooled = append(tree.Unpooled, blocks[i])
+		}
+	}
+	tree.Blocks = blocks
+	return tree, nil
+}
+
+// fetchBlockAllocations lists the allocations of each block into out, concurrently. The first
+// error cancels the requests still running.
+func fetchBlockAllocations(ctx context.Context, api *client.Client, refs []client.BlockRef, out []treeBlockModel) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, treeFetchConcurrency)
+	for i := range refs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			allocs, err := api.ListAllAllocations(ctx, "", "", refs[i].ID)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			list := make([]treeAllocationModel, 0, len(allocs))
+			for _, a := range allocs {
+				list = append(list, treeAllocationModel{
+					Id:   types.StringValue(a.Id),
+					Name: types.StringValue(a.Name),
+					Cidr: types.StringValue(a.CIDR),
+				})
+			}
+			out[i].Allocations = list
+		}(i)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// utilization returns used as a percentage of total, two decimal integer strings as the API
+// reports them. It is 0 when total is zero or either count is malformed.
+func utilization(used, total string) float64 {
+	u, okU := new(big.Int).SetString(used, 10)
+	t, okT := new(big.Int).SetString(total, 10)
+	if !okU || !okT || t.Sign() <= 0 {
+		return 0
+	}
+	f, _ := new(big.Rat).SetFrac(new(big.Int).Mul(u, big.NewInt(100)), t).Float64()
+	return f
+}
+
+// treeBlockAttributes returns the nested attributes of a block in an environment tree.
+func treeBlockAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id":            schema.StringAttribute{Computed: true, MarkdownDescription: "Block UUID."},
+		"name":          schema.StringAttribute{Computed: true, MarkdownDescription: "Block name."},
+		"cidr":          schema.StringAttribute{Computed: true, MarkdownDescription: "CIDR range."},
+		"total_ips":     schema.StringAttribute{Computed: true, MarkdownDescription: "Total IP count in the block (string; supports IPv6 /64 etc.)."},
+		"used_ips":      schema.StringAttribute{Computed: true, MarkdownDescription: "IPs used by allocations."},
+		"available_ips": schema.StringAttribute{Computed: true, MarkdownDescription: "Available IPs."},
+		"utilization_percent": schema.Float64Attribute{
+			Computed:            true,
+			MarkdownDescription: "`used_ips` as a percentage of `total_ips`.",
+		},
+		"allocations": schema.ListNestedAttribute{
+			Computed:            true,
+			MarkdownDescription: "Allocations in the block.",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"id":   schema.StringAttribute{Computed: true, MarkdownDescription: "Allocation UUID."},
+					"name": schema.StringAttribute{Computed: true, MarkdownDescription: "Allocation name."},
+					"cidr": schema.StringAttribute{Computed: true, MarkdownDescription: "Allocation CIDR."},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// treeFixture is environment prod with two pools and three blocks. The API's pool_id is
+// authoritative: db is inside prod-pool's CIDR but has no pool.
+func treeFixture() *fakeAPI {
+	poolID := "pool-1"
+	return &fakeAPI{
+		Environments: []client.EnvResponse{{Id: "env-1", Name: "prod"}},
+		Pools: []client.PoolResponse{
+			{ID: "pool-1", EnvironmentID: "env-1", Name: "prod-pool", CIDR: "10.0.0.0/16"},
+			{ID: "pool-2", EnvironmentID: "env-1", Name: "prod-extra", CIDR: "10.1.0.0/16"},
+		},
+		Blocks: []client.BlockResponse{
+			{ID: "b1", Name: "app", CIDR: "10.0.0.0/24", TotalIPs: "256", UsedIPs: "64", Available: "192", EnvironmentID: "env-1", PoolID: &poolID},
+			{ID: "b2", Name: "db", CIDR: "10.0.1.0/24", TotalIPs: "256", UsedIPs: "32", Available: "224", EnvironmentID: "env-1"},
+			{ID: "b3", Name: "legacy", CIDR: "172.16.0.0/24", TotalIPs: "256", UsedIPs: "0", Available: "256", EnvironmentID: "env-1"},
+		},
+		Allocations: []client.AllocationResponse{
+			{Id: "a1", Name: "web", BlockID: "b1", CIDR: "10.0.0.0/26"},
+			{Id: "a2", Name: "pg", BlockID: "b2", CIDR: "10.0.1.0/28"},
+			{Id: "a3", Name: "redis", BlockID: "b2", CIDR: "10.0.1.16/28"},
+		},
+	}
+}
+
+func TestFetchEnvironmentTree(t *testing.T) {
+	ctx := context.Background()
+	tree, err := fetchEnvironmentTree(ctx, newFakeAPI(t, treeFixture()), "env-1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Name != "prod" || len(tree.Pools) != 2 {
+		t.Fatalf("got %q with %d pools, want prod with 2", tree.Name, len(tree.Pools))
+	}
+	blocks := tree.Pools[0].Blocks
+	if len(blocks) != 1 || blocks[0].Name.ValueString() != "app" {
+		t.Fatalf("pool-1 blocks = %v, want app", blocks)
+	}
+	if got := blocks[0].UtilizationPercent.ValueFloat64(); got != 25 {
+		t.Errorf("app utilization_percent = %v, want 25", got)
+	}
+	if len(tree.Pools[1].Blocks) != 0 {
+		t.Errorf("pool-2 blocks = %v, want none", tree.Pools[1].Blocks)
+	}
+	unpooled := tree.Unpooled
+	if len(unpooled) != 2 || unpooled[0].Name.ValueString() != "db" || unpooled[1].Name.ValueString() != "legacy" {
+		t.Fatalf("unpooled = %v, want db and legacy", unpooled)
+	}
+	if len(blocks[0].Allocations) != 1 || len(unpooled[0].Allocations) != 2 || unpooled[0].Allocations[1].Name.ValueString() != "redis" {
+		t.Errorf("allocations = %v / %v, want web / pg, redis", blocks[0].Allocations, unpooled[0].Allocations)
+	}
+
+	// The model must fit the schema, nested lists included.
+	var schemaResp datasource.SchemaResponse
+	NewEnvironmentTreeDataSource().Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	model := EnvironmentTreeDataSourceModel{Pools: tree.Pools, UnpooledBlocks: tree.Unpooled}
+	if diags := state.Set(ctx, &model); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if _, err := fetchEnvironmentTree(ctx, newFakeAPI(t, treeFixture()), "missing", true); err == nil {
+		t.Error("expected error for an unknown environment")
+	}
+}
+
+func TestEnvironmentDataSourceFromTree(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, treeFixture())
+	id, err := resolveEnvironmentImport(ctx, api, "prod")
+	if err != nil || id != "env-1" {
+		t.Fatalf("resolve prod = %q, %v; want env-1", id, err)
+	}
+	tree, err := fetchEnvironmentTree(ctx, api, id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Blocks[0].Allocations != nil {
+		t.Errorf("allocations fetched without being asked for: %v", tree.Blocks[0].Allocations)
+	}
+	var m EnvironmentDataSourceModel
+	m.fromTree(tree)
+	if len(m.Pools) != 2 || len(m.Blocks) != 3 {
+		t.Fatalf("got %d pools and %d blocks, want 2 and 3", len(m.Pools), len(m.Blocks))
+	}
+	for i, want := range []types.String{types.StringValue("pool-1"), types.StringNull(), types.StringNull()} {
+		if got := m.Blocks[i].PoolId; !got.Equal(want) {
+			t.Errorf("block %s pool_id = %s, want %s", m.Blocks[i].Name, got, want)
+		}
+	}
+	if m.TotalIps.ValueString() != "768" || m.UsedIps.ValueString() != "96" || m.AvailableIps.ValueString() != "672" {
+		t.Errorf("totals = %s/%s/%s, want 768/96/672", m.TotalIps, m.UsedIps, m.AvailableIps)
+	}
+	if got := m.UtilizationPercent.ValueFloat64(); got != 12.5 {
+		t.Errorf("utilization_percent = %v, want 12.5", got)
+	}
+
+	var schemaResp datasource.SchemaResponse
+	NewEnvironmentDataSource().Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	if diags := state.Set(ctx, &m); diags.HasError() {
+		t.Fatal(diags)
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	for _, tt := range []struct {
+		used, total string
+		want        float64
+	}{
+		{"64", "256", 25},
+		{"0", "0", 0},
+		{"", "256", 0},
+		{"9223372036854775808", "18446744073709551616", 50},
+	} {
+		if got := utilization(tt.used, tt.total); got != tt.want {
+			t.Errorf("utilization(%q, %q) = %v, want %v", tt.used, tt.total, got, tt.want)
+		}
+	}
+}
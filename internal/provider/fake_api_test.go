@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+)
+
+// fakeAPI is an in-memory IPAM server for unit tests. A test seeds only the objects it needs and
+// passes it to newFakeAPI. List endpoints apply the API's filters and pagination; creates, updates
+// and deletes change the data and are recorded in ops as "<verb> <kind> <name>".
+type fakeAPI struct {
+	Environments []client.EnvResponse
+	Pools        []client.PoolResponse
+	Blocks       []client.BlockResponse
+	Allocations  []client.AllocationResponse
+	// Reserved is served as the reserved blocks. When nil, listing them fails as it does for a
+	// token without admin rights.
+	Reserved []client.ReservedBlockResponse
+	// Fail makes requests fail with a conflict, keyed by "METHOD /path".
+	Fail map[string]bool
+
+	mu      sync.Mutex
+	ops     []string
+	queries map[string]url.Values
+	nextID  int
+}
+
+// newFakeAPI serves f and returns a client for it.
+func newFakeAPI(t *testing.T, f *fakeAPI) *client.Client {
+	t.Helper()
+	f.queries = map[string]url.Values{}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	api, err := client.New(srv.URL, "test-token", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+// query returns the query of the last request to path.
+func (f *fakeAPI) query(path string) url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries[path]
+}
+
+// operations returns the recorded creates, updates and deletes.
+func (f *fakeAPI) operations() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.ops...)
+}
+
+func (f *fakeAPI) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	q := r.URL.Query()
+	f.queries[r.URL.Path] = q
+	if f.Fail[r.Method+" "+r.URL.Path] {
+		writeError(w, http.StatusConflict, "conflict")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	collection, id := parts[0], ""
+	if len(parts) > 1 {
+		id = parts[1]
+	}
+	var body map[string]interface{}
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	str := func(key string) string {
+		s, _ := body[key].(string)
+		return s
+	}
+
+	switch {
+	case collection == "environments" && id == "" && r.Method == http.MethodGet:
+		var out []client.EnvResponse
+		for _, e := range f.Environments {
+			if match(q, "name", e.Name) {
+				out = append(out, e)
+			}
+		}
+		total := len(out)
+		writeJSON(w, client.EnvListResponse{Environments: paginate(q, out), Total: total})
+	case collection == "environments" && r.Method == http.MethodGet:
+		for _, e := range f.Environments {
+			if e.Id != id {
+				continue
+			}
+			detail := client.EnvDetailResponse{Id: e.Id, Name: e.Name, Blocks: []client.BlockRef{}}
+			for _, b := range f.Blocks {
+				if b.EnvironmentID == e.Id {
+					detail.Blocks = append(detail.Blocks, client.BlockRef{ID: b.ID, Name: b.Name, CIDR: b.CIDR, TotalIPs: b.TotalIPs, UsedIPs: b.UsedIPs, Available: b.Available, EnvironmentID: b.EnvironmentID})
+				}
+			}
+			writeJSON(w, detail)
+			return
+		}
+		writeError(w, http.StatusNotFound, "environment not found")
+
+	case collection == "pools" && id == "" && r.Method == http.MethodGet:
+		var out []client.PoolResponse
+		for _, p := range f.Pools {
+			if match(q, "environment_id", p.EnvironmentID) {
+				out = append(out, p)
+			}
+		}
+		writeJSON(w, client.PoolListResponse{Pools: out})
+	case collection == "pools" && r.Method == http.MethodPost:
+		p := client.PoolResponse{ID: f.newID(), EnvironmentID: str("environment_id"), Name: str("name"), CIDR: str("cidr")}
+		f.Pools = append(f.Pools, p)
+		f.ops = append(f.ops, "create pool "+p.Name)
+		writeJSON(w, p)
+	case collection == "pools":
+		for i, p := range f.Pools {
+			if p.ID != id {
+				continue
+			}
+			switch r.Method {
+			case http.MethodPut:
+				p.Name, p.CIDR = str("name"), str("cidr")
+				f.Pools[i] = p
+				f.ops = append(f.ops, "update pool "+p.Name)
+				writeJSON(w, p)
+			case http.MethodDelete:
+				f.Pools = append(f.Pools[:i], f.Pools[i+1:]...)
+				f.ops = append(f.ops, "delete pool "+p.Name)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				writeJSON(w, p)
+			}
+			return
+		}
+		writeError(w, http.StatusNotFound, "pool not found")
+
+	case collection == "blocks" && id == "" && r.Method == http.MethodGet:
+		var out []client.BlockResponse
+		for _, b := range f.Blocks {
+			orphaned := b.EnvironmentID == ""
+			if match(q, "name", b.Name) && match(q, "environment_id", b.EnvironmentID) && (q.Get("orphaned_only") != "true" || orphaned) {
+				out = append(out, b)
+			}
+		}
+		total := len(out)
+		writeJSON(w, client.BlockListResponse{Blocks: paginate(q, out), Total: total})
+	case collection == "blocks":
+		for i, b := range f.Blocks {
+			if b.ID != id {
+				continue
+			}
+			if r.Method == http.MethodDelete {
+				f.Blocks = append(f.Blocks[:i], f.Blocks[i+1:]...)
+				f.ops = append(f.ops, "delete block "+b.Name)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			writeJSON(w, b)
+			return
+		}
+		writeError(w, http.StatusNotFound, "block not found")
+
+	case collection == "allocations" && id == "" && r.Method == http.MethodGet:
+		var out []client.AllocationResponse
+		for _, a := range f.Allocations {
+			if match(q, "name", a.Name) && match(q, "block_name", a.BlockName) && match(q, "block_id", a.BlockID) {
+				out = append(out, a)
+			}
+		}
+		total := len(out)
+		writeJSON(w, client.AllocationListResponse{Allocations: paginate(q, out), Total: total})
+	case collection == "allocations" && id == "" && r.Method == http.MethodPost:
+		var block *client.BlockResponse
+		for i, b := range f.Blocks {
+			if b.ID == str("block_id") || str("block_id") == "" && b.Name == str("block_name") {
+				block = &f.Blocks[i]
+				break
+			}
+		}
+		if block == nil {
+			writeError(w, http.StatusNotFound, "block not found")
+			return
+		}
+		for _, a := range f.Allocations {
+			if a.BlockID == block.ID && a.Name == str("name") {
+				writeError(w, http.StatusConflict, "allocation name already exists in block")
+				return
+			}
+		}
+		a := client.AllocationResponse{Id: f.newID(), Name: str("name"), BlockName: block.Name, BlockID: block.ID, CIDR: str("cidr")}
+		f.Allocations = append(f.Allocations, a)
+		f.ops = append(f.ops, "create allocation "+a.Name)
+		writeJSON(w, a)
+	case collection == "allocations":
+		for i, a := range f.Allocations {
+			if a.Id != id {
+				continue
+			}
+			switch r.Method {
+			case http.MethodPut:
+				a.Name = str("name")
+				if c := str("cidr"); c != "" {
+					a.CIDR = c
+				}
+				f.Allocations[i] = a
+				f.ops = append(f.ops, "update allocation "+a.Name)
+				writeJSON(w, a)
+			case http.MethodDelete:
+				f.Allocations = append(f.Allocations[:i], f.Allocations[i+1:]...)
+				f.ops = append(f.ops, "delete allocation "+a.Name)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				writeJSON(w, a)
+			}
+			return
+		}
+		writeError(w, http.StatusNotFound, "allocation not found")
+
+	case collection == "reserved-blocks" && r.Method == http.MethodGet:
+		if f.Reserved == nil {
+			writeError(w, http.StatusForbidden, "admin only")
+			return
+		}
+		writeJSON(w, client.ReservedBlockListResponse{ReservedBlocks: f.Reserved})
+
+	default:
+		writeError(w, http.StatusNotFound, "unexpected request "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (f *fakeAPI) newID() string {
+	f.nextID++
+	return fmt.Sprintf("new-%d", f.nextID)
+}
+
+// match reports whether the filter key in q is unset or equal to value.
+func match(q url.Values, key, value string) bool {
+	return q.Get(key) == "" || q.Get(key) == value
+}
+
+// paginate applies the limit and offset in q.
+func paginate[T any](q url.Values, items []T) []T {
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit, _ := strconv.Atoi(q.Get("limit")); limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,178 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+)
+
+// forceDestroyAttribute is the force_destroy attribute of ipam_environment and ipam_block.
+func forceDestroyAttribute(children string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional: true,
+		Computed: true,
+		Default:  booldefault.StaticBool(false),
+		MarkdownDescription: fmt.Sprintf("When `true`, destroying this resource first deletes its %s. "+
+			"The cascade stops before deleting anything if a child allocation overlaps a reserved block, or if a child is protected: "+
+			"the API reports it as protected, or an `ipam_allocation`, `ipam_allocation_set`, `ipam_block` or `ipam_pool` with `deletion_protection = true` in its state or configuration manages it and was planned in the same run. "+
+			"Children managed only by other configurations are not visible to the provider and are deleted too. "+
+			"Must be applied before the destroy to take effect. Defaults to `false`.", children),
+	}
+}
+
+// cascade holds the children of an environment or block in deletion order. Everything is
+// enumerated and checked before the first delete, so a blocked cascade removes nothing.
+type cascade struct {
+	allocations []client.AllocationResponse
+	blocks      []client.BlockResponse
+	pools       []client.PoolResponse
+	removed     []string
+}
+
+// addBlock queues a block's allocations and, when withBlock is set, the block itself.
+func (c *cascade) addBlock(ctx context.Context, api *client.Client, block client.BlockResponse, withBlock bool) error {
+	allocs, err := blockAllocations(ctx, api, &block)
+	if err != nil {
+		return fmt.Errorf("listing allocations in block %q: %w", block.Name, err)
+	}
+	c.allocations = append(c.allocations, allocs...)
+	if withBlock {
+		c.blocks = append(c.blocks, block)
+	}
+	return nil
+}
+
+// addEnvironment queues every block (with its allocations) and pool of an environment.
+func (c *cascade) addEnvironment(ctx context.Context, api *client.Client, envID string) error {
+	blocks, err := api.ListAllBlocks(ctx, "", envID, false)
+	if err != nil {
+		return fmt.Errorf("listing blocks: %w", err)
+	}
+	for _, b := range blocks {
+		if !strings.EqualFold(b.EnvironmentID, envID) {
+			continue
+		}
+		if err := c.addBlock(ctx, api, b, true); err != nil {
+			return err
+		}
+	}
+	pools, err := api.ListPools(ctx, envID)
+	if err != nil {
+		return fmt.Errorf("listing pools: %w", err)
+	}
+	for _, p := range pools.Pools {
+		if strings.EqualFold(p.EnvironmentID, envID) {
+			c.pools = append(c.pools, p)
+		}
+	}
+	return nil
+}
+
+// checkProtected stops the cascade when a queued child is protected, either as reported by the
+// API or as recorded in protections by the resource that manages it.
+func (c *cascade) checkProtected(protections *protectionLedger) error {
+	for _, a := range c.allocations {
+		if a.DeletionProtection || protections.protected(a.Id) {
+			return fmt.Errorf("allocation %q (%s) has deletion protection enabled", a.Name, a.CIDR)
+		}
+	}
+	for _, b := range c.blocks {
+		if b.DeletionProtection || protections.protected(b.ID) {
+			return fmt.Errorf("block %q (%s) has deletion protection enabled", b.Name, b.CIDR)
+		}
+	}
+	for _, p := range c.pools {
+		if p.DeletionProtection || protections.protected(p.ID) {
+			return fmt.Errorf("pool %q (%s) has deletion protection enabled", p.Name, p.CIDR)
+		}
+	}
+	return nil
+}
+
+// checkReserved stops the cascade when a queued allocation overlaps a reserved block. Failing to
+// list reserved blocks also stops it, since the check cannot be made.
+func (c *cascade) checkReserved(ctx context.Context, api *client.Client) error {
+	if len(c.allocations) == 0 {
+		return nil
+	}
+	reserved, err := api.ListReservedBlocks(ctx, "")
+	if err != nil {
+		return fmt.Errorf("listing reserved blocks: %w", err)
+	}
+	for _, a := range c.allocations {
+		ap, err := netip.ParsePrefix(a.CIDR)
+		if err != nil {
+			continue
+		}
+		for _, rb := range reserved.ReservedBlocks {
+			if rp, err := netip.ParsePrefix(rb.CIDR); err == nil && rp.Overlaps(ap) {
+				return fmt.Errorf("allocation %q (%s) overlaps reserved block %q (%s); release it before force destroying", a.Name, a.CIDR, rb.Name, rb.CIDR)
+			}
+		}
+	}
+	return nil
+}
+
+// run deletes the queued children: allocations, then blocks, then pools. Objects that are
+// already gone are skipped and not listed. c.removed lists what was deleted, also when run
+// fails part way.
+func (c *cascade) run(ctx context.Context, api *client.Client) error {
+	for _, a := range c.allocations {
+		if gone, err := deleted(api.DeleteAllocation(ctx, a.Id)); err != nil {
+			return fmt.Errorf("deleting allocation %q: %w", a.Name, err)
+		} else if !gone {
+			c.removed = append(c.removed, fmt.Sprintf("allocation %q (%s)", a.Name, a.CIDR))
+		}
+	}
+	for _, b := range c.blocks {
+		if gone, err := deleted(api.DeleteBlock(ctx, b.ID)); err != nil {
+			return fmt.Errorf("deleting block %q: %w", b.Name, err)
+		} else if !gone {
+			c.removed = append(c.removed, fmt.Sprintf("block %q (%s)", b.Name, b.CIDR))
+		}
+	}
+	for _, p := range c.pools {
+		if gone, err := deleted(api.DeletePool(ctx, p.ID)); err != nil {
+			return fmt.Errorf("deleting pool %q: %w", p.Name, err)
+		} else if !gone {
+			c.removed = append(c.removed, fmt.Sprintf("pool %q (%s)", p.Name, p.CIDR))
+		}
+	}
+	return nil
+}
+
+// deleted interprets the result of a delete call: an object that is already gone is not an
+// error, but it was not removed by the cascade either.
+func deleted(err error) (gone bool, _ error) {
+	if err != nil && isNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
+
+// destroy checks and runs the cascade for the named parent, refusing protected children. What was removed is reported as a
+// warning; a blocked or failed cascade is an error and the parent must not be deleted.
+func (c *cascade) destroy(ctx context.Context, api *client.Client, protections *protectionLedger, typeName, name string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	err := c.checkProtected(protections)
+	if err == nil {
+		err = c.checkReserved(ctx, api)
+	}
+	if err == nil {
+		err = c.run(ctx, api)
+	}
+	if len(c.removed) > 0 {
+		diags.AddWarning("Force destroy removed child objects",
+			fmt.Sprintf("%s %q: removed %d object(s):\n- %s", typeName, name, len(c.removed), strings.Join(c.removed, "\n- ")))
+	}
+	if err != nil {
+		diags.AddError("Force destroy stopped", fmt.Sprintf("%s %q: %s", typeName, name, err))
+	}
+	return diags
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+)
+
+// cascadeFixture is environment env-1 with one pool, two blocks and their allocations, plus a
+// block "app" in another environment, with the given reserved blocks (nil means listing them is
+// forbidden).
+func cascadeFixture(reserved []client.ReservedBlockResponse) *fakeAPI {
+	pool := "pool-1"
+	return &fakeAPI{
+		Pools: []client.PoolResponse{{ID: "pool-1", EnvironmentID: "env-1", Name: "prod-pool", CIDR: "10.0.0.0/16"}},
+		Blocks: []client.BlockResponse{
+			{ID: "b1", Name: "app", CIDR: "10.0.0.0/24", EnvironmentID: "env-1", PoolID: &pool},
+			{ID: "b2", Name: "db", CIDR: "10.0.1.0/24", EnvironmentID: "env-1", PoolID: &pool},
+			{ID: "b9", Name: "app", CIDR: "10.9.0.0/24", EnvironmentID: "env-2"},
+		},
+		Allocations: []client.AllocationResponse{
+			{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"},
+			{Id: "a2", Name: "pg", BlockName: "db", BlockID: "b2", CIDR: "10.0.1.0/26"},
+			{Id: "a9", Name: "other", BlockName: "app", BlockID: "b9", CIDR: "10.9.0.0/26"},
+		},
+		Reserved: reserved,
+	}
+}
+
+func TestCascadeEnvironmentOrder(t *testing.T) {
+	ctx := context.Background()
+	f := cascadeFixture([]client.ReservedBlockResponse{{ID: "r1", Name: "vpn", CIDR: "192.168.0.0/24"}})
+	api := newFakeAPI(t, f)
+	var c cascade
+	if err := c.addEnvironment(ctx, api, "env-1"); err != nil {
+		t.Fatal(err)
+	}
+	diags := c.destroy(ctx, api, nil, "ipam_environment", "prod")
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	// Allocations first, then blocks, then pools; the other environment's "app" block is untouched.
+	want := []string{"delete allocation web", "delete allocation pg", "delete block app", "delete block db", "delete pool prod-pool"}
+	if !reflect.DeepEqual(f.operations(), want) {
+		t.Errorf("deleted %v, want %v", f.operations(), want)
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0].Detail(), "removed 5 object(s)") {
+		t.Errorf("expected one warning listing 5 removed objects, got %v", diags)
+	}
+}
+
+func TestCascadeBlockKeepsBlock(t *testing.T) {
+	ctx := context.Background()
+	f := cascadeFixture([]client.ReservedBlockResponse{})
+	api := newFakeAPI(t, f)
+	var c cascade
+	if err := c.addBlock(ctx, api, client.BlockResponse{ID: "b1", Name: "app", CIDR: "10.0.0.0/24"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if diags := c.destroy(ctx, api, nil, "ipam_block", "app"); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if want := []string{"delete allocation web"}; !reflect.DeepEqual(f.operations(), want) {
+		t.Errorf("deleted %v, want %v", f.operations(), want)
+	}
+}
+
+func TestCascadePartialFailure(t *testing.T) {
+	ctx := context.Background()
+	f := cascadeFixture([]client.ReservedBlockResponse{})
+	f.Fail = map[string]bool{"DELETE /api/blocks/b2": true}
+	api := newFakeAPI(t, f)
+	var c cascade
+	if err := c.addEnvironment(ctx, api, "env-1"); err != nil {
+		t.Fatal(err)
+	}
+	diags := c.destroy(ctx, api, nil, "ipam_environment", "prod")
+	if !diags.HasError() {
+		t.Fatal("expected an error")
+	}
+	want := []string{"delete allocation web", "delete allocation pg", "delete block app"}
+	if !reflect.DeepEqual(f.operations(), want) {
+		t.Errorf("deleted %v, want %v", f.operations(), want)
+	}
+	// What was removed before the failure is still reported.
+	if len(c.removed) != 3 {
+		t.Errorf("removed %v, want 3 entries", c.removed)
+	}
+	if got := diags.Errors()[0].Detail(); !strings.Contains(got, `deleting block "db"`) {
+		t.Errorf("error %q does not name the failed block", got)
+	}
+}
+
+func TestCascadeSkipsMissing(t *testing.T) {
+	ctx := context.Background()
+	f := cascadeFixture([]client.ReservedBlockResponse{})
+	api := newFakeAPI(t, f)
+	c := cascade{pools: []client.PoolResponse{{ID: "pool-gone", Name: "gone", CIDR: "10.5.0.0/16"}}}
+	diags := c.destroy(ctx, api, nil, "ipam_environment", "prod")
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(c.removed) != 0 || len(diags) != 0 {
+		t.Errorf("an object that was already gone was reported as removed: %v", c.removed)
+	}
+}
+
+func TestCascadeRefusal(t *testing.T) {
+	tests := []struct {
+		name      string
+		reserved  []client.ReservedBlockResponse
+		protected []string // IDs recorded in the protection ledger
+		seed      func(f *fakeAPI)
+		want      string
+	}{
+		{
+			name:     "allocation overlaps reserved block",
+			reserved: []client.ReservedBlockResponse{{ID: "r1", Name: "vpn", CIDR: "10.0.1.0/28"}},
+			want:     `allocation "pg" (10.0.1.0/26) overlaps reserved block "vpn"`,
+		},
+		{
+			name: "reserved blocks cannot be listed",
+			want: "listing reserved blocks",
+		},
+		{
+			name:      "allocation protected by its resource",
+			reserved:  []client.ReservedBlockResponse{},
+			protected: []string{"A2"},
+			want:      `allocation "pg" (10.0.1.0/26) has deletion protection enabled`,
+		},
+		{
+			name:      "pool protected by its resource",
+			reserved:  []client.ReservedBlockResponse{},
+			protected: []string{"pool-1"},
+			want:      `pool "prod-pool" (10.0.0.0/16) has deletion protection enabled`,
+		},
+		{
+			name:     "block protected in the API",
+			reserved: []client.ReservedBlockResponse{},
+			seed:     func(f *fakeAPI) { f.Blocks[1].DeletionProtection = true },
+			want:     `block "db" (10.0.1.0/24) has deletion protection enabled`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := cascadeFixture(tt.reserved)
+			if tt.seed != nil {
+				tt.seed(f)
+			}
+			api := newFakeAPI(t, f)
+			protections := newProtectionLedger()
+			for _, id := range tt.protected {
+				protections.record(id)
+			}
+			var c cascade
+			if err := c.addEnvironment(ctx, api, "env-1"); err != nil {
+				t.Fatal(err)
+			}
+			diags := c.destroy(ctx, api, protections, "ipam_environment", "prod")
+			if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", diags, tt.want)
+			}
+			if len(f.operations()) != 0 {
+				t.Errorf("a refused cascade deleted %v", f.operations())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"net/netip"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The free space data sources report where the unused space of a block or pool is, computed
+// from the allocations (or blocks) and reserved blocks inside it, as usedInBlock and usedInPool
+// see them.
+
+type freeSpaceCapacityModel struct {
+	PrefixLength types.Int64  `tfsdk:"prefix_length"`
+	Count        types.String `tfsdk:"count"`
+}
+
+var freeSpaceCapacityType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"prefix_length": types.Int64Type,
+	"count":         types.StringType,
+}}
+
+// freeSpace holds the computed attributes shared by the free space data sources.
+type freeSpace struct {
+	FreeCidrs         types.List
+	FreeIps           types.String
+	LargestFreePrefix types.Int64
+	Capacity          types.List
+	Fragmentation     types.Float64
+}
+
+// freeSpaceAttributes returns the computed attributes shared by the free space data sources.
+func freeSpaceAttributes(kind string) map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"cidr": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "CIDR of the " + kind + ".",
+		},
+		"free_cidrs": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Computed:            true,
+			MarkdownDescription: "The free space as the fewest CIDRs that cover it exactly, in address order.",
+		},
+		"free_ips": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Number of free addresses (string; IPv6 counts exceed 64 bits).",
+		},
+		"largest_free_prefix": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Shortest prefix length that still fits, i.e. the largest allocatable prefix (e.g. 20 when a /20 fits but a /19 does not). Null when the " + kind + " is full.",
+		},
+		"capacity": schema.ListNestedAttribute{
+			Computed:            true,
+			MarkdownDescription: "How many more prefixes of each length fit, from `largest_free_prefix` to /32 (IPv4) or /128 (IPv6). Empty when the " + kind + " is full.",
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"prefix_length": schema.Int64Attribute{Computed: true, MarkdownDescription: "Prefix length."},
+					"count":         schema.StringAttribute{Computed: true, MarkdownDescription: "Number of non-overlapping prefixes of this length that fit (string; may exceed 64 bits)."},
+				},
+			},
+		},
+		"fragmentation": schema.Float64Attribute{
+			Computed:            true,
+			MarkdownDescription: "Fragmentation score from 0 to 1: the share of free addresses outside the largest free CIDR. 0 when the free space is one CIDR (or there is none); close to 1 when it is scattered in small pieces.",
+		},
+	}
+}
+
+// computeFreeSpace reports the free space of parent given the prefixes used in it.
+func computeFreeSpace(ctx context.Context, parent netip.Prefix, used []netip.Prefix) (freeSpace, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	free := cidr.Free(parent, used)
+	width := parent.Addr().BitLen()
+
+	cidrs := make([]string, 0, len(free))
+	total := new(big.Int)
+	largest := netip.Prefix{}
+	for _, p := range free {
+		cidrs = append(cidrs, p.String())
+		total.Add(total, cidr.Size(p))
+		if !largest.IsValid() || p.Bits() < largest.Bits() {
+			largest = p
+		}
+	}
+
+	capacity := []freeSpaceCapacityModel{}
+	out := freeSpace{LargestFreePrefix: types.Int64Null(), Fragmentation: types.Float64Value(0)}
+	if largest.IsValid() {
+		out.LargestFreePrefix = types.Int64Value(int64(largest.Bits()))
+		for bits := largest.Bits(); bits <= width; bits++ {
+			// Free CIDRs are aligned and disjoint, so each holds 2^(bits - its length) prefixes.
+			n := new(big.Int)
+			for _, p := range free {
+				if p.Bits() <= bits {
+					n.Add(n, new(big.Int).Lsh(big.NewInt(1), uint(bits-p.Bits())))
+				}
+			}
+			capacity = append(capacity, freeSpaceCapacityModel{PrefixLength: types.Int64Value(int64(bits)), Count: types.StringValue(n.String())})
+		}
+		ratio := new(big.Rat).SetFrac(cidr.Size(largest), total)
+		f, _ := ratio.Float64()
+		out.Fragmentation = types.Float64Value(1 - f)
+	}
+
+	var d diag.Diagnostics
+	out.FreeCidrs, d = types.ListValueFrom(ctx, types.StringType, cidrs)
+	diags.Append(d...)
+	out.Capacity, d = types.ListValueFrom(ctx, freeSpaceCapacityType, capacity)
+	diags.Append(d...)
+	out.FreeIps = types.StringValue(total.String())
+	return out, diags
+}
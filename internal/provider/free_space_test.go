@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+)
+
+func TestComputeFreeSpace(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		parent        string
+		used          []string
+		freeCidrs     []string
+		freeIps       string
+		largest       int64 // 0 when full
+		capacity      map[int64]string
+		fragmentation float64
+	}{
+		{
+			name:          "empty",
+			parent:        "10.0.0.0/24",
+			freeCidrs:     []string{"10.0.0.0/24"},
+			freeIps:       "256",
+			largest:       24,
+			capacity:      map[int64]string{24: "1", 25: "2", 32: "256"},
+			fragmentation: 0,
+		},
+		{
+			name:          "two holes",
+			parent:        "10.0.0.0/24",
+			used:          []string{"10.0.0.0/26", "10.0.0.192/26"},
+			freeCidrs:     []string{"10.0.0.64/26", "10.0.0.128/26"},
+			freeIps:       "128",
+			largest:       26,
+			capacity:      map[int64]string{26: "2", 27: "4", 32: "128"},
+			fragmentation: 0.5,
+		},
+		{
+			name:          "uneven holes",
+			parent:        "10.0.0.0/24",
+			used:          []string{"10.0.0.0/30", "10.0.0.128/25"},
+			freeCidrs:     []string{"10.0.0.4/30", "10.0.0.8/29", "10.0.0.16/28", "10.0.0.32/27", "10.0.0.64/26"},
+			freeIps:       "124",
+			largest:       26,
+			capacity:      map[int64]string{26: "1", 27: "3", 30: "31", 32: "124"},
+			fragmentation: 1 - 64.0/124.0,
+		},
+		{
+			name:      "full",
+			parent:    "10.0.0.0/24",
+			used:      []string{"10.0.0.0/24"},
+			freeCidrs: []string{},
+			freeIps:   "0",
+		},
+		{
+			name:          "IPv6",
+			parent:        "2001:db8::/48",
+			used:          []string{"2001:db8::/49"},
+			freeCidrs:     []string{"2001:db8:0:8000::/49"},
+			freeIps:       "604462909807314587353088",
+			largest:       49,
+			capacity:      map[int64]string{49: "1", 64: "32768", 128: "604462909807314587353088"},
+			fragmentation: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make([]netip.Prefix, 0, len(tt.used))
+			for _, s := range tt.used {
+				used = append(used, netip.MustParsePrefix(s))
+			}
+			got, diags := computeFreeSpace(ctx, netip.MustParsePrefix(tt.parent), used)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			var free []string
+			got.FreeCidrs.ElementsAs(ctx, &free, false)
+			if len(free) != len(tt.freeCidrs) {
+				t.Fatalf("free_cidrs = %v, want %v", free, tt.freeCidrs)
+			}
+			for i := range free {
+				if free[i] != tt.freeCidrs[i] {
+					t.Errorf("free_cidrs = %v, want %v", free, tt.freeCidrs)
+					break
+				}
+			}
+			if got.FreeIps.ValueString() != tt.freeIps {
+				t.Errorf("free_ips = %s, want %s", got.FreeIps, tt.freeIps)
+			}
+			if tt.largest == 0 {
+				if !got.LargestFreePrefix.IsNull() || len(got.Capacity.Elements()) != 0 {
+					t.Errorf("full parent: largest_free_prefix = %s, capacity = %s", got.LargestFreePrefix, got.Capacity)
+				}
+			} else if got.LargestFreePrefix.ValueInt64() != tt.largest {
+				t.Errorf("largest_free_prefix = %s, want %d", got.LargestFreePrefix, tt.largest)
+			}
+			var capacity []freeSpaceCapacityModel
+			got.Capacity.ElementsAs(ctx, &capacity, false)
+			counts := map[int64]string{}
+			for _, c := range capacity {
+				counts[c.PrefixLength.ValueInt64()] = c.Count.ValueString()
+			}
+			for bits, want := range tt.capacity {
+				if counts[bits] != want {
+					t.Errorf("capacity[/%d] = %q, want %q", bits, counts[bits], want)
+				}
+			}
+			if d := got.Fragmentation.ValueFloat64() - tt.fragmentation; d > 1e-9 || d < -1e-9 {
+				t.Errorf("fragmentation = %v, want %v", got.Fragmentation.ValueFloat64(), tt.fragmentation)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Provider functions are pure: they never call the IPAM API, so they work in terraform console
+// and before the provider is configured. Like Terraform's own cidr functions they accept CIDRs
+// with host bits set and use the network address, and treat IPv4 and IPv6 alike; prefixes of
+// different address families never contain or overlap each other.
+
+// cidrArg parses argument i of a function as a CIDR prefix, masked to its network address.
+func cidrArg(i int64, s string) (netip.Prefix, *function.FuncError) {
+	p, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Prefix{}, function.NewArgumentFuncError(i, fmt.Sprintf("%q is not a valid CIDR prefix: %s", s, err))
+	}
+	return p.Masked(), nil
+}
+
+// cidrOrAddressArg parses argument i as a CIDR prefix or a single IP address, which is treated
+// as a /32 (IPv4) or /128 (IPv6).
+func cidrOrAddressArg(i int64, s string) (netip.Prefix, *function.FuncError) {
+	if a, err := netip.ParseAddr(strings.TrimSpace(s)); err == nil {
+		return netip.PrefixFrom(a.WithZone(""), a.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Prefix{}, function.NewArgumentFuncError(i, fmt.Sprintf("%q is not a valid CIDR prefix or IP address", s))
+	}
+	return p.Masked(), nil
+}
+
+// cidrListArg parses every element of list argument i with cidrArg.
+func cidrListArg(i int64, list []string) ([]netip.Prefix, *function.FuncError) {
+	out := make([]netip.Prefix, 0, len(list))
+	for n, s := range list {
+		p, err := cidrArg(i, s)
+		if err != nil {
+			return nil, function.NewArgumentFuncError(i, fmt.Sprintf("element %d: %s", n, err.Text))
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
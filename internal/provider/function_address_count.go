@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &AddressCountFunction{}
+
+func NewAddressCountFunction() function.Function {
+	return &AddressCountFunction{}
+}
+
+type AddressCountFunction struct{}
+
+func (f *AddressCountFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "address_count"
+}
+
+func (f *AddressCountFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "The number of addresses in a CIDR",
+		MarkdownDescription: "Returns the number of addresses in `cidr`, exactly, including for IPv6 prefixes whose size does not fit in 64 bits (`::/0` has 2^128 addresses).",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "cidr", MarkdownDescription: "CIDR to measure, e.g. `10.0.0.0/16`."},
+		},
+		Return: function.NumberReturn{},
+	}
+}
+
+func (f *AddressCountFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var cidrArgValue string
+	resp.Error = req.Arguments.Get(ctx, &cidrArgValue)
+	if resp.Error != nil {
+		return
+	}
+	p, err := cidrArg(0, cidrArgValue)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, new(big.Float).SetInt(cidr.Size(p)))
+}
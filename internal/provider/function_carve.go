@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &CarveFunction{}
+
+func NewCarveFunction() function.Function {
+	return &CarveFunction{}
+}
+
+type CarveFunction struct{}
+
+func (f *CarveFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "carve"
+}
+
+func (f *CarveFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Lay out named subnets inside a parent range",
+		MarkdownDescription: "Places one prefix per entry of `prefixes` (name to prefix length) inside `parent` without overlaps and returns a map of name to CIDR. Larger prefixes are placed first, ties broken by name, each at the lowest free aligned address: the same layout `ipam_allocation_set` creates in an empty block. The result depends only on the arguments, so it is stable across runs. Fails when the prefixes do not fit.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "parent", MarkdownDescription: "Range to carve, e.g. `10.0.0.0/16`."},
+			function.MapParameter{Name: "prefixes", ElementType: types.Int64Type, MarkdownDescription: "Prefix length for each subnet name, e.g. `{ public = 24, private = 20 }`."},
+		},
+		Return: function.MapReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *CarveFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var parentArg string
+	var prefixes map[string]int64
+	resp.Error = req.Arguments.Get(ctx, &parentArg, &prefixes)
+	if resp.Error != nil {
+		return
+	}
+	parent, err := cidrArg(0, parentArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	reqs := make([]cidr.Request, 0, len(prefixes))
+	for name, bits := range prefixes {
+		if bits < int64(parent.Bits()) || bits > int64(parent.Addr().BitLen()) {
+			resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("%s: prefix length /%d does not fit in %s", name, bits, parent))
+			return
+		}
+		reqs = append(reqs, cidr.Request{Name: name, Bits: int(bits)})
+	}
+	placed, carveErr := cidr.Carve(parent, nil, reqs)
+	if carveErr != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("cannot carve %s: %s", parent, carveErr))
+		return
+	}
+	out := make(map[string]string, len(placed))
+	for name, p := range placed {
+		out[name] = p.String()
+	}
+	resp.Error = resp.Result.Set(ctx, out)
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &CIDRContainsFunction{}
+
+func NewCIDRContainsFunction() function.Function {
+	return &CIDRContainsFunction{}
+}
+
+type CIDRContainsFunction struct{}
+
+func (f *CIDRContainsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_contains"
+}
+
+func (f *CIDRContainsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Whether a CIDR contains an address or another CIDR",
+		MarkdownDescription: "Returns `true` when every address of `other` is inside `cidr`. `other` may be a CIDR or a single IP address. A CIDR contains itself; prefixes of different address families never contain each other.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "cidr", MarkdownDescription: "Enclosing CIDR, e.g. `10.0.0.0/16`."},
+			function.StringParameter{Name: "other", MarkdownDescription: "CIDR or IP address to test, e.g. `10.0.1.0/24` or `10.0.1.5`."},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *CIDRContainsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var outerArg, innerArg string
+	resp.Error = req.Arguments.Get(ctx, &outerArg, &innerArg)
+	if resp.Error != nil {
+		return
+	}
+	outer, err := cidrArg(0, outerArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	inner, err := cidrOrAddressArg(1, innerArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, contains(outer, inner))
+}
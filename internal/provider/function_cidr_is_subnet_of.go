@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &CIDRIsSubnetOfFunction{}
+
+func NewCIDRIsSubnetOfFunction() function.Function {
+	return &CIDRIsSubnetOfFunction{}
+}
+
+type CIDRIsSubnetOfFunction struct{}
+
+func (f *CIDRIsSubnetOfFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_is_subnet_of"
+}
+
+func (f *CIDRIsSubnetOfFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Whether a CIDR is a subnet of another",
+		MarkdownDescription: "Returns `true` when `subnet` lies entirely inside `supernet`: the argument order of `cidr_contains`, reversed. A CIDR is a subnet of itself; prefixes of different address families never are.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "subnet", MarkdownDescription: "CIDR to test, e.g. `10.0.1.0/24`."},
+			function.StringParameter{Name: "supernet", MarkdownDescription: "Enclosing CIDR, e.g. `10.0.0.0/16`."},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *CIDRIsSubnetOfFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var subnetArg, supernetArg string
+	resp.Error = req.Arguments.Get(ctx, &subnetArg, &supernetArg)
+	if resp.Error != nil {
+		return
+	}
+	subnet, err := cidrArg(0, subnetArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	supernet, err := cidrArg(1, supernetArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, contains(supernet, subnet))
+}
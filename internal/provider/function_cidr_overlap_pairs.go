@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &CIDROverlapPairsFunction{}
+
+func NewCIDROverlapPairsFunction() function.Function {
+	return &CIDROverlapPairsFunction{}
+}
+
+type CIDROverlapPairsFunction struct{}
+
+type overlapPairModel struct {
+	A types.String `tfsdk:"a"`
+	B types.String `tfsdk:"b"`
+}
+
+var overlapPairType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"a": types.StringType,
+	"b": types.StringType,
+}}
+
+func (f *CIDROverlapPairsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_overlap_pairs"
+}
+
+func (f *CIDROverlapPairsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Every pair of overlapping CIDRs in a list",
+		MarkdownDescription: "Returns one `{ a, b }` object for each pair of entries in `cidrs` that share any address, as written in the list and in list order (`a` comes before `b`). An empty result means the list is free of overlaps, so `length(provider::ipam::cidr_overlap_pairs(var.subnets)) == 0` works as a validation condition. Duplicate entries count as overlapping.",
+		Parameters: []function.Parameter{
+			function.ListParameter{Name: "cidrs", ElementType: types.StringType, MarkdownDescription: "CIDRs to compare, IPv4 and IPv6 mixed freely."},
+		},
+		Return: function.ListReturn{ElementType: overlapPairType},
+	}
+}
+
+func (f *CIDROverlapPairsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var list []string
+	resp.Error = req.Arguments.Get(ctx, &list)
+	if resp.Error != nil {
+		return
+	}
+	prefixes, err := cidrListArg(0, list)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	pairs := []overlapPairModel{}
+	for i := range prefixes {
+		for j := i + 1; j < len(prefixes); j++ {
+			if prefixes[i].Overlaps(prefixes[j]) {
+				pairs = append(pairs, overlapPairModel{A: types.StringValue(list[i]), B: types.StringValue(list[j])})
+			}
+		}
+	}
+	result, diags := types.ListValueFrom(ctx, overlapPairType, pairs)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, result)
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &CIDROverlapsFunction{}
+
+func NewCIDROverlapsFunction() function.Function {
+	return &CIDROverlapsFunction{}
+}
+
+type CIDROverlapsFunction struct{}
+
+func (f *CIDROverlapsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_overlaps"
+}
+
+func (f *CIDROverlapsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Whether two CIDRs share any address",
+		MarkdownDescription: "Returns `true` when `a` and `b` have at least one address in common, including when one contains the other. Prefixes of different address families never overlap.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "a", MarkdownDescription: "First CIDR."},
+			function.StringParameter{Name: "b", MarkdownDescription: "Second CIDR."},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *CIDROverlapsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var aArg, bArg string
+	resp.Error = req.Arguments.Get(ctx, &aArg, &bArg)
+	if resp.Error != nil {
+		return
+	}
+	a, err := cidrArg(0, aArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	b, err := cidrArg(1, bArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, a.Overlaps(b))
+}
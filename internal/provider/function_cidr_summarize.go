@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &CIDRSummarizeFunction{}
+
+func NewCIDRSummarizeFunction() function.Function {
+	return &CIDRSummarizeFunction{}
+}
+
+type CIDRSummarizeFunction struct{}
+
+func (f *CIDRSummarizeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_summarize"
+}
+
+func (f *CIDRSummarizeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Merge a list of CIDRs into the fewest covering CIDRs",
+		MarkdownDescription: "Returns the fewest CIDRs that cover exactly the addresses of `cidrs`: overlapping and adjacent entries are merged, so `[\"10.0.0.0/24\", \"10.0.1.0/24\"]` becomes `[\"10.0.0.0/23\"]`. IPv4 CIDRs come before IPv6, each in address order.",
+		Parameters: []function.Parameter{
+			function.ListParameter{Name: "cidrs", ElementType: types.StringType, MarkdownDescription: "CIDRs to summarize, e.g. route table entries."},
+		},
+		Return: function.ListReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *CIDRSummarizeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var list []string
+	resp.Error = req.Arguments.Get(ctx, &list)
+	if resp.Error != nil {
+		return
+	}
+	prefixes, err := cidrListArg(0, list)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	out := []string{}
+	for _, p := range cidr.Summarize(prefixes) {
+		out = append(out, p.String())
+	}
+	resp.Error = resp.Result.Set(ctx, out)
+}
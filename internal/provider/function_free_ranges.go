@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &FreeRangesFunction{}
+
+func NewFreeRangesFunction() function.Function {
+	return &FreeRangesFunction{}
+}
+
+type FreeRangesFunction struct{}
+
+func (f *FreeRangesFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "free_ranges"
+}
+
+func (f *FreeRangesFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "The unused space of a parent range as CIDRs",
+		MarkdownDescription: "Returns the space of `parent` not covered by any of `used` as the fewest CIDRs that cover it exactly, in address order. Entries of `used` outside `parent` or of the other address family are ignored. Returns an empty list when `parent` is full.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "parent", MarkdownDescription: "Range to inspect, e.g. a block CIDR."},
+			function.ListParameter{Name: "used", ElementType: types.StringType, MarkdownDescription: "CIDRs already taken."},
+		},
+		Return: function.ListReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *FreeRangesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var parentArg string
+	var usedArg []string
+	resp.Error = req.Arguments.Get(ctx, &parentArg, &usedArg)
+	if resp.Error != nil {
+		return
+	}
+	parent, err := cidrArg(0, parentArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	used, err := cidrListArg(1, usedArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	free := []string{}
+	for _, p := range cidr.Free(parent, used) {
+		free = append(free, p.String())
+	}
+	resp.Error = resp.Result.Set(ctx, free)
+}
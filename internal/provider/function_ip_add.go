@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strings"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &IPAddFunction{}
+
+func NewIPAddFunction() function.Function {
+	return &IPAddFunction{}
+}
+
+type IPAddFunction struct{}
+
+func (f *IPAddFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ip_add"
+}
+
+func (f *IPAddFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Offset an IP address",
+		MarkdownDescription: "Returns the address `n` addresses after `ip`, or before it when `n` is negative. `n` may be larger than 64 bits for IPv6. Fails when the result falls outside the address family of `ip`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "ip", MarkdownDescription: "IPv4 or IPv6 address, e.g. `10.0.0.1`."},
+			function.NumberParameter{Name: "n", MarkdownDescription: "Whole number of addresses to add."},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *IPAddFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ipArg string
+	var n *big.Float
+	resp.Error = req.Arguments.Get(ctx, &ipArg, &n)
+	if resp.Error != nil {
+		return
+	}
+	ip, err := addressArg(0, ipArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	if !n.IsInt() {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("%s is not a whole number", n.Text('g', -1)))
+		return
+	}
+	offset, _ := n.Int(nil)
+	out, addErr := cidr.Add(ip, offset)
+	if addErr != nil {
+		resp.Error = function.NewFuncError(addErr.Error())
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, out.String())
+}
+
+// addressArg parses argument i as an IP address.
+func addressArg(i int64, s string) (netip.Addr, *function.FuncError) {
+	a, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, function.NewArgumentFuncError(i, fmt.Sprintf("%q is not a valid IP address", s))
+	}
+	return a.WithZone(""), nil
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &IPRangeToCIDRsFunction{}
+
+func NewIPRangeToCIDRsFunction() function.Function {
+	return &IPRangeToCIDRsFunction{}
+}
+
+type IPRangeToCIDRsFunction struct{}
+
+func (f *IPRangeToCIDRsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ip_range_to_cidrs"
+}
+
+func (f *IPRangeToCIDRsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "The CIDRs covering an address range",
+		MarkdownDescription: "Returns the fewest CIDRs that cover exactly the addresses from `start` to `end`, inclusive, in address order. Both addresses must be of the same family, and `start` must not come after `end`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "start", MarkdownDescription: "First address of the range."},
+			function.StringParameter{Name: "end", MarkdownDescription: "Last address of the range."},
+		},
+		Return: function.ListReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *IPRangeToCIDRsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var startArg, endArg string
+	resp.Error = req.Arguments.Get(ctx, &startArg, &endArg)
+	if resp.Error != nil {
+		return
+	}
+	start, err := addressArg(0, startArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	end, err := addressArg(1, endArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	prefixes, rangeErr := cidr.Range(start, end)
+	if rangeErr != nil {
+		resp.Error = function.NewFuncError(rangeErr.Error())
+		return
+	}
+	out := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		out = append(out, p.String())
+	}
+	resp.Error = resp.Result.Set(ctx, out)
+}
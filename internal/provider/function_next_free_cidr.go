@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &NextFreeCIDRFunction{}
+
+func NewNextFreeCIDRFunction() function.Function {
+	return &NextFreeCIDRFunction{}
+}
+
+type NextFreeCIDRFunction struct{}
+
+func (f *NextFreeCIDRFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "next_free_cidr"
+}
+
+func (f *NextFreeCIDRFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "The next free CIDR of a given size in a parent range",
+		MarkdownDescription: "Returns the lowest free, aligned prefix of length `prefix_length` inside `parent` that overlaps none of `used`: the first-fit packing the IPAM server uses to auto-allocate. Entries of `used` outside `parent` or of the other address family are ignored. Fails when no such prefix is free.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "parent", MarkdownDescription: "Range to allocate from, e.g. a block CIDR."},
+			function.ListParameter{Name: "used", ElementType: types.StringType, MarkdownDescription: "CIDRs already taken."},
+			function.Int64Parameter{Name: "prefix_length", MarkdownDescription: "Length of the prefix to find, e.g. `24`."},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *NextFreeCIDRFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var parentArg string
+	var usedArg []string
+	var prefixLength int64
+	resp.Error = req.Arguments.Get(ctx, &parentArg, &usedArg, &prefixLength)
+	if resp.Error != nil {
+		return
+	}
+	parent, err := cidrArg(0, parentArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	used, err := cidrListArg(1, usedArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	if prefixLength < int64(parent.Bits()) || prefixLength > int64(parent.Addr().BitLen()) {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("prefix length /%d does not fit in %s", prefixLength, parent))
+		return
+	}
+	p, findErr := cidr.Find(parent, used, int(prefixLength), cidr.Options{})
+	if errors.Is(findErr, cidr.ErrExhausted) {
+		resp.Error = function.NewFuncError(fmt.Sprintf("no /%d is free in %s", prefixLength, parent))
+		return
+	}
+	if findErr != nil {
+		resp.Error = function.NewFuncError(findErr.Error())
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, p.String())
+}
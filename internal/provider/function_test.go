@@ -0,0 +1,425 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// runFunction calls a provider function with the given arguments. result is an unknown value of
+// the function's return type.
+func runFunction(fn function.Function, result attr.Value, args ...attr.Value) (attr.Value, *function.FuncError) {
+	resp := function.RunResponse{Result: function.NewResultData(result)}
+	fn.Run(context.Background(), function.RunRequest{Arguments: function.NewArgumentsData(args)}, &resp)
+	return resp.Result.Value(), resp.Error
+}
+
+func TestCIDRPredicateFunctions(t *testing.T) {
+	containsFn, overlapsFn, subnetFn := NewCIDRContainsFunction(), NewCIDROverlapsFunction(), NewCIDRIsSubnetOfFunction()
+	tests := []struct {
+		name   string
+		fn     function.Function
+		a, b   string
+		want   bool
+		errArg int64 // argument expected to be rejected, or -1
+	}{
+		{"contains subnet", containsFn, "10.0.0.0/16", "10.0.1.0/24", true, -1},
+		{"contains itself", containsFn, "10.0.0.0/16", "10.0.0.0/16", true, -1},
+		{"does not contain supernet", containsFn, "10.0.0.0/24", "10.0.0.0/16", false, -1},
+		{"does not contain sibling", containsFn, "10.0.0.0/24", "10.0.1.0/24", false, -1},
+		{"contains address", containsFn, "10.0.0.0/16", "10.0.1.5", true, -1},
+		{"does not contain address", containsFn, "10.0.0.0/16", "10.1.0.0", false, -1},
+		{"contains last address", containsFn, "10.0.0.0/16", "10.0.255.255", true, -1},
+		{"whole IPv4 space", containsFn, "0.0.0.0/0", "255.255.255.255/32", true, -1},
+		{"host bits ignored", containsFn, "10.0.0.1/16", "10.0.255.0/24", true, -1},
+		{"IPv6 contains subnet", containsFn, "2001:db8::/32", "2001:db8:1::/48", true, -1},
+		{"IPv6 spelling ignored", containsFn, "2001:DB8::/32", "2001:db8:0:0::1", true, -1},
+		{"IPv6 does not contain sibling", containsFn, "2001:db8::/48", "2001:db8:1::/48", false, -1},
+		{"whole IPv6 space", containsFn, "::/0", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", true, -1},
+		{"IPv4 never contains IPv6", containsFn, "0.0.0.0/0", "::/128", false, -1},
+		{"IPv6 never contains IPv4", containsFn, "::/0", "10.0.0.0/8", false, -1},
+		{"IPv4-mapped IPv6 is IPv6", containsFn, "::ffff:0:0/96", "10.0.0.1", false, -1},
+		{"contains bad outer", containsFn, "10.0.0.0/33", "10.0.0.0/24", false, 0},
+		{"contains bad inner", containsFn, "10.0.0.0/16", "bogus", false, 1},
+
+		{"overlaps when equal", overlapsFn, "10.0.0.0/24", "10.0.0.0/24", true, -1},
+		{"overlaps when contained", overlapsFn, "10.0.0.0/24", "10.0.0.128/25", true, -1},
+		{"overlaps either order", overlapsFn, "10.0.0.128/25", "10.0.0.0/16", true, -1},
+		{"adjacent do not overlap", overlapsFn, "10.0.0.0/25", "10.0.0.128/25", false, -1},
+		{"IPv6 overlaps", overlapsFn, "2001:db8::/32", "2001:db8:ffff::/48", true, -1},
+		{"IPv6 adjacent", overlapsFn, "2001:db8::/33", "2001:db8:8000::/33", false, -1},
+		{"families never overlap", overlapsFn, "0.0.0.0/0", "::/0", false, -1},
+		{"overlaps needs a prefix", overlapsFn, "10.0.0.0/24", "10.0.0.1", false, 1},
+
+		{"subnet of supernet", subnetFn, "10.0.1.0/24", "10.0.0.0/16", true, -1},
+		{"subnet of itself", subnetFn, "2001:db8::/48", "2001:db8::/48", true, -1},
+		{"supernet is not a subnet", subnetFn, "10.0.0.0/16", "10.0.1.0/24", false, -1},
+		{"subnet across families", subnetFn, "::/128", "0.0.0.0/0", false, -1},
+		{"subnet bad argument", subnetFn, "10.0.0.0/24", "", false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runFunction(tt.fn, types.BoolUnknown(), types.StringValue(tt.a), types.StringValue(tt.b))
+			if tt.errArg >= 0 {
+				if err == nil || err.FunctionArgument == nil || *err.FunctionArgument != tt.errArg {
+					t.Fatalf("got error %v, want an error on argument %d", err, tt.errArg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(types.BoolValue(tt.want)) {
+				t.Errorf("got %s, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCIDROverlapPairsFunction(t *testing.T) {
+	ctx := context.Background()
+	pairs := func(list ...string) ([]overlapPairModel, *function.FuncError) {
+		arg, _ := types.ListValueFrom(ctx, types.StringType, list)
+		got, err := runFunction(NewCIDROverlapPairsFunction(), types.ListUnknown(overlapPairType), arg)
+		if err != nil {
+			return nil, err
+		}
+		var out []overlapPairModel
+		if diags := got.(types.List).ElementsAs(ctx, &out, false); diags.HasError() {
+			t.Fatal(diags)
+		}
+		return out, nil
+	}
+	tests := []struct {
+		name string
+		list []string
+		want [][2]string
+	}{
+		{"empty list", nil, nil},
+		{"single entry", []string{"10.0.0.0/8"}, nil},
+		{"disjoint", []string{"10.0.0.0/24", "10.0.1.0/24", "2001:db8::/32"}, nil},
+		{"duplicates", []string{"10.0.0.0/24", "10.0.0.0/24"}, [][2]string{{"10.0.0.0/24", "10.0.0.0/24"}}},
+		{
+			"every pair in list order",
+			[]string{"10.0.1.0/24", "10.0.0.0/16", "192.168.0.0/16", "10.0.1.128/25"},
+			[][2]string{{"10.0.1.0/24", "10.0.0.0/16"}, {"10.0.1.0/24", "10.0.1.128/25"}, {"10.0.0.0/16", "10.0.1.128/25"}},
+		},
+		{
+			"mixed families",
+			[]string{"0.0.0.0/0", "::/0", "2001:DB8::/32", "10.0.0.0/8"},
+			[][2]string{{"0.0.0.0/0", "10.0.0.0/8"}, {"::/0", "2001:DB8::/32"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pairs(tt.list...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d pairs %v, want %v", len(got), got, tt.want)
+			}
+			for i, w := range tt.want {
+				if got[i].A.ValueString() != w[0] || got[i].B.ValueString() != w[1] {
+					t.Errorf("pair %d: got (%s, %s), want (%s, %s)", i, got[i].A, got[i].B, w[0], w[1])
+				}
+			}
+		})
+	}
+
+	if _, err := pairs("10.0.0.0/24", "10.0.0.0/99"); err == nil || err.FunctionArgument == nil || *err.FunctionArgument != 0 {
+		t.Errorf("invalid element: got error %v, want an error on argument 0", err)
+	}
+}
+
+func stringList(t *testing.T, list ...string) types.List {
+	t.Helper()
+	v, diags := types.ListValueFrom(context.Background(), types.StringType, append([]string{}, list...))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return v
+}
+
+func TestNextFreeCIDRFunction(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent string
+		used   []string
+		bits   int64
+		want   string
+		errArg int64 // -1: no error, -2: error not tied to an argument
+	}{
+		{"empty parent", "10.0.0.0/16", nil, 24, "10.0.0.0/24", -1},
+		{"skips used", "10.0.0.0/16", []string{"10.0.0.0/24", "10.0.1.0/25"}, 24, "10.0.2.0/24", -1},
+		{"fills earlier gap", "10.0.0.0/16", []string{"10.0.1.0/24"}, 24, "10.0.0.0/24", -1},
+		{"aligned after a small prefix", "10.0.0.0/24", []string{"10.0.0.0/28"}, 26, "10.0.0.64/26", -1},
+		{"ignores other family and ranges outside", "10.0.0.0/24", []string{"::/0", "10.1.0.0/16"}, 25, "10.0.0.0/25", -1},
+		{"whole parent", "10.0.0.0/24", nil, 24, "10.0.0.0/24", -1},
+		{"IPv6", "2001:db8::/32", []string{"2001:db8::/48"}, 48, "2001:db8:1::/48", -1},
+		{"IPv6 /64 in /56", "2001:db8:0:ff00::/56", []string{"2001:db8:0:ff00::/64"}, 64, "2001:db8:0:ff01::/64", -1},
+		{"exhausted", "10.0.0.0/24", []string{"10.0.0.0/25", "10.0.0.128/26"}, 25, "", -2},
+		{"larger than parent", "10.0.0.0/24", nil, 16, "", 2},
+		{"longer than the family", "10.0.0.0/24", nil, 33, "", 2},
+		{"bad used entry", "10.0.0.0/24", []string{"10.0.0.0/24", "x"}, 25, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runFunction(NewNextFreeCIDRFunction(), types.StringUnknown(),
+				types.StringValue(tt.parent), stringList(t, tt.used...), types.Int64Value(tt.bits))
+			switch {
+			case tt.errArg == -1 && err != nil:
+				t.Fatal(err)
+			case tt.errArg == -2 && (err == nil || err.FunctionArgument != nil):
+				t.Fatalf("got error %v, want a function error", err)
+			case tt.errArg >= 0 && (err == nil || err.FunctionArgument == nil || *err.FunctionArgument != tt.errArg):
+				t.Fatalf("got error %v, want an error on argument %d", err, tt.errArg)
+			}
+			if tt.errArg == -1 && !got.Equal(types.StringValue(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCarveFunction(t *testing.T) {
+	ctx := context.Background()
+	carve := func(parent string, prefixes map[string]int64) (map[string]string, *function.FuncError) {
+		arg, _ := types.MapValueFrom(ctx, types.Int64Type, prefixes)
+		got, err := runFunction(NewCarveFunction(), types.MapUnknown(types.StringType), types.StringValue(parent), arg)
+		if err != nil {
+			return nil, err
+		}
+		out := map[string]string{}
+		if diags := got.(types.Map).ElementsAs(ctx, &out, false); diags.HasError() {
+			t.Fatal(diags)
+		}
+		return out, nil
+	}
+
+	got, err := carve("10.0.0.0/16", map[string]int64{"public-a": 24, "public-b": 24, "private-a": 20, "private-b": 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"private-a": "10.0.0.0/20", "private-b": "10.0.16.0/20", "public-a": "10.0.32.0/24", "public-b": "10.0.33.0/24"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got, err = carve("2001:db8::/48", map[string]int64{"a": 64, "b": 56})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"b": "2001:db8::/56", "a": "2001:db8:0:100::/64"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IPv6: got %v, want %v", got, want)
+	}
+
+	if got, err := carve("10.0.0.0/24", map[string]int64{}); err != nil || len(got) != 0 {
+		t.Errorf("empty map: got %v, %v", got, err)
+	}
+	if _, err := carve("10.0.0.0/24", map[string]int64{"a": 25, "b": 25, "c": 25}); err == nil || err.FunctionArgument != nil {
+		t.Errorf("overfull parent: got error %v, want a function error", err)
+	}
+	if _, err := carve("10.0.0.0/24", map[string]int64{"a": 16}); err == nil || err.FunctionArgument == nil || *err.FunctionArgument != 1 {
+		t.Errorf("prefix larger than parent: got error %v, want an error on argument 1", err)
+	}
+}
+
+func TestFreeRangesFunction(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		parent string
+		used   []string
+		want   []string
+	}{
+		{"empty parent", "10.0.0.0/24", nil, []string{"10.0.0.0/24"}},
+		{"full parent", "10.0.0.0/24", []string{"10.0.0.0/24"}, []string{}},
+		{"hole", "10.0.0.0/24", []string{"10.0.0.64/26"}, []string{"10.0.0.0/26", "10.0.0.128/25"}},
+		{"host bits in used", "10.0.0.0/24", []string{"10.0.0.1/25"}, []string{"10.0.0.128/25"}},
+		{"IPv6", "2001:db8::/47", []string{"2001:db8::/48"}, []string{"2001:db8:1::/48"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runFunction(NewFreeRangesFunction(), types.ListUnknown(types.StringType), types.StringValue(tt.parent), stringList(t, tt.used...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var list []string
+			if diags := got.(types.List).ElementsAs(ctx, &list, false); diags.HasError() {
+				t.Fatal(diags)
+			}
+			if got.IsNull() || !reflect.DeepEqual(append([]string{}, list...), tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddressCountFunction(t *testing.T) {
+	for cidr, want := range map[string]string{
+		"10.0.0.0/16":     "65536",
+		"10.0.0.1/32":     "1",
+		"0.0.0.0/0":       "4294967296",
+		"2001:db8::/32":   "79228162514264337593543950336",
+		"::/0":            "340282366920938463463374607431768211456",
+		"2001:db8::1/128": "1",
+	} {
+		got, err := runFunction(NewAddressCountFunction(), types.NumberUnknown(), types.StringValue(cidr))
+		if err != nil {
+			t.Fatalf("%s: %v", cidr, err)
+		}
+		if s := got.(types.Number).ValueBigFloat().Text('f', 0); s != want {
+			t.Errorf("%s: got %s, want %s", cidr, s, want)
+		}
+	}
+	if _, err := runFunction(NewAddressCountFunction(), types.NumberUnknown(), types.StringValue("10.0.0.0")); err == nil {
+		t.Error("expected an error for an address without a prefix length")
+	}
+}
+
+func TestUtilizationPercentFunction(t *testing.T) {
+	tests := []struct {
+		used, total string
+		want        string // %g of the result, or empty for an error
+	}{
+		{"0", "256", "0"},
+		{"128", "256", "50"},
+		{"256", "256", "100"},
+		{"1", "3", "33.33333333"},
+		{" 64 ", "256", "25"},
+		{"18446744073709551616", "340282366920938463463374607431768211456", "5.421010862e-18"},
+		{"340282366920938463463374607431768211456", "340282366920938463463374607431768211456", "100"},
+		{"1", "0", ""},
+		{"-1", "256", ""},
+		{"1.5", "256", ""},
+		{"", "256", ""},
+	}
+	for _, tt := range tests {
+		got, err := runFunction(NewUtilizationPercentFunction(), types.NumberUnknown(), types.StringValue(tt.used), types.StringValue(tt.total))
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("(%q, %q): expected error, got %s", tt.used, tt.total, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("(%q, %q): %v", tt.used, tt.total, err)
+			continue
+		}
+		if s := got.(types.Number).ValueBigFloat().Text('g', 10); s != tt.want {
+			t.Errorf("(%q, %q): got %s, want %s", tt.used, tt.total, s, tt.want)
+		}
+	}
+}
+
+func TestIPAddFunction(t *testing.T) {
+	number := func(s string) types.Number {
+		f, _, err := new(big.Float).SetPrec(512).Parse(s, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.NumberValue(f)
+	}
+	tests := []struct {
+		ip   string
+		n    string
+		want string // empty for an error
+	}{
+		{"10.0.0.1", "1", "10.0.0.2"},
+		{"10.0.0.255", "1", "10.0.1.0"},
+		{"10.0.1.0", "-1", "10.0.0.255"},
+		{"0.0.0.0", "4294967295", "255.255.255.255"},
+		{"0.0.0.0", "4294967296", ""},
+		{"10.0.0.0", "-167772161", ""},
+		{"10.0.0.1", "0.5", ""},
+		{"2001:db8::", "18446744073709551616", "2001:db8:0:1::"},
+		{"::", "340282366920938463463374607431768211455", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"2001:db8::", "-1", "2001:db7:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"10.0.0.0/24", "1", ""},
+	}
+	for _, tt := range tests {
+		got, err := runFunction(NewIPAddFunction(), types.StringUnknown(), types.StringValue(tt.ip), number(tt.n))
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ip_add(%s, %s): expected error, got %s", tt.ip, tt.n, got)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(types.StringValue(tt.want)) {
+			t.Errorf("ip_add(%s, %s) = %s, %v; want %s", tt.ip, tt.n, got, err, tt.want)
+		}
+	}
+}
+
+func TestIPRangeToCIDRsFunction(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		start, end string
+		want       []string // nil for an error
+	}{
+		{"10.0.0.0", "10.0.0.255", []string{"10.0.0.0/24"}},
+		{"10.0.0.1", "10.0.0.6", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"192.168.1.7", "192.168.1.7", []string{"192.168.1.7/32"}},
+		{"2001:db8::", "2001:db8::ffff", []string{"2001:db8::/112"}},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", []string{"::/0"}},
+		{"10.0.0.6", "10.0.0.1", nil},
+		{"10.0.0.1", "::1", nil},
+		{"10.0.0.0/24", "10.0.0.255", nil},
+	}
+	for _, tt := range tests {
+		got, err := runFunction(NewIPRangeToCIDRsFunction(), types.ListUnknown(types.StringType), types.StringValue(tt.start), types.StringValue(tt.end))
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("(%s, %s): expected error, got %s", tt.start, tt.end, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("(%s, %s): %v", tt.start, tt.end, err)
+			continue
+		}
+		var list []string
+		got.(types.List).ElementsAs(ctx, &list, false)
+		if !reflect.DeepEqual(list, tt.want) {
+			t.Errorf("(%s, %s): got %v, want %v", tt.start, tt.end, list, tt.want)
+		}
+	}
+}
+
+func TestCIDRSummarizeFunction(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		in   []string
+		want []string
+	}{
+		{"empty", nil, []string{}},
+		{"adjacent", []string{"10.0.1.0/24", "10.0.0.0/24"}, []string{"10.0.0.0/23"}},
+		{"contained", []string{"10.0.0.0/16", "10.0.5.0/24"}, []string{"10.0.0.0/16"}},
+		{"not alignable", []string{"10.0.1.0/24", "10.0.2.0/24"}, []string{"10.0.1.0/24", "10.0.2.0/24"}},
+		{"host bits", []string{"10.0.0.1/25", "10.0.0.128/25"}, []string{"10.0.0.0/24"}},
+		{"mixed families", []string{"2001:db8:1::/48", "10.0.0.0/8", "2001:db8::/48"}, []string{"10.0.0.0/8", "2001:db8::/47"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runFunction(NewCIDRSummarizeFunction(), types.ListUnknown(types.StringType), stringList(t, tt.in...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var list []string
+			got.(types.List).ElementsAs(ctx, &list, false)
+			if !reflect.DeepEqual(append([]string{}, list...), tt.want) {
+				t.Errorf("got %v, want %v", list, tt.want)
+			}
+		})
+	}
+	if _, err := runFunction(NewCIDRSummarizeFunction(), types.ListUnknown(types.StringType), stringList(t, "10.0.0.0/24", "nope")); err == nil {
+		t.Error("expected an error for an invalid entry")
+	}
+}
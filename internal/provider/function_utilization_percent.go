@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &UtilizationPercentFunction{}
+
+func NewUtilizationPercentFunction() function.Function {
+	return &UtilizationPercentFunction{}
+}
+
+type UtilizationPercentFunction struct{}
+
+func (f *UtilizationPercentFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "utilization_percent"
+}
+
+func (f *UtilizationPercentFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Used addresses as a percentage of the total",
+		MarkdownDescription: "Returns `used` / `total` × 100. Both are decimal integer strings of any size, such as the `used_ips` and `total_ips` attributes of `ipam_block`, so IPv6 counts beyond 64 bits work. Numbers are accepted too.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "used", MarkdownDescription: "Number of used addresses, e.g. `ipam_block.vpc.used_ips`."},
+			function.StringParameter{Name: "total", MarkdownDescription: "Total number of addresses, e.g. `ipam_block.vpc.total_ips`. Must be greater than zero."},
+		},
+		Return: function.NumberReturn{},
+	}
+}
+
+func (f *UtilizationPercentFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var usedArg, totalArg string
+	resp.Error = req.Arguments.Get(ctx, &usedArg, &totalArg)
+	if resp.Error != nil {
+		return
+	}
+	used, err := countArg(0, usedArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	total, err := countArg(1, totalArg)
+	if err != nil {
+		resp.Error = err
+		return
+	}
+	if total.Sign() == 0 {
+		resp.Error = function.NewArgumentFuncError(1, "total must be greater than zero")
+		return
+	}
+	ratio := new(big.Rat).SetFrac(new(big.Int).Mul(used, big.NewInt(100)), total)
+	resp.Error = resp.Result.Set(ctx, new(big.Float).SetRat(ratio))
+}
+
+// countArg parses argument i as a non-negative decimal integer of any size.
+func countArg(i int64, s string) (*big.Int, *function.FuncError) {
+	n, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok || n.Sign() < 0 {
+		return nil, function.NewArgumentFuncError(i, fmt.Sprintf("%q is not a non-negative integer", s))
+	}
+	return n, nil
+}
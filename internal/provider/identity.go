@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Resource identities hold only attributes that never change for the life of the remote object:
+// the UUID, plus the parent for pools (environment_id) and allocations (block_id), which both
+// force replacement when changed.
+
+// idIdentityModel is the identity of resources identified by their UUID alone.
+type idIdentityModel struct {
+	Id types.String `tfsdk:"id"`
+}
+
+type poolIdentityModel struct {
+	Id            types.String `tfsdk:"id"`
+	EnvironmentId types.String `tfsdk:"environment_id"`
+}
+
+type allocationIdentityModel struct {
+	Id      types.String `tfsdk:"id"`
+	BlockId types.String `tfsdk:"block_id"`
+}
+
+// identityIDAttribute is the id attribute shared by every identity schema.
+func identityIDAttribute(what string) identityschema.StringAttribute {
+	return identityschema.StringAttribute{
+		RequiredForImport: true,
+		Description:       what + " UUID.",
+	}
+}
+
+func idIdentitySchema(what string) identityschema.Schema {
+	return identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"id": identityIDAttribute(what),
+		},
+	}
+}
+
+// setIdentity stores the identity in a CRUD response. Terraform versions without identity
+// support leave the response identity nil, in which case there is nothing to set.
+func setIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity, val any, diags *diag.Diagnostics) {
+	if identity == nil {
+		return
+	}
+	diags.Append(identity.Set(ctx, val)...)
+}
@@ -0,0 +1,253 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// importCandidate is one object matching a human-friendly import ID.
+type importCandidate struct {
+	ID    string
+	Label string
+}
+
+// uniqueMatch returns the ID of the single candidate, or an error naming every candidate when
+// the import ID (or data source lookup key) is ambiguous.
+func uniqueMatch(kind, importID string, candidates []importCandidate) (string, error) {
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("no %s matches %q", kind, importID)
+	case 1:
+		return candidates[0].ID, nil
+	}
+	lines := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", c.Label, c.ID))
+	}
+	sort.Strings(lines)
+	return "", fmt.Errorf("%q matches %d %ss; use the UUID of one of them instead:\n%s", importID, len(candidates), kind, strings.Join(lines, "\n"))
+}
+
+// splitImportID splits a two-part "parent/name" import ID.
+func splitImportID(importID, format string) (string, string, error) {
+	parent, name, ok := strings.Cut(importID, "/")
+	if !ok || parent == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("expected a UUID or %s, got %q", format, importID)
+	}
+	return parent, name, nil
+}
+
+// sameCIDR reports whether two CIDR strings denote the same network.
+func sameCIDR(a string, b netip.Prefix) bool {
+	p, err := netip.ParsePrefix(a)
+	return err == nil && p.Masked() == b
+}
+
+// importResolver maps a human-friendly import ID to the object's UUID.
+type importResolver func(ctx context.Context, api *client.Client, importID string) (string, error)
+
+// importByKey imports a resource by UUID, or resolves a human-friendly ID with resolve first.
+// An import block with an identity instead of an ID imports by the identity's id.
+func importByKey(ctx context.Context, typeName string, api *client.Client, req resource.ImportStateRequest, resp *resource.ImportStateResponse, resolve importResolver) {
+	if req.ID == "" && req.Identity != nil {
+		resource.ImportStatePassthroughWithIdentity(ctx, path.Root("id"), path.Root("id"), req, resp)
+		return
+	}
+	id := strings.TrimSpace(req.ID)
+	if _, err := uuid.ParseUUID(id); err != nil {
+		ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+		defer cancel()
+		if id, err = resolve(ctx, api, id); err != nil {
+			resp.Diagnostics.AddError("Cannot import "+typeName, err.Error())
+			return
+		}
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
+
+// resolveEnvironmentImport resolves "env-name".
+func resolveEnvironmentImport(ctx context.Context, api *client.Client, importID string) (string, error) {
+	envs, err := api.ListAllEnvironments(ctx, importID)
+	if err != nil {
+		return "", err
+	}
+	var found []importCandidate
+	for _, e := range envs {
+		if e.Name == importID {
+			found = append(found, importCandidate{ID: e.Id, Label: e.Name})
+		}
+	}
+	return uniqueMatch("environment", importID, found)
+}
+
+// resolveEnvironmentPools resolves the comma-separated pool names of an "env/pool-a,pool-b"
+// environment import ID to pool UUIDs, in the order given.
+func resolveEnvironmentPools(ctx context.Context, api *client.Client, envID, poolNames string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+	pools, err := api.ListPools(ctx, envID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, name := range strings.Split(poolNames, ",") {
+		name = strings.TrimSpace(name)
+		var found []importCandidate
+		for _, p := range pools.Pools {
+			if p.Name == name {
+				found = append(found, importCandidate{ID: p.ID, Label: fmt.Sprintf("%s %s", p.Name, p.CIDR)})
+			}
+		}
+		id, err := uniqueMatch("pool", name, found)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// resolveAllocationSetMembers resolves the comma-separated member names of an
+// "block-name/member-a,member-b" allocation set import ID to a name => allocation UUID map.
+func resolveAllocationSetMembers(ctx context.Context, api *client.Client, blockName, names string) (map[string]string, error) {
+	block, err := blockByName(ctx, api, blockName)
+	if err != nil {
+		return nil, err
+	}
+	allocs, err := api.ListAllAllocations(ctx, "", "", block.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]string{}
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		var found []importCandidate
+		for _, a := range allocs {
+			if a.Name == name {
+				found = append(found, importCandidate{ID: a.Id, Label: fmt.Sprintf("%s/%s %s", blockName, a.Name, a.CIDR)})
+			}
+		}
+		id, err := uniqueMatch("allocation", name, found)
+		if err != nil {
+			return nil, err
+		}
+		ids[name] = strings.ToLower(id)
+	}
+	return ids, nil
+}
+
+// resolvePoolImport resolves "env-name/pool-name".
+func resolvePoolImport(ctx context.Context, api *client.Client, importID string) (string, error) {
+	envName, poolName, err := splitImportID(importID, "env-name/pool-name")
+	if err != nil {
+		return "", err
+	}
+	envID, err := resolveEnvironmentImport(ctx, api, envName)
+	if err != nil {
+		return "", err
+	}
+	pools, err := api.ListPools(ctx, envID)
+	if err != nil {
+		return "", err
+	}
+	var found []importCandidate
+	for _, p := range pools.Pools {
+		if p.Name == poolName {
+			found = append(found, importCandidate{ID: p.ID, Label: fmt.Sprintf("%s/%s %s", envName, p.Name, p.CIDR)})
+		}
+	}
+	return uniqueMatch("pool", importID, found)
+}
+
+// resolveBlockImport resolves "env-name/block-name" or a block CIDR.
+func resolveBlockImport(ctx context.Context, api *client.Client, importID string) (string, error) {
+	var found []importCandidate
+	if prefix, err := netip.ParsePrefix(importID); err == nil {
+		blocks, err := api.ListAllBlocks(ctx, "", "", false)
+		if err != nil {
+			return "", err
+		}
+		for _, b := range blocks {
+			if sameCIDR(b.CIDR, prefix.Masked()) {
+				found = append(found, importCandidate{ID: b.ID, Label: fmt.Sprintf("%s %s", b.Name, b.CIDR)})
+			}
+		}
+		return uniqueMatch("block", importID, found)
+	}
+	envName, blockName, err := splitImportID(importID, "env-name/block-name or a CIDR")
+	if err != nil {
+		return "", err
+	}
+	envID, err := resolveEnvironmentImport(ctx, api, envName)
+	if err != nil {
+		return "", err
+	}
+	blocks, err := api.ListAllBlocks(ctx, blockName, envID, false)
+	if err != nil {
+		return "", err
+	}
+	for _, b := range blocks {
+		if b.Name == blockName && strings.EqualFold(b.EnvironmentID, envID) {
+			found = append(found, importCandidate{ID: b.ID, Label: fmt.Sprintf("%s/%s %s", envName, b.Name, b.CIDR)})
+		}
+	}
+	return uniqueMatch("block", importID, found)
+}
+
+// resolveAllocationImport resolves "block-name/alloc-name" or an allocation CIDR.
+func resolveAllocationImport(ctx context.Context, api *client.Client, importID string) (string, error) {
+	var found []importCandidate
+	if prefix, err := netip.ParsePrefix(importID); err == nil {
+		allocs, err := api.ListAllAllocations(ctx, "", "", "")
+		if err != nil {
+			return "", err
+		}
+		for _, a := range allocs {
+			if sameCIDR(a.CIDR, prefix.Masked()) {
+				found = append(found, importCandidate{ID: a.Id, Label: fmt.Sprintf("%s/%s %s", a.BlockName, a.Name, a.CIDR)})
+			}
+		}
+		return uniqueMatch("allocation", importID, found)
+	}
+	blockName, allocName, err := splitImportID(importID, "block-name/alloc-name or a CIDR")
+	if err != nil {
+		return "", err
+	}
+	allocs, err := api.ListAllAllocations(ctx, allocName, blockName, "")
+	if err != nil {
+		return "", err
+	}
+	for _, a := range allocs {
+		if a.Name == allocName && a.BlockName == blockName {
+			found = append(found, importCandidate{ID: a.Id, Label: fmt.Sprintf("%s/%s %s", a.BlockName, a.Name, a.CIDR)})
+		}
+	}
+	return uniqueMatch("allocation", importID, found)
+}
+
+// resolveReservedBlockImport resolves a reserved block CIDR.
+func resolveReservedBlockImport(ctx context.Context, api *client.Client, importID string) (string, error) {
+	prefix, err := netip.ParsePrefix(importID)
+	if err != nil {
+		return "", fmt.Errorf("expected a UUID or a CIDR, got %q", importID)
+	}
+	reserved, err := api.ListReservedBlocks(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	var found []importCandidate
+	for _, rb := range reserved.ReservedBlocks {
+		if sameCIDR(rb.CIDR, prefix.Masked()) {
+			found = append(found, importCandidate{ID: rb.ID, Label: fmt.Sprintf("%s %s", rb.Name, rb.CIDR)})
+		}
+	}
+	return uniqueMatch("reserved block", importID, found)
+}
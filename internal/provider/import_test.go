@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestUniqueMatch(t *testing.T) {
+	if _, err := uniqueMatch("block", "prod/app", nil); err == nil {
+		t.Error("expected error for no candidates")
+	}
+	id, err := uniqueMatch("block", "prod/app", []importCandidate{{ID: "a", Label: "prod/app"}})
+	if err != nil || id != "a" {
+		t.Errorf("single candidate: got %q, %v", id, err)
+	}
+	_, err = uniqueMatch("allocation", "10.0.0.0/24", []importCandidate{
+		{ID: "b", Label: "blk-2/web 10.0.0.0/24"},
+		{ID: "a", Label: "blk-1/web 10.0.0.0/24"},
+	})
+	if err == nil {
+		t.Fatal("expected error for ambiguous match")
+	}
+	for _, want := range []string{"matches 2 allocations", "- blk-1/web 10.0.0.0/24 (a)", "- blk-2/web 10.0.0.0/24 (b)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestSplitImportID(t *testing.T) {
+	parent, name, err := splitImportID("prod/app", "env-name/block-name")
+	if err != nil || parent != "prod" || name != "app" {
+		t.Errorf("got %q, %q, %v", parent, name, err)
+	}
+	for _, id := range []string{"prod", "/app", "prod/", "a/b/c"} {
+		if _, _, err := splitImportID(id, "env-name/block-name"); err == nil {
+			t.Errorf("splitImportID(%q): expected error", id)
+		}
+	}
+}
+
+func TestResolveEnvironmentPools(t *testing.T) {
+	api := newFakeAPI(t, prodFixture())
+	ids, err := resolveEnvironmentPools(context.Background(), api, "env-1", "prod-extra, prod-pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"pool-2", "pool-1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	for _, names := range []string{"missing", "prod-pool,", ""} {
+		if _, err := resolveEnvironmentPools(context.Background(), api, "env-1", names); err == nil {
+			t.Errorf("resolveEnvironmentPools(%q): expected error", names)
+		}
+	}
+}
+
+func TestResolveAllocationSetMembers(t *testing.T) {
+	api := newFakeAPI(t, prodFixture())
+	ids, err := resolveAllocationSetMembers(context.Background(), api, "app", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"web": "a1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	if _, err := resolveAllocationSetMembers(context.Background(), api, "app", "web,api"); err == nil {
+		t.Error("expected error for a member that does not exist")
+	}
+	if _, err := resolveAllocationSetMembers(context.Background(), api, "missing", "web"); err == nil {
+		t.Error("expected error for a block that does not exist")
+	}
+}
+
+func TestOwnedPools(t *testing.T) {
+	ctx := context.Background()
+	pools := []client.PoolResponse{{ID: "pool-1"}, {ID: "pool-2"}, {ID: "pool-3"}}
+	ids := func(pools []client.PoolResponse) []string {
+		out := make([]string, 0, len(pools))
+		for _, p := range pools {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+	list := func(ids ...string) types.List {
+		v, _ := types.ListValueFrom(ctx, types.StringType, ids)
+		return v
+	}
+	tests := []struct {
+		name    string
+		poolIDs types.List
+		want    []string
+	}{
+		{"import adopts every pool", types.ListNull(types.StringType), []string{"pool-1", "pool-2", "pool-3"}},
+		{"pool_ids order", list("pool-3", "pool-1"), []string{"pool-3", "pool-1"}},
+		{"deleted pool dropped", list("pool-1", "pool-9"), []string{"pool-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owned, diags := ownedPools(ctx, pools, tt.poolIDs)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if got := ids(owned); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &AllocationListResource{}
+
+func NewAllocationListResource() list.ListResource {
+	return &AllocationListResource{}
+}
+
+type AllocationListResource struct {
+	listClient
+}
+
+type AllocationListModel struct {
+	Name      types.String `tfsdk:"name"`
+	BlockName types.String `tfsdk:"block_name"`
+	BlockId   types.String `tfsdk:"block_id"`
+}
+
+func (r *AllocationListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_allocation"
+}
+
+func (r *AllocationListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists IPAM allocations for `terraform query`.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Filter by name (as the API's `name` filter).",
+			},
+			"block_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only list allocations in the block with this name.",
+			},
+			"block_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only list allocations in the block with this UUID.",
+			},
+		},
+	}
+}
+
+func (r *AllocationListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var filter AllocationListModel
+	if diags := req.Config.Get(ctx, &filter); diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	allocs, err := r.api.ListAllAllocations(ctx, filter.Name.ValueString(), filter.BlockName.ValueString(), filter.BlockId.ValueString())
+	if err != nil {
+		listError(stream, err)
+		return
+	}
+	streamResults(req, stream, allocs, func(a client.AllocationResponse) list.ListResult {
+		blockID := types.StringNull()
+		if a.BlockID != "" {
+			blockID = types.StringValue(a.BlockID)
+		}
+		identity := allocationIdentityModel{Id: types.StringValue(a.Id), BlockId: blockID}
+		return listResult(ctx, req, a.BlockName+"/"+a.Name+" "+a.CIDR, identity, map[string]attr.Value{
+			"id":                  types.StringValue(a.Id),
+			"name":                types.StringValue(a.Name),
+			"block_name":          types.StringValue(a.BlockName),
+			"block_id":            blockID,
+			"cidr":                cidrStringValue(a.CIDR),
+			"predict_cidr":        types.BoolValue(false),
+			"deletion_protection": types.BoolValue(false),
+		})
+	})
+}
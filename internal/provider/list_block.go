@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &BlockListResource{}
+
+func NewBlockListResource() list.ListResource {
+	return &BlockListResource{}
+}
+
+type BlockListResource struct {
+	listClient
+}
+
+type BlockListModel struct {
+	Name          types.String `tfsdk:"name"`
+	EnvironmentId types.String `tfsdk:"environment_id"`
+	OrphanedOnly  types.Bool   `tfsdk:"orphaned_only"`
+}
+
+func (r *BlockListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_block"
+}
+
+func (r *BlockListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists IPAM network blocks for `terraform query`.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Filter by name (as the API's `name` filter).",
+			},
+			"environment_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only list blocks of this environment.",
+			},
+			"orphaned_only": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only list blocks that are not in an environment.",
+			},
+		},
+	}
+}
+
+func (r *BlockListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var filter BlockListModel
+	if diags := req.Config.Get(ctx, &filter); diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	blocks, err := r.api.ListAllBlocks(ctx, filter.Name.ValueString(), filter.EnvironmentId.ValueString(), filter.OrphanedOnly.ValueBool())
+	if err != nil {
+		listError(stream, err)
+		return
+	}
+	streamResults(req, stream, blocks, func(b client.BlockResponse) list.ListResult {
+		attrs := map[string]attr.Value{
+			"id":                  types.StringValue(b.ID),
+			"name":                types.StringValue(b.Name),
+			"cidr":                cidrStringValue(b.CIDR),
+			"total_ips":           types.StringValue(b.TotalIPs),
+			"used_ips":            types.StringValue(b.UsedIPs),
+			"available_ips":       types.StringValue(b.Available),
+			"environment_id":      types.StringNull(),
+			"pool_id":             types.StringNull(),
+			"deletion_protection": types.BoolValue(false),
+			"force_destroy":       types.BoolValue(false),
+		}
+		if b.EnvironmentID != "" {
+			attrs["environment_id"] = types.StringValue(b.EnvironmentID)
+		}
+		if b.PoolID != nil && *b.PoolID != "" {
+			attrs["pool_id"] = types.StringValue(*b.PoolID)
+		}
+		return listResult(ctx, req, b.Name+" "+b.CIDR, idIdentityModel{Id: types.StringValue(b.ID)}, attrs)
+	})
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &EnvironmentListResource{}
+
+func NewEnvironmentListResource() list.ListResource {
+	return &EnvironmentListResource{}
+}
+
+type EnvironmentListResource struct {
+	listClient
+}
+
+type EnvironmentListModel struct {
+	Name types.String `tfsdk:"name"`
+}
+
+func (r *EnvironmentListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environment"
+}
+
+func (r *EnvironmentListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists IPAM environments for `terraform query`.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Filter by name (as the API's `name` filter).",
+			},
+		},
+	}
+}
+
+func (r *EnvironmentListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var filter EnvironmentListModel
+	if diags := req.Config.Get(ctx, &filter); diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	envs, err := r.api.ListAllEnvironments(ctx, filter.Name.ValueString())
+	if err != nil {
+		listError(stream, err)
+		return
+	}
+	streamResults(req, stream, envs, func(e client.EnvResponse) list.ListResult {
+		attrs := map[string]attr.Value{
+			"id":                  types.StringValue(e.Id),
+			"name":                types.StringValue(e.Name),
+			"deletion_protection": types.BoolValue(false),
+			"force_destroy":       types.BoolValue(false),
+		}
+		if req.IncludeResource {
+			if pools, err := r.api.ListPools(ctx, e.Id); err == nil {
+				attrs["pools"], attrs["pool_ids"] = environmentPools(pools.Pools)
+			}
+		}
+		return listResult(ctx, req, e.Name, idIdentityModel{Id: types.StringValue(e.Id)}, attrs)
+	})
+}
@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The list data sources share a filter block and a sort_by attribute. Their top-level
+// attributes (name, environment_id, ...) are sent to the API; the filter block is applied to
+// the results in the provider, because the API has no equivalent parameters. Each data source
+// offers the filter attributes that make sense for its objects.
+
+const (
+	filterNameRegex       = "name_regex"
+	filterCidrContains    = "cidr_contains"
+	filterCidrWithin      = "cidr_within"
+	filterIPVersion       = "ip_version"
+	filterPoolID          = "pool_id"
+	filterMinAvailableIPs = "min_available_ips"
+)
+
+const (
+	sortByName        = "name"
+	sortByCIDR        = "cidr"
+	sortByUtilization = "utilization"
+)
+
+var listFilterDescriptions = map[string]string{
+	filterNameRegex:       "Only objects whose name matches this regular expression (RE2 syntax, unanchored: use `^...$` for a full match).",
+	filterCidrContains:    "Only objects whose CIDR contains this IP address or CIDR.",
+	filterCidrWithin:      "Only objects whose CIDR is inside this CIDR (or equal to it).",
+	filterIPVersion:       "Only IPv4 (`4`) or IPv6 (`6`) objects.",
+	filterPoolID:          "Only objects in this pool.",
+	filterMinAvailableIPs: "Only blocks with at least this many available IPs (decimal string; may exceed 64 bits).",
+}
+
+// listFilterAttribute returns the optional filter block of a list data source, with the given
+// filter attributes.
+func listFilterAttribute(fields ...string) schema.SingleNestedAttribute {
+	attrs := make(map[string]schema.Attribute, len(fields))
+	for _, f := range fields {
+		if f == filterIPVersion {
+			attrs[f] = schema.Int64Attribute{Optional: true, MarkdownDescription: listFilterDescriptions[f]}
+			continue
+		}
+		attrs[f] = schema.StringAttribute{Optional: true, MarkdownDescription: listFilterDescriptions[f]}
+	}
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: "Filters applied by the provider to the listed objects. All set filters must match.",
+		Attributes:          attrs,
+	}
+}
+
+// sortByAttribute returns the sort_by attribute of a list data source accepting the given keys.
+func sortByAttribute(keys ...string) schema.StringAttribute {
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = "`" + k + "`"
+	}
+	choices := quoted[0]
+	if n := len(quoted); n > 1 {
+		choices = strings.Join(quoted[:n-1], ", ") + " or " + quoted[n-1]
+	}
+	description := "Sort the results by " + choices + "."
+	for _, k := range keys {
+		switch k {
+		case sortByCIDR:
+			description += " `cidr` sorts numerically: IPv4 before IPv6, then by address, then shorter prefixes first."
+		case sortByUtilization:
+			description += " `utilization` puts the most utilized first."
+		}
+	}
+	return schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: description + " Without it, the API order is kept.",
+	}
+}
+
+// listFilter is a parsed filter block. Zero fields match everything.
+type listFilter struct {
+	nameRegex    *regexp.Regexp
+	cidrContains netip.Prefix
+	cidrWithin   netip.Prefix
+	ipVersion    int64
+	poolID       string
+	minAvailable *big.Int
+}
+
+// parseListFilter parses the filter block. Unknown values are skipped, so it can run in
+// ValidateConfig as well as in Read.
+func parseListFilter(obj types.Object) (listFilter, diag.Diagnostics) {
+	var f listFilter
+	var diags diag.Diagnostics
+	if obj.IsNull() || obj.IsUnknown() {
+		return f, diags
+	}
+	attrs := obj.Attributes()
+	str := func(name string) (string, bool) {
+		v, ok := attrs[name].(types.String)
+		if !ok || v.IsNull() || v.IsUnknown() {
+			return "", false
+		}
+		return v.ValueString(), true
+	}
+	at := func(name string) path.Path { return path.Root("filter").AtName(name) }
+
+	if s, ok := str(filterNameRegex); ok {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			diags.AddAttributeError(at(filterNameRegex), "Invalid name_regex", err.Error())
+		}
+		f.nameRegex = re
+	}
+	if s, ok := str(filterCidrContains); ok {
+		p, err := parseAddressOrCIDR(s)
+		if err != nil {
+			diags.AddAttributeError(at(filterCidrContains), "Invalid cidr_contains", err.Error())
+		}
+		f.cidrContains = p
+	}
+	if s, ok := str(filterCidrWithin); ok {
+		p, err := netip.ParsePrefix(strings.TrimSpace(s))
+		if err != nil {
+			diags.AddAttributeError(at(filterCidrWithin), "Invalid cidr_within", fmt.Sprintf("%q is not a valid CIDR prefix: %s", s, err))
+		}
+		f.cidrWithin = p.Masked()
+	}
+	if v, ok := attrs[filterIPVersion].(types.Int64); ok && !v.IsNull() && !v.IsUnknown() {
+		if v.ValueInt64() != 4 && v.ValueInt64() != 6 {
+			diags.AddAttributeError(at(filterIPVersion), "Invalid ip_version", "ip_version must be 4 or 6.")
+		}
+		f.ipVersion = v.ValueInt64()
+	}
+	if s, ok := str(filterPoolID); ok {
+		f.poolID = s
+	}
+	if s, ok := str(filterMinAvailableIPs); ok {
+		n, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+		if !ok || n.Sign() < 0 {
+			diags.AddAttributeError(at(filterMinAvailableIPs), "Invalid min_available_ips", fmt.Sprintf("%q is not a non-negative integer.", s))
+		}
+		f.minAvailable = n
+	}
+	return f, diags
+}
+
+// parseAddressOrCIDR parses an IP address as a single-address prefix, or a CIDR.
+func parseAddressOrCIDR(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if a, err := netip.ParseAddr(s); err == nil {
+		return netip.PrefixFrom(a.WithZone(""), a.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%q is not a valid IP address or CIDR", s)
+	}
+	return p.Masked(), nil
+}
+
+// matchName reports whether name passes the name_regex filter.
+func (f listFilter) matchName(name string) bool {
+	return f.nameRegex == nil || f.nameRegex.MatchString(name)
+}
+
+// matchCIDR reports whether cidr passes the CIDR and IP version filters. Unparsable CIDRs only
+// pass when none of those filters is set.
+func (f listFilter) matchCIDR(cidr string) bool {
+	if !f.cidrContains.IsValid() && !f.cidrWithin.IsValid() && f.ipVersion == 0 {
+		return true
+	}
+	p, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return false
+	}
+	p = p.Masked()
+	switch {
+	case f.ipVersion == 4 && !p.Addr().Is4(), f.ipVersion == 6 && !p.Addr().Is6():
+		return false
+	case f.cidrContains.IsValid() && !contains(p, f.cidrContains):
+		return false
+	case f.cidrWithin.IsValid() && !contains(f.cidrWithin, p):
+		return false
+	}
+	return true
+}
+
+// matchAvailable reports whether an available IP count passes the min_available_ips filter.
+func (f listFilter) matchAvailable(available string) bool {
+	if f.minAvailable == nil {
+		return true
+	}
+	n, ok := new(big.Int).SetString(available, 10)
+	return ok && n.Cmp(f.minAvailable) >= 0
+}
+
+// validateSortBy checks sort_by against the keys a data source accepts.
+func validateSortBy(diags *diag.Diagnostics, v types.String, keys ...string) {
+	if v.IsNull() || v.IsUnknown() {
+		return
+	}
+	for _, k := range keys {
+		if v.ValueString() == k {
+			return
+		}
+	}
+	diags.AddAttributeError(path.Root("sort_by"), "Invalid sort_by", fmt.Sprintf("sort_by must be one of %s, got %q.", strings.Join(keys, ", "), v.ValueString()))
+}
+
+// sortListed sorts items in place by the key named by sortBy using the given accessors; an
+// empty sortBy keeps the order. utilization may be nil when the objects have none.
+func sortListed[T any](items []T, sortBy string, name, cidr func(T) string, utilization func(T) float64) {
+	var less func(a, b T) bool
+	switch sortBy {
+	case sortByName:
+		less = func(a, b T) bool { return name(a) < name(b) }
+	case sortByCIDR:
+		less = func(a, b T) bool { return compareCIDR(cidr(a), cidr(b)) < 0 }
+	case sortByUtilization:
+		if utilization == nil {
+			return
+		}
+		less = func(a, b T) bool { return utilization(a) > utilization(b) }
+	default:
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool { return less(items[i], items[j]) })
+}
+
+// compareCIDR orders CIDRs numerically: IPv4 before IPv6, then by address, then shorter
+// prefixes first. Unparsable CIDRs sort last, by their text.
+func compareCIDR(a, b string) int {
+	pa, errA := netip.ParsePrefix(a)
+	pb, errB := netip.ParsePrefix(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return 1
+	case errB != nil:
+		return -1
+	}
+	pa, pb = pa.Masked(), pb.Masked()
+	if pa.Addr().Is4() != pb.Addr().Is4() {
+		if pa.Addr().Is4() {
+			return -1
+		}
+		return 1
+	}
+	if c := pa.Addr().Compare(pb.Addr()); c != 0 {
+		return c
+	}
+	return pa.Bits() - pb.Bits()
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func filterObject(t *testing.T, values map[string]attr.Value) types.Object {
+	t.Helper()
+	attrTypes := map[string]attr.Type{}
+	for name, v := range values {
+		attrTypes[name] = v.Type(nil)
+	}
+	obj, diags := types.ObjectValue(attrTypes, values)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return obj
+}
+
+func TestListFilter(t *testing.T) {
+	f, diags := parseListFilter(filterObject(t, map[string]attr.Value{
+		filterNameRegex:       types.StringValue("^prod-"),
+		filterCidrWithin:      types.StringValue("10.0.0.0/8"),
+		filterIPVersion:       types.Int64Value(4),
+		filterMinAvailableIPs: types.StringValue("100"),
+	}))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	for _, tt := range []struct {
+		name, cidr, available string
+		want                  bool
+	}{
+		{"prod-app", "10.1.0.0/16", "65536", true},
+		{"staging-app", "10.1.0.0/16", "65536", false},
+		{"prod-app", "172.16.0.0/16", "65536", false},
+		{"prod-app", "10.0.0.0/8", "65536", true},
+		{"prod-app", "10.1.0.0/16", "99", false},
+		{"prod-app", "not-a-cidr", "65536", false},
+	} {
+		got := f.matchName(tt.name) && f.matchCIDR(tt.cidr) && f.matchAvailable(tt.available)
+		if got != tt.want {
+			t.Errorf("%s %s %s: got %v, want %v", tt.name, tt.cidr, tt.available, got, tt.want)
+		}
+	}
+
+	f, diags = parseListFilter(filterObject(t, map[string]attr.Value{
+		filterCidrContains: types.StringValue("2001:db8::1"),
+		filterIPVersion:    types.Int64Null(),
+	}))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if !f.matchCIDR("2001:db8::/32") || f.matchCIDR("2001:db9::/32") || f.matchCIDR("10.0.0.0/8") {
+		t.Error("cidr_contains 2001:db8::1 matched the wrong CIDRs")
+	}
+
+	// A null filter block matches everything, including unparsable CIDRs.
+	f, diags = parseListFilter(types.ObjectNull(map[string]attr.Type{filterNameRegex: types.StringType}))
+	if diags.HasError() || !f.matchName("x") || !f.matchCIDR("junk") || !f.matchAvailable("") {
+		t.Error("null filter did not match everything")
+	}
+
+	for field, v := range map[string]attr.Value{
+		filterNameRegex:       types.StringValue("("),
+		filterCidrContains:    types.StringValue("10.0.0.0/33"),
+		filterCidrWithin:      types.StringValue("10.0.0.1"),
+		filterIPVersion:       types.Int64Value(5),
+		filterMinAvailableIPs: types.StringValue("-1"),
+	} {
+		_, diags := parseListFilter(filterObject(t, map[string]attr.Value{field: v}))
+		if !diags.HasError() || !strings.Contains(diags[0].Summary(), field) {
+			t.Errorf("%s = %s: got %v, want an error naming the attribute", field, v, diags)
+		}
+	}
+}
+
+func TestSortListed(t *testing.T) {
+	type item struct {
+		name, cidr string
+		util       float64
+	}
+	items := []item{
+		{"c", "10.0.0.0/24", 10},
+		{"a", "2001:db8::/32", 90},
+		{"b", "9.255.0.0/16", 50},
+		{"d", "10.0.0.0/16", 0},
+		{"e", "junk", 20},
+	}
+	name := func(i item) string { return i.name }
+	cidr := func(i item) string { return i.cidr }
+	util := func(i item) float64 { return i.util }
+	order := func() string {
+		var b strings.Builder
+		for _, i := range items {
+			b.WriteString(i.name)
+		}
+		return b.String()
+	}
+	for _, tt := range []struct {
+		by, want string
+	}{
+		{"", "cabde"},
+		{sortByName, "abcde"},
+		{sortByCIDR, "bdcae"},
+		{sortByUtilization, "abecd"},
+	} {
+		sortListed(items, tt.by, name, cidr, util)
+		if got := order(); got != tt.want {
+			t.Errorf("sort_by %q: got %s, want %s", tt.by, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &PoolListResource{}
+
+func NewPoolListResource() list.ListResource {
+	return &PoolListResource{}
+}
+
+type PoolListResource struct {
+	listClient
+}
+
+type PoolListModel struct {
+	EnvironmentId types.String `tfsdk:"environment_id"`
+}
+
+func (r *PoolListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pool"
+}
+
+func (r *PoolListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists IPAM pools for `terraform query`.",
+		Attributes: map[string]schema.Attribute{
+			"environment_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only list pools of this environment. Omit to list the pools of every environment.",
+			},
+		},
+	}
+}
+
+func (r *PoolListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var filter PoolListModel
+	if diags := req.Config.Get(ctx, &filter); diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	envIDs := []string{filter.EnvironmentId.ValueString()}
+	if envIDs[0] == "" {
+		envs, err := r.api.ListAllEnvironments(ctx, "")
+		if err != nil {
+			listError(stream, err)
+			return
+		}
+		envIDs = envIDs[:0]
+		for _, e := range envs {
+			envIDs = append(envIDs, e.Id)
+		}
+	}
+	var pools []client.PoolResponse
+	for _, id := range envIDs {
+		out, err := r.api.ListPools(ctx, id)
+		if err != nil {
+			listError(stream, err)
+			return
+		}
+		pools = append(pools, out.Pools...)
+	}
+	streamResults(req, stream, pools, func(p client.PoolResponse) list.ListResult {
+		identity := poolIdentityModel{Id: types.StringValue(p.ID), EnvironmentId: types.StringValue(p.EnvironmentID)}
+		return listResult(ctx, req, p.Name, identity, map[string]attr.Value{
+			"id":                  types.StringValue(p.ID),
+			"environment_id":      types.StringValue(p.EnvironmentID),
+			"name":                types.StringValue(p.Name),
+			"cidr":                cidrStringValue(p.CIDR),
+			"deletion_protection": types.BoolValue(false),
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &ReservedBlockListResource{}
+
+func NewReservedBlockListResource() list.ListResource {
+	return &ReservedBlockListResource{}
+}
+
+type ReservedBlockListResource struct {
+	listClient
+}
+
+type ReservedBlockListModel struct {
+	OrganizationId types.String `tfsdk:"organization_id"`
+}
+
+func (r *ReservedBlockListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_reserved_block"
+}
+
+func (r *ReservedBlockListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists reserved blocks for `terraform query` (admin only).",
+		Attributes: map[string]schema.Attribute{
+			"organization_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only list reserved blocks of this organization.",
+			},
+		},
+	}
+}
+
+func (r *ReservedBlockListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var filter ReservedBlockListModel
+	if diags := req.Config.Get(ctx, &filter); diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	out, err := r.api.ListReservedBlocks(ctx, filter.OrganizationId.ValueString())
+	if err != nil {
+		listError(stream, err)
+		return
+	}
+	streamResults(req, stream, out.ReservedBlocks, func(rb client.ReservedBlockResponse) list.ListResult {
+		return listResult(ctx, req, rb.Name+" "+rb.CIDR, idIdentityModel{Id: types.StringValue(rb.ID)}, map[string]attr.Value{
+			"id":                  types.StringValue(rb.ID),
+			"name":                types.StringValue(rb.Name),
+			"cidr":                cidrStringValue(rb.CIDR),
+			"reason":              types.StringValue(rb.Reason),
+			"created_at":          types.StringValue(rb.CreatedAt),
+			"deletion_protection": types.BoolValue(false),
+		})
+	})
+}
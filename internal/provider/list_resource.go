@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// listClient is embedded by the list resources for their shared Configure.
+type listClient struct {
+	api *client.Client
+}
+
+func (l *listClient) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	api, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		return
+	}
+	l.api = api
+}
+
+// listResult builds one result from the identity and, when Terraform asks for it, the resource
+// attributes. Attributes not in attrs (such as timeouts) stay null.
+func listResult(ctx context.Context, req list.ListRequest, displayName string, identity any, attrs map[string]attr.Value) list.ListResult {
+	result := req.NewListResult(ctx)
+	result.DisplayName = displayName
+	result.Diagnostics.Append(result.Identity.Set(ctx, identity)...)
+	if req.IncludeResource {
+		for name, v := range attrs {
+			result.Diagnostics.Append(result.Resource.SetAttribute(ctx, path.Root(name), v)...)
+		}
+	}
+	return result
+}
+
+// streamResults pushes a result per item, stopping at the request's limit.
+func streamResults[T any](req list.ListRequest, stream *list.ListResultsStream, items []T, build func(T) list.ListResult) {
+	stream.Results = func(push func(list.ListResult) bool) {
+		for i, item := range items {
+			if req.Limit > 0 && int64(i) >= req.Limit {
+				return
+			}
+			if !push(build(item)) {
+				return
+			}
+		}
+	}
+}
+
+// listError ends a list stream with an API error.
+func listError(stream *list.ListResultsStream, err error) {
+	stream.Results = list.ListResultsStreamDiagnostics(diag.Diagnostics{apiError(err)})
+}
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// listFixture is two environments with a pool each, a block in prod and an orphaned block, and
+// two allocations in the prod block, one without a block_id. Listing reserved blocks fails, as it
+// does for a token without admin rights.
+func listFixture() *fakeAPI {
+	poolID := "pool-1"
+	return &fakeAPI{
+		Environments: []client.EnvResponse{{Id: "env-1", Name: "prod"}, {Id: "env-2", Name: "staging"}},
+		Pools: []client.PoolResponse{
+			{ID: "pool-1", EnvironmentID: "env-1", Name: "prod-pool", CIDR: "10.0.0.0/16"},
+			{ID: "pool-2", EnvironmentID: "env-2", Name: "staging-pool", CIDR: "10.1.0.0/16"},
+		},
+		Blocks: []client.BlockResponse{
+			{ID: "b1", Name: "app", CIDR: "10.0.0.0/24", TotalIPs: "256", UsedIPs: "64", Available: "192", EnvironmentID: "env-1", PoolID: &poolID},
+			{ID: "b2", Name: "orphan", CIDR: "172.16.0.0/24", TotalIPs: "256", UsedIPs: "0", Available: "256"},
+		},
+		Allocations: []client.AllocationResponse{
+			{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"},
+			{Id: "a2", Name: "legacy", BlockName: "app", CIDR: "10.0.0.64/26"},
+		},
+	}
+}
+
+// runList configures a list resource with api, runs it with the given config attributes (the
+// others are null) and returns every result pushed to the stream.
+func runList(t *testing.T, lr list.ListResource, r resource.Resource, api *client.Client, config map[string]tftypes.Value, includeResource bool, limit int64) []list.ListResult {
+	t.Helper()
+	ctx := context.Background()
+	var configureResp resource.ConfigureResponse
+	lr.(list.ListResourceWithConfigure).Configure(ctx, resource.ConfigureRequest{ProviderData: api}, &configureResp)
+	if configureResp.Diagnostics.HasError() {
+		t.Fatal(configureResp.Diagnostics)
+	}
+
+	var configSchema list.ListResourceSchemaResponse
+	lr.ListResourceConfigSchema(ctx, list.ListResourceSchemaRequest{}, &configSchema)
+	configType := configSchema.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	vals := map[string]tftypes.Value{}
+	for name, typ := range configType.AttributeTypes {
+		vals[name] = tftypes.NewValue(typ, nil)
+		if v, ok := config[name]; ok {
+			vals[name] = v
+		}
+	}
+	var resourceSchema resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resourceSchema)
+	var identitySchema resource.IdentitySchemaResponse
+	r.(resource.ResourceWithIdentity).IdentitySchema(ctx, resource.IdentitySchemaRequest{}, &identitySchema)
+
+	req := list.ListRequest{
+		Config:                 tfsdk.Config{Schema: configSchema.Schema, Raw: tftypes.NewValue(configType, vals)},
+		IncludeResource:        includeResource,
+		Limit:                  limit,
+		ResourceSchema:         resourceSchema.Schema,
+		ResourceIdentitySchema: identitySchema.IdentitySchema,
+	}
+	var stream list.ListResultsStream
+	lr.List(ctx, req, &stream)
+	var results []list.ListResult
+	for result := range stream.Results {
+		results = append(results, result)
+	}
+	return results
+}
+
+// resultString reads a string attribute of a list result's resource.
+func resultString(t *testing.T, result list.ListResult, name string) types.String {
+	t.Helper()
+	var v types.String
+	if diags := result.Resource.GetAttribute(context.Background(), path.Root(name), &v); diags.HasError() {
+		t.Fatal(diags)
+	}
+	return v
+}
+
+func TestEnvironmentList(t *testing.T) {
+	ctx := context.Background()
+	f := listFixture()
+	results := runList(t, NewEnvironmentListResource(), NewEnvironmentResource(), newFakeAPI(t, f),
+		map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, "prod")}, true, 0)
+	if got := f.query("/api/environments").Get("name"); got != "prod" {
+		t.Errorf("name filter sent as %q, want prod", got)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	for _, r := range results {
+		if r.Diagnostics.HasError() {
+			t.Fatal(r.Diagnostics)
+		}
+	}
+	if results[0].DisplayName != "prod" {
+		t.Errorf("display name = %q, want prod", results[0].DisplayName)
+	}
+	var identity idIdentityModel
+	if diags := results[0].Identity.Get(ctx, &identity); diags.HasError() || identity.Id.ValueString() != "env-1" {
+		t.Errorf("identity = %v, %v; want env-1", identity, diags)
+	}
+	var poolIDs []string
+	if diags := results[0].Resource.GetAttribute(ctx, path.Root("pool_ids"), &poolIDs); diags.HasError() || len(poolIDs) != 1 || poolIDs[0] != "pool-1" {
+		t.Errorf("prod pool_ids = %v, %v; want [pool-1]", poolIDs, diags)
+	}
+}
+
+func TestPoolList(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, listFixture())
+
+	// Without environment_id the pools of every environment are listed.
+	results := runList(t, NewPoolListResource(), NewPoolResource(), api, nil, false, 0)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	var identity poolIdentityModel
+	if diags := results[1].Identity.Get(ctx, &identity); diags.HasError() || identity.Id.ValueString() != "pool-2" || identity.EnvironmentId.ValueString() != "env-2" {
+		t.Errorf("identity = %v, %v; want pool-2 in env-2", identity, diags)
+	}
+	if !results[0].Resource.Raw.IsNull() {
+		t.Error("resource set although Terraform did not ask for it")
+	}
+
+	results = runList(t, NewPoolListResource(), NewPoolResource(), api,
+		map[string]tftypes.Value{"environment_id": tftypes.NewValue(tftypes.String, "env-1")}, true, 0)
+	if len(results) != 1 || results[0].DisplayName != "prod-pool" {
+		t.Fatalf("got %v, want prod-pool only", results)
+	}
+	var cidr cidrValue
+	if diags := results[0].Resource.GetAttribute(ctx, path.Root("cidr"), &cidr); diags.HasError() || cidr.ValueString() != "10.0.0.0/16" {
+		t.Errorf("cidr = %s, %v; want 10.0.0.0/16", cidr, diags)
+	}
+}
+
+func TestBlockList(t *testing.T) {
+	f := listFixture()
+	api := newFakeAPI(t, f)
+	results := runList(t, NewBlockListResource(), NewBlockResource(), api, map[string]tftypes.Value{
+		"name":           tftypes.NewValue(tftypes.String, "app"),
+		"environment_id": tftypes.NewValue(tftypes.String, "env-1"),
+	}, true, 0)
+	q := f.query("/api/blocks")
+	if q.Get("name") != "app" || q.Get("environment_id") != "env-1" {
+		t.Errorf("filters sent as %v, want name=app and environment_id=env-1", q)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].DisplayName != "app 10.0.0.0/24" {
+		t.Errorf("display name = %q", results[0].DisplayName)
+	}
+	if got := resultString(t, results[0], "pool_id"); got.ValueString() != "pool-1" {
+		t.Errorf("app pool_id = %s, want pool-1", got)
+	}
+
+	results = runList(t, NewBlockListResource(), NewBlockResource(), api, nil, true, 0)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	// An orphaned block has neither environment nor pool, so both are null rather than "".
+	for _, name := range []string{"environment_id", "pool_id"} {
+		if got := resultString(t, results[1], name); !got.IsNull() {
+			t.Errorf("orphan %s = %s, want null", name, got)
+		}
+	}
+
+	// The limit stops the stream early.
+	if results := runList(t, NewBlockListResource(), NewBlockResource(), api, nil, false, 1); len(results) != 1 {
+		t.Errorf("limit 1: got %d results", len(results))
+	}
+}
+
+func TestAllocationList(t *testing.T) {
+	ctx := context.Background()
+	f := listFixture()
+	results := runList(t, NewAllocationListResource(), NewAllocationResource(), newFakeAPI(t, f),
+		map[string]tftypes.Value{"block_name": tftypes.NewValue(tftypes.String, "app")}, true, 0)
+	if got := f.query("/api/allocations").Get("block_name"); got != "app" {
+		t.Errorf("block_name filter sent as %q, want app", got)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].DisplayName != "app/web 10.0.0.0/26" {
+		t.Errorf("display name = %q", results[0].DisplayName)
+	}
+	var identity allocationIdentityModel
+	if diags := results[0].Identity.Get(ctx, &identity); diags.HasError() || identity.BlockId.ValueString() != "b1" {
+		t.Errorf("identity = %v, %v; want block_id b1", identity, diags)
+	}
+	// The API did not report a block_id for the second allocation.
+	if diags := results[1].Identity.Get(ctx, &identity); diags.HasError() || !identity.BlockId.IsNull() {
+		t.Errorf("identity = %v, %v; want a null block_id", identity, diags)
+	}
+	if got := resultString(t, results[1], "block_id"); !got.IsNull() {
+		t.Errorf("block_id = %s, want null", got)
+	}
+}
+
+func TestReservedBlockListError(t *testing.T) {
+	results := runList(t, NewReservedBlockListResource(), NewReservedBlockResource(), newFakeAPI(t, listFixture()), nil, true, 0)
+	if len(results) != 1 || !results[0].Diagnostics.HasError() {
+		t.Fatalf("got %v, want a single result with an error", results)
+	}
+	if got := results[0].Diagnostics.Errors()[0].Summary(); got != "API error" {
+		t.Errorf("summary = %q, want API error", got)
+	}
+}
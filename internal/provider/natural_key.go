@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Single-object data sources accept natural keys instead of a UUID. A key is resolved through
+// the list endpoints: every attribute set must match exactly (CIDRs by the network they name),
+// and exactly one object may match. Errors come from uniqueMatch, like imports by name.
+
+// validateLookupKey checks that a data source sets either id or some of its key attributes,
+// described by keyAttrs, but not both.
+func validateLookupKey(diags *diag.Diagnostics, id types.String, keyAttrs string, keys ...types.String) {
+	set := false
+	for _, k := range keys {
+		if !k.IsNull() {
+			set = true
+		}
+	}
+	switch {
+	case id.IsNull() && !set:
+		diags.AddError("Invalid configuration", "Set `id` or "+keyAttrs+".")
+	case !id.IsNull() && set:
+		diags.AddError("Invalid configuration", "Set either `id` or "+keyAttrs+", not both.")
+	}
+}
+
+// lookupKey describes the attributes set on a data source, for error messages.
+type lookupKey []string
+
+func (k *lookupKey) add(attr string, v types.String) {
+	if !v.IsNull() && !v.IsUnknown() && v.ValueString() != "" {
+		*k = append(*k, attr+"="+v.ValueString())
+	}
+}
+
+func (k lookupKey) String() string {
+	return strings.Join(k, " ")
+}
+
+// lookupCIDR parses an optional CIDR key attribute.
+func lookupCIDR(v types.String) (netip.Prefix, bool, error) {
+	if v.IsNull() || v.ValueString() == "" {
+		return netip.Prefix{}, false, nil
+	}
+	p, err := netip.ParsePrefix(strings.TrimSpace(v.ValueString()))
+	if err != nil {
+		return netip.Prefix{}, false, fmt.Errorf("cidr %q is not a valid CIDR prefix: %w", v.ValueString(), err)
+	}
+	return p.Masked(), true, nil
+}
+
+// lookupEnvironmentID returns the environment a lookup is scoped to: environment_id when set,
+// else the environment named environment_name, else "" (all environments).
+func lookupEnvironmentID(ctx context.Context, api *client.Client, id, name types.String) (string, error) {
+	if id.ValueString() != "" {
+		return id.ValueString(), nil
+	}
+	if name.ValueString() != "" {
+		return resolveEnvironmentImport(ctx, api, name.ValueString())
+	}
+	return "", nil
+}
+
+// resolvePoolKey finds the pool matching name and/or cidr, optionally within one environment.
+func resolvePoolKey(ctx context.Context, api *client.Client, envID, envName, name, cidr types.String) (string, error) {
+	var key lookupKey
+	key.add("environment_id", envID)
+	key.add("environment_name", envName)
+	key.add("name", name)
+	key.add("cidr", cidr)
+	prefix, byCIDR, err := lookupCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	env, err := lookupEnvironmentID(ctx, api, envID, envName)
+	if err != nil {
+		return "", err
+	}
+	envIDs := []string{env}
+	if env == "" {
+		envs, err := api.ListAllEnvironments(ctx, "")
+		if err != nil {
+			return "", err
+		}
+		envIDs = envIDs[:0]
+		for _, e := range envs {
+			envIDs = append(envIDs, e.Id)
+		}
+	}
+	var found []importCandidate
+	for _, id := range envIDs {
+		pools, err := api.ListPools(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		for _, p := range pools.Pools {
+			if (name.ValueString() == "" || p.Name == name.ValueString()) && (!byCIDR || sameCIDR(p.CIDR, prefix)) {
+				found = append(found, importCandidate{ID: p.ID, Label: fmt.Sprintf("%s %s", p.Name, p.CIDR)})
+			}
+		}
+	}
+	return uniqueMatch("pool", key.String(), found)
+}
+
+// resolveBlockKey finds the block matching name and/or cidr, optionally within one environment.
+func resolveBlockKey(ctx context.Context, api *client.Client, envID, envName, name, cidr types.String) (string, error) {
+	var key lookupKey
+	key.add("environment_id", envID)
+	key.add("environment_name", envName)
+	key.add("name", name)
+	key.add("cidr", cidr)
+	prefix, byCIDR, err := lookupCIDR(cidr)
+	if err != nil {
+		return "", err
+	}
+	env, err := lookupEnvironmentID(ctx, api, envID, envName)
+	if err != nil {
+		return "", err
+	}
+	blocks, err := api.ListAllBlocks(ctx, name.ValueString(), env, false)
+	if err != nil {
+		return "", err
+	}
+	var found []importCandidate
+	for _, b := range blocks {
+		if (name.ValueString() == "" || b.Name == name.ValueString()) &&
+			(env == "" || strings.EqualFold(b.EnvironmentID, env)) &&
+			(!byCIDR || sameCIDR(b.CIDR, prefix)) {
+			found = append(found, importCandidate{ID: b.ID, Label: fmt.Sprintf("%s %s", b.Name, b.CIDR)})
+		}
+	}
+	return uniqueMatch("block", key.String(), found)
+}
+
+// resolveReservedBlockKey finds the reserved block with the given id, or matching name and/or
+// cidr. There is no endpoint to get one reserved block, so it always lists them.
+func resolveReservedBlockKey(ctx context.Context, api *client.Client, id, name, cidr types.String) (*client.ReservedBlockResponse, error) {
+	var key lookupKey
+	key.add("id", id)
+	key.add("name", name)
+	key.add("cidr", cidr)
+	prefix, byCIDR, err := lookupCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	list, err := api.ListReservedBlocks(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var found []importCandidate
+	byID := map[string]*client.ReservedBlockResponse{}
+	for i, rb := range list.ReservedBlocks {
+		if id.ValueString() != "" {
+			if strings.EqualFold(rb.ID, id.ValueString()) {
+				return &list.ReservedBlocks[i], nil
+			}
+			continue
+		}
+		if (name.ValueString() == "" || rb.Name == name.ValueString()) && (!byCIDR || sameCIDR(rb.CIDR, prefix)) {
+			found = append(found, importCandidate{ID: rb.ID, Label: fmt.Sprintf("%s %s", rb.Name, rb.CIDR)})
+			byID[rb.ID] = &list.ReservedBlocks[i]
+		}
+	}
+	match, err := uniqueMatch("reserved block", key.String(), found)
+	if err != nil {
+		return nil, err
+	}
+	return byID[match], nil
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestResolveNaturalKeys(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, prodFixture())
+	s := types.StringValue
+	null := types.StringNull()
+	tests := []struct {
+		name    string
+		resolve func() (string, error)
+		want    string // ID, or a substring of the error
+		wantErr bool
+	}{
+		{
+			name:    "pool by name",
+			resolve: func() (string, error) { return resolvePoolKey(ctx, api, null, null, s("prod-pool"), null) },
+			want:    "pool-1",
+		},
+		{
+			name:    "pool by environment name and CIDR with host bits",
+			resolve: func() (string, error) { return resolvePoolKey(ctx, api, null, s("prod"), null, s("10.1.0.1/16")) },
+			want:    "pool-2",
+		},
+		{
+			name: "pool name and CIDR must both match",
+			resolve: func() (string, error) {
+				return resolvePoolKey(ctx, api, s("env-1"), null, s("prod-pool"), s("10.1.0.0/16"))
+			},
+			want:    `no pool matches "environment_id=env-1 name=prod-pool cidr=10.1.0.0/16"`,
+			wantErr: true,
+		},
+		{
+			name:    "pool ambiguous",
+			resolve: func() (string, error) { return resolvePoolKey(ctx, api, s("env-1"), null, null, null) },
+			want:    "matches 2 pools",
+			wantErr: true,
+		},
+		{
+			name:    "block by name",
+			resolve: func() (string, error) { return resolveBlockKey(ctx, api, null, null, s("db"), null) },
+			want:    "b2",
+		},
+		{
+			name:    "block by CIDR in environment",
+			resolve: func() (string, error) { return resolveBlockKey(ctx, api, null, s("prod"), null, s("10.0.0.0/24")) },
+			want:    "b1",
+		},
+		{
+			name:    "block name is matched exactly",
+			resolve: func() (string, error) { return resolveBlockKey(ctx, api, null, null, s("ap"), null) },
+			want:    `no block matches "name=ap"`,
+			wantErr: true,
+		},
+		{
+			name:    "block in unknown environment",
+			resolve: func() (string, error) { return resolveBlockKey(ctx, api, null, s("staging"), s("app"), null) },
+			want:    `no environment matches "staging"`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid CIDR",
+			resolve: func() (string, error) { return resolveBlockKey(ctx, api, null, null, null, s("10.0.0.0/33")) },
+			want:    "not a valid CIDR prefix",
+			wantErr: true,
+		},
+		{
+			name: "reserved block by CIDR",
+			resolve: func() (string, error) {
+				rb, err := resolveReservedBlockKey(ctx, api, null, null, s("10.0.0.192/26"))
+				if err != nil {
+					return "", err
+				}
+				return rb.ID, nil
+			},
+			want: "r1",
+		},
+		{
+			name: "reserved block by unknown ID",
+			resolve: func() (string, error) {
+				_, err := resolveReservedBlockKey(ctx, api, s("r9"), null, null)
+				return "", err
+			},
+			want:    `no reserved block matches "id=r9"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resolve()
+			switch {
+			case tt.wantErr && err == nil:
+				t.Fatalf("got %q, want error containing %q", got, tt.want)
+			case tt.wantErr && !strings.Contains(err.Error(), tt.want):
+				t.Fatalf("error = %q, want it to contain %q", err, tt.want)
+			case !tt.wantErr && err != nil:
+				t.Fatal(err)
+			case !tt.wantErr && got != tt.want:
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
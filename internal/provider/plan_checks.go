@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// The plan checks compare a planned CIDR with what already exists on the server, so address
+// conflicts show up in the plan rather than part way through an apply. They only run when the
+// CIDR (or the parent it must fit in) is new or changed, and only against parents that already
+// exist: a parent created in the same apply is checked by the server instead. Listing reserved
+// blocks is admin only; without access that part of the check is skipped, as in usedInBlock.
+
+// reservedConflicts lists the reserved blocks overlapping prefix.
+func reservedConflicts(ctx context.Context, api *client.Client, prefix netip.Prefix) []string {
+	reserved, err := api.ListReservedBlocks(ctx, "")
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, rb := range reserved.ReservedBlocks {
+		if p, err := netip.ParsePrefix(rb.CIDR); err == nil && p.Overlaps(prefix) {
+			out = append(out, fmt.Sprintf("overlaps reserved block %q (%s)", rb.Name, rb.CIDR))
+		}
+	}
+	return out
+}
+
+// blockConflicts checks a block CIDR against its pool, the other blocks of its environment (the
+// other orphaned blocks when envID is empty) and the reserved blocks. Blocks in different
+// environments may reuse address space. selfID is the block being updated, if any.
+func blockConflicts(ctx context.Context, api *client.Client, prefix netip.Prefix, selfID, envID, poolID string) ([]string, error) {
+	var out []string
+	if poolID != "" {
+		pool, err := api.GetPool(ctx, poolID)
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		if pool != nil {
+			if pp, err := netip.ParsePrefix(pool.CIDR); err == nil && !contains(pp, prefix) {
+				out = append(out, fmt.Sprintf("is outside pool %q (%s)", pool.Name, pool.CIDR))
+			}
+		}
+	}
+	blocks, err := api.ListAllBlocks(ctx, "", envID, envID == "")
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		if strings.EqualFold(b.ID, selfID) || !strings.EqualFold(b.EnvironmentID, envID) {
+			continue
+		}
+		if p, err := netip.ParsePrefix(b.CIDR); err == nil && p.Overlaps(prefix) {
+			out = append(out, fmt.Sprintf("overlaps block %q (%s)", b.Name, b.CIDR))
+		}
+	}
+	return append(out, reservedConflicts(ctx, api, prefix)...), nil
+}
+
+// poolConflicts checks a pool CIDR against the other pools of its environment and, when an
+// existing pool changes, the blocks drawn from it. Reserved ranges commonly sit inside a pool, so
+// they are not conflicts here.
+func poolConflicts(ctx context.Context, api *client.Client, prefix netip.Prefix, selfID, envID string) ([]string, error) {
+	var out []string
+	pools, err := api.ListPools(ctx, envID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, p := range pools.Pools {
+		if strings.EqualFold(p.ID, selfID) || !strings.EqualFold(p.EnvironmentID, envID) {
+			continue
+		}
+		if pp, err := netip.ParsePrefix(p.CIDR); err == nil && pp.Overlaps(prefix) {
+			out = append(out, fmt.Sprintf("overlaps pool %q (%s)", p.Name, p.CIDR))
+		}
+	}
+	if selfID == "" {
+		return out, nil
+	}
+	blocks, err := api.ListAllBlocks(ctx, "", envID, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		if b.PoolID == nil || !strings.EqualFold(*b.PoolID, selfID) {
+			continue
+		}
+		if bp, err := netip.ParsePrefix(b.CIDR); err == nil && !contains(prefix, bp) {
+			out = append(out, fmt.Sprintf("no longer contains block %q (%s)", b.Name, b.CIDR))
+		}
+	}
+	return out, nil
+}
+
+// allocationConflicts checks an allocation CIDR against its block, the block's other allocations
+// and the reserved blocks. selfID is the allocation being changed, if any.
+func allocationConflicts(ctx context.Context, api *client.Client, prefix netip.Prefix, blockName, blockID, selfID string) ([]string, error) {
+	block, err := resolveBlock(ctx, api, blockName, blockID)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []string
+	if bp, err := netip.ParsePrefix(block.CIDR); err == nil && !contains(bp, prefix) {
+		out = append(out, fmt.Sprintf("is outside block %q (%s)", block.Name, block.CIDR))
+	}
+	allocs, err := blockAllocations(ctx, api, block)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range allocs {
+		if strings.EqualFold(a.Id, selfID) {
+			continue
+		}
+		if p, err := netip.ParsePrefix(a.CIDR); err == nil && p.Overlaps(prefix) {
+			out = append(out, fmt.Sprintf("overlaps allocation %q (%s)", a.Name, a.CIDR))
+		}
+	}
+	return append(out, reservedConflicts(ctx, api, prefix)...), nil
+}
+
+// contains reports whether outer contains all of inner.
+func contains(outer, inner netip.Prefix) bool {
+	return outer.Addr().Is4() == inner.Addr().Is4() && outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+func isNotFound(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "not found")
+}
+
+// reportConflicts turns the result of a plan check into diagnostics on the cidr attribute. An
+// API failure only warns: the server still validates the CIDR at apply time.
+func reportConflicts(diags *diag.Diagnostics, typeName string, prefix netip.Prefix, conflicts []string, err error) {
+	if err != nil {
+		diags.AddWarning("Could not check CIDR conflicts", fmt.Sprintf("%s %s was not checked against the IPAM server at plan time: %s", typeName, prefix, err))
+		return
+	}
+	if len(conflicts) == 0 {
+		return
+	}
+	diags.AddAttributeError(path.Root("cidr"), "CIDR conflict",
+		fmt.Sprintf("%s %s:\n- %s", typeName, prefix, strings.Join(conflicts, "\n- ")))
+}
+
+// plannedPrefix returns the planned CIDR as a network prefix, or false when it is not known yet.
+func plannedPrefix(planned cidrValue) (netip.Prefix, bool) {
+	if planned.IsNull() || planned.IsUnknown() {
+		return netip.Prefix{}, false
+	}
+	p, err := netip.ParsePrefix(planned.ValueString())
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return p.Masked(), true
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+)
+
+// prodFixture is environment prod with two pools, blocks app (in prod-pool) and db, allocation
+// web in app and reserved block vpn.
+func prodFixture() *fakeAPI {
+	poolID := "pool-1"
+	return &fakeAPI{
+		Environments: []client.EnvResponse{{Id: "env-1", Name: "prod"}},
+		Pools: []client.PoolResponse{
+			{ID: "pool-1", EnvironmentID: "env-1", Name: "prod-pool", CIDR: "10.0.0.0/16"},
+			{ID: "pool-2", EnvironmentID: "env-1", Name: "prod-extra", CIDR: "10.1.0.0/16"},
+		},
+		Blocks: []client.BlockResponse{
+			{ID: "b1", Name: "app", CIDR: "10.0.0.0/24", EnvironmentID: "env-1", PoolID: &poolID},
+			{ID: "b2", Name: "db", CIDR: "10.0.1.0/24", EnvironmentID: "env-1"},
+		},
+		Allocations: []client.AllocationResponse{{Id: "a1", Name: "web", BlockName: "app", BlockID: "b1", CIDR: "10.0.0.0/26"}},
+		Reserved:    []client.ReservedBlockResponse{{ID: "r1", Name: "vpn", CIDR: "10.0.0.192/26"}},
+	}
+}
+
+func TestPlanConflicts(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, prodFixture())
+	tests := []struct {
+		name  string
+		check func(netip.Prefix) ([]string, error)
+		cidr  string
+		want  []string
+	}{
+		{
+			name:  "block outside pool",
+			check: func(p netip.Prefix) ([]string, error) { return blockConflicts(ctx, api, p, "", "env-1", "pool-1") },
+			cidr:  "10.2.0.0/24",
+			want:  []string{`is outside pool "prod-pool" (10.0.0.0/16)`},
+		},
+		{
+			name:  "block overlaps block",
+			check: func(p netip.Prefix) ([]string, error) { return blockConflicts(ctx, api, p, "", "env-1", "pool-1") },
+			cidr:  "10.0.1.0/25",
+			want:  []string{`overlaps block "db" (10.0.1.0/24)`},
+		},
+		{
+			name:  "block in another environment",
+			check: func(p netip.Prefix) ([]string, error) { return blockConflicts(ctx, api, p, "", "env-2", "") },
+			cidr:  "10.0.1.0/25",
+		},
+		{
+			name:  "orphaned block",
+			check: func(p netip.Prefix) ([]string, error) { return blockConflicts(ctx, api, p, "", "", "") },
+			cidr:  "10.0.1.0/25",
+		},
+		{
+			name:  "block ignores itself",
+			check: func(p netip.Prefix) ([]string, error) { return blockConflicts(ctx, api, p, "b2", "env-1", "") },
+			cidr:  "10.0.1.0/25",
+		},
+		{
+			name:  "allocation overlaps allocation",
+			check: func(p netip.Prefix) ([]string, error) { return allocationConflicts(ctx, api, p, "app", "", "") },
+			cidr:  "10.0.0.32/27",
+			want:  []string{`overlaps allocation "web" (10.0.0.0/26)`},
+		},
+		{
+			name:  "allocation ignores itself",
+			check: func(p netip.Prefix) ([]string, error) { return allocationConflicts(ctx, api, p, "app", "", "a1") },
+			cidr:  "10.0.0.0/25",
+		},
+		{
+			name:  "allocation overlaps reserved block",
+			check: func(p netip.Prefix) ([]string, error) { return allocationConflicts(ctx, api, p, "app", "", "") },
+			cidr:  "10.0.0.192/27",
+			want:  []string{`overlaps reserved block "vpn" (10.0.0.192/26)`},
+		},
+		{
+			name:  "allocation outside block",
+			check: func(p netip.Prefix) ([]string, error) { return allocationConflicts(ctx, api, p, "app", "", "") },
+			cidr:  "10.0.1.0/26",
+			want:  []string{`is outside block "app" (10.0.0.0/24)`},
+		},
+		{
+			name:  "allocation in a block not created yet",
+			check: func(p netip.Prefix) ([]string, error) { return allocationConflicts(ctx, api, p, "new-block", "", "") },
+			cidr:  "10.9.0.0/26",
+		},
+		{
+			name:  "pool overlaps pool",
+			check: func(p netip.Prefix) ([]string, error) { return poolConflicts(ctx, api, p, "", "env-1") },
+			cidr:  "10.1.128.0/17",
+			want:  []string{`overlaps pool "prod-extra" (10.1.0.0/16)`},
+		},
+		{
+			name:  "pool shrinks below its blocks",
+			check: func(p netip.Prefix) ([]string, error) { return poolConflicts(ctx, api, p, "pool-1", "env-1") },
+			cidr:  "10.0.0.0/25",
+			want:  []string{`no longer contains block "app" (10.0.0.0/24)`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.check(netip.MustParsePrefix(tt.cidr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"net/netip"
+	"sync"
+)
+
+// predictionLedger lets an allocation CIDR predicted during plan be predicted again, identically,
+// during apply. Terraform plans every resource once more just before applying it and rejects a
+// final plan whose cidr differs from the one it showed, but by then the allocations applied
+// earlier in the run have changed the block. The ledger records the ranges this provider process
+// has created and deleted in each block, so a prediction sees the block as it was before the run.
+// It also records the ranges planned for new allocations, so two of them planned into the same
+// range fail the plan rather than the apply.
+type predictionLedger struct {
+	mu     sync.Mutex
+	blocks map[string]*blockLedger
+}
+
+type blockLedger struct {
+	created, deleted []netip.Prefix
+	planned          map[string]netip.Prefix // allocation name -> planned CIDR
+}
+
+func newPredictionLedger() *predictionLedger {
+	return &predictionLedger{blocks: map[string]*blockLedger{}}
+}
+
+// block returns the ledger of a block, creating it. l.mu must be held.
+func (l *predictionLedger) block(blockID string) *blockLedger {
+	b, ok := l.blocks[blockID]
+	if !ok {
+		b = &blockLedger{planned: map[string]netip.Prefix{}}
+		l.blocks[blockID] = b
+	}
+	return b
+}
+
+// recordCreate notes that this process created an allocation at p in the block.
+func (l *predictionLedger) recordCreate(blockID string, p netip.Prefix) {
+	if l == nil || blockID == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.block(blockID)
+	b.created = append(b.created, p.Masked())
+}
+
+// recordDelete notes that this process deleted an allocation at p in the block.
+func (l *predictionLedger) recordDelete(blockID string, p netip.Prefix) {
+	if l == nil || blockID == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.block(blockID)
+	b.deleted = append(b.deleted, p.Masked())
+}
+
+// beforeRun turns the prefixes currently used in a block into the prefixes used before this
+// process changed it: ranges it created are dropped and ranges it deleted are added back.
+func (l *predictionLedger) beforeRun(blockID string, used []netip.Prefix) []netip.Prefix {
+	if l == nil {
+		return used
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.blocks[blockID]
+	if !ok {
+		return used
+	}
+	out := make([]netip.Prefix, 0, len(used)+len(b.deleted))
+	for _, p := range used {
+		if !containsPrefix(b.created, p) {
+			out = append(out, p)
+		}
+	}
+	return append(out, b.deleted...)
+}
+
+// plan records p as the CIDR planned for a new allocation in the block. When it overlaps the
+// CIDR planned for another allocation, that allocation's name and CIDR are returned with false.
+func (l *predictionLedger) plan(blockID, name string, p netip.Prefix) (string, netip.Prefix, bool) {
+	if l == nil {
+		return "", netip.Prefix{}, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.block(blockID)
+	for other, op := range b.planned {
+		if other != name && op.Overlaps(p) {
+			return other, op, false
+		}
+	}
+	b.planned[name] = p
+	return "", netip.Prefix{}, true
+}
+
+func containsPrefix(list []netip.Prefix, p netip.Prefix) bool {
+	for _, q := range list {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+)
+
+func TestPlaceInBlockBeforeRun(t *testing.T) {
+	ctx := context.Background()
+	api := newFakeAPI(t, prodFixture())
+	block := &client.BlockResponse{ID: "b1", Name: "app", CIDR: "10.0.0.0/24"}
+	opts := cidr.Options{Strategy: cidr.FirstFit}
+	place := func(ledger *predictionLedger) string {
+		t.Helper()
+		p, err := placeInBlock(ctx, api, block, 26, opts, ledger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.String()
+	}
+
+	ledger := newPredictionLedger()
+	if got := place(ledger); got != "10.0.0.64/26" {
+		t.Errorf("empty ledger: got %s, want 10.0.0.64/26", got)
+	}
+	// An allocation deleted earlier in the run still counts as used.
+	ledger.recordDelete("b1", netip.MustParsePrefix("10.0.0.64/26"))
+	if got := place(ledger); got != "10.0.0.128/26" {
+		t.Errorf("after delete: got %s, want 10.0.0.128/26", got)
+	}
+	// An allocation created earlier in the run did not exist when the plan was made.
+	ledger.recordCreate("b1", netip.MustParsePrefix("10.0.0.0/26"))
+	if got := place(ledger); got != "10.0.0.0/26" {
+		t.Errorf("after create: got %s, want 10.0.0.0/26", got)
+	}
+	// Other blocks are unaffected.
+	ledger.recordCreate("b2", netip.MustParsePrefix("10.0.0.0/26"))
+	if got := place(nil); got != "10.0.0.64/26" {
+		t.Errorf("nil ledger: got %s, want 10.0.0.64/26", got)
+	}
+}
+
+func TestPredictionLedgerPlan(t *testing.T) {
+	ledger := newPredictionLedger()
+	if _, _, ok := ledger.plan("b1", "web", netip.MustParsePrefix("10.0.0.64/26")); !ok {
+		t.Fatal("first plan reported a conflict")
+	}
+	// Planning the same allocation again (as Terraform does before apply) is not a conflict.
+	if _, _, ok := ledger.plan("b1", "web", netip.MustParsePrefix("10.0.0.64/26")); !ok {
+		t.Fatal("replanning the same allocation reported a conflict")
+	}
+	if _, _, ok := ledger.plan("b2", "api", netip.MustParsePrefix("10.0.0.64/26")); !ok {
+		t.Fatal("an allocation in another block reported a conflict")
+	}
+	other, p, ok := ledger.plan("b1", "api", netip.MustParsePrefix("10.0.0.96/27"))
+	if ok || other != "web" || p.String() != "10.0.0.64/26" {
+		t.Errorf("got (%q, %s, %v), want conflict with web 10.0.0.64/26", other, p, ok)
+	}
+}
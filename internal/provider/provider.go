@@ -6,6 +6,8 @@ import (
 
 	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -13,14 +15,25 @@ import (
 )
 
 var _ provider.Provider = &IpamProvider{}
+var _ provider.ProviderWithListResources = &IpamProvider{}
+var _ provider.ProviderWithFunctions = &IpamProvider{}
 
 type IpamProvider struct {
 	version string
 }
 
 type IpamProviderModel struct {
-	Endpoint types.String `tfsdk:"endpoint"`
-	Token    types.String `tfsdk:"token"`
+	Endpoint       types.String `tfsdk:"endpoint"`
+	Token          types.String `tfsdk:"token"`
+	NormalizeCIDRs types.Bool   `tfsdk:"normalize_cidrs"`
+}
+
+// providerData is passed to resources. Data sources and list resources only need the client.
+type providerData struct {
+	api            *client.Client
+	normalizeCIDRs bool
+	predictions    *predictionLedger
+	protections    *protectionLedger
 }
 
 func (p *IpamProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -41,6 +54,10 @@ func (p *IpamProvider) Schema(ctx context.Context, req provider.SchemaRequest, r
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"normalize_cidrs": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, resource CIDRs with host bits set (e.g. `10.0.0.1/24`) are accepted and sent to the API as their network address (`10.0.0.0/24`). When `false` (the default), such CIDRs are rejected at plan time.",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -69,7 +86,8 @@ func (p *IpamProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 	resp.DataSourceData = c
-	resp.ResourceData = c
+	resp.ResourceData = &providerData{api: c, normalizeCIDRs: data.NormalizeCIDRs.ValueBool(), predictions: newPredictionLedger(), protections: newProtectionLedger()}
+	resp.ListResourceData = c
 }
 
 func (p *IpamProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -79,6 +97,17 @@ func (p *IpamProvider) Resources(ctx context.Context) []func() resource.Resource
 		NewReservedBlockResource,
 		NewBlockResource,
 		NewAllocationResource,
+		NewAllocationSetResource,
+	}
+}
+
+func (p *IpamProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewEnvironmentListResource,
+		NewPoolListResource,
+		NewReservedBlockListResource,
+		NewBlockListResource,
+		NewAllocationListResource,
 	}
 }
 
@@ -94,6 +123,28 @@ func (p *IpamProvider) DataSources(ctx context.Context) []func() datasource.Data
 		NewBlocksDataSource,
 		NewAllocationDataSource,
 		NewAllocationsDataSource,
+		NewNextAvailableCIDRDataSource,
+		NewBlockFreeSpaceDataSource,
+		NewPoolFreeSpaceDataSource,
+		NewAddressLookupDataSource,
+		NewEnvironmentTreeDataSource,
+	}
+}
+
+func (p *IpamProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewCIDRContainsFunction,
+		NewCIDROverlapsFunction,
+		NewCIDROverlapPairsFunction,
+		NewCIDRIsSubnetOfFunction,
+		NewNextFreeCIDRFunction,
+		NewCarveFunction,
+		NewFreeRangesFunction,
+		NewAddressCountFunction,
+		NewUtilizationPercentFunction,
+		NewIPAddFunction,
+		NewIPRangeToCIDRsFunction,
+		NewCIDRSummarizeFunction,
 	}
 }
 
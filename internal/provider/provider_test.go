@@ -1,9 +1,12 @@
 package provider
 
 import (
+	"context"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -14,6 +17,30 @@ func TestProvider(t *testing.T) {
 	}
 }
 
+// TestProviderSchema checks that every resource, identity, list resource and data source schema is valid.
+func TestProviderSchema(t *testing.T) {
+	server := providerserver.NewProtocol6(New("test")())()
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+	for _, name := range []string{"ipam_environment", "ipam_pool", "ipam_block", "ipam_allocation", "ipam_reserved_block"} {
+		if _, ok := resp.ListResourceSchemas[name]; !ok {
+			t.Errorf("missing list resource %s", name)
+		}
+	}
+	ids, err := server.GetResourceIdentitySchemas(context.Background(), &tfprotov6.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range ids.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+}
+
 // TestAccProviderConfig runs a minimal config that requires a live IPAM server.
 func TestAccProviderConfig(t *testing.T) {
 	testAccPreCheck(t)
@@ -3,10 +3,16 @@ package provider
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestAccEnvironmentResource(t *testing.T) {
@@ -50,6 +56,12 @@ resource "ipam_environment" "acc" {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "ipam_environment.acc",
+				ImportState:       true,
+				ImportStateId:     "acc-env-updated",
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -121,6 +133,18 @@ resource "ipam_block" "acc" {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "ipam_block.acc",
+				ImportState:       true,
+				ImportStateId:     "acc-block-env/acc-block-renamed",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "ipam_block.acc",
+				ImportState:       true,
+				ImportStateId:     "10.1.100.0/24",
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -197,9 +221,53 @@ resource "ipam_allocation" "acc" {
 					resource.TestCheckResourceAttr("ipam_allocation.acc", "name", "acc-alloc-updated"),
 				),
 			},
+			{
+				// Configuration generated on import sets both block_name and block_id.
+				Config: testAccProviderConfig(endpoint, token) + `
+resource "ipam_environment" "acc" {
+  name = "acc-alloc-env"
+  pools = [
+    { name = "acc-alloc-pool", cidr = "10.2.0.0/16" }
+  ]
+}
+
+data "ipam_pools" "acc" {
+  environment_id = ipam_environment.acc.id
+}
+
+resource "ipam_block" "acc" {
+  name           = "acc-alloc-block"
+  cidr           = "10.2.101.0/24"
+  environment_id = ipam_environment.acc.id
+  pool_id        = ipam_environment.acc.pool_ids[0]
+}
+
+resource "ipam_allocation" "acc" {
+  name       = "acc-alloc-updated"
+  block_name = ipam_block.acc.name
+  block_id   = ipam_block.acc.id
+  cidr       = "10.2.101.0/26"
+}
+`,
+				ResourceName:    "ipam_allocation.acc",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithID,
+			},
+			{
+				ResourceName:      "ipam_allocation.acc",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 			{
 				ResourceName:      "ipam_allocation.acc",
 				ImportState:       true,
+				ImportStateId:     "acc-alloc-block/acc-alloc-updated",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "ipam_allocation.acc",
+				ImportState:       true,
+				ImportStateId:     "10.2.101.0/26",
 				ImportStateVerify: true,
 			},
 		},
@@ -267,6 +335,243 @@ resource "ipam_allocation" "acc" {
 	})
 }
 
+func TestAccAllocationResize(t *testing.T) {
+	testAccPreCheck(t)
+	testAccAllocationPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	config := func(cidr string) string {
+		return testAccProviderConfig(endpoint, token) + `
+resource "ipam_environment" "acc" {
+  name = "acc-resize-env"
+  pools = [
+    { name = "acc-resize-pool", cidr = "10.8.0.0/16" }
+  ]
+}
+
+resource "ipam_block" "acc" {
+  name           = "acc-resize-block"
+  cidr           = "10.8.0.0/16"
+  environment_id = ipam_environment.acc.id
+  pool_id        = ipam_environment.acc.pool_ids[0]
+}
+
+resource "ipam_allocation" "acc" {
+  name       = "acc-resize"
+  block_name = ipam_block.acc.name
+  cidr       = "` + cidr + `"
+}
+`
+	}
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("10.8.0.0/24"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("ipam_allocation.acc", "id", func(value string) error {
+						id = value
+						return nil
+					}),
+				),
+			},
+			{
+				// Growing into free space keeps the same allocation.
+				Config: config("10.8.0.0/23"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ipam_allocation.acc", "cidr", "10.8.0.0/23"),
+					resource.TestCheckResourceAttrWith("ipam_allocation.acc", "id", func(value string) error {
+						if value != id {
+							return fmt.Errorf("allocation was replaced: id %s, want %s", value, id)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAllocationByBlockID(t *testing.T) {
+	testAccPreCheck(t)
+	testAccAllocationPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	config := func(blockName string) string {
+		return testAccProviderConfig(endpoint, token) + `
+resource "ipam_environment" "acc" {
+  name = "acc-blockid-env"
+  pools = [
+    { name = "acc-blockid-pool", cidr = "10.9.0.0/16" }
+  ]
+}
+
+resource "ipam_block" "acc" {
+  name           = "` + blockName + `"
+  cidr           = "10.9.0.0/16"
+  environment_id = ipam_environment.acc.id
+  pool_id        = ipam_environment.acc.pool_ids[0]
+}
+
+resource "ipam_allocation" "acc" {
+  name     = "acc-blockid"
+  block_id = ipam_block.acc.id
+  cidr     = "10.9.1.0/24"
+}
+`
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("acc-blockid-block"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("ipam_allocation.acc", "block_id", "ipam_block.acc", "id"),
+					resource.TestCheckResourceAttr("ipam_allocation.acc", "block_name", "acc-blockid-block"),
+				),
+			},
+			{
+				// Renaming the block must not replace the allocation.
+				Config: config("acc-blockid-block-renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("ipam_allocation.acc", plancheck.ResourceActionNoop),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccAllocationConstrainedResource(t *testing.T) {
+	testAccPreCheck(t)
+	testAccAllocationPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	base := testAccProviderConfig(endpoint, token) + `
+resource "ipam_environment" "acc" {
+  name = "acc-constrained-env"
+  pools = [
+    { name = "acc-constrained-pool", cidr = "10.6.0.0/16" }
+  ]
+}
+
+resource "ipam_block" "acc" {
+  name           = "acc-constrained-block"
+  cidr           = "10.6.0.0/16"
+  environment_id = ipam_environment.acc.id
+  pool_id        = ipam_environment.acc.pool_ids[0]
+}
+`
+	allocation := func(strategy string) string {
+		return `
+resource "ipam_allocation" "acc" {
+  name          = "acc-constrained"
+  block_name    = ipam_block.acc.name
+  prefix_length = 24
+  within_cidr   = "10.6.128.0/17"
+  exclude_cidrs = ["10.6.128.0/24"]
+  strategy      = "` + strategy + `"
+}
+`
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      base + allocation("worst_fit"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Invalid strategy`),
+			},
+			{
+				Config: base + allocation("first_fit"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ipam_allocation.acc", "id"),
+					resource.TestCheckResourceAttr("ipam_allocation.acc", "cidr", "10.6.129.0/24"),
+				),
+			},
+			{
+				// Constraints only apply on create, so changing one replaces the allocation.
+				Config: base + allocation("last_fit"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("ipam_allocation.acc", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("ipam_allocation.acc", "cidr", "10.6.255.0/24"),
+			},
+		},
+	})
+}
+
+func TestAccAllocationSetResource(t *testing.T) {
+	testAccPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	base := testAccProviderConfig(endpoint, token) + `
+resource "ipam_environment" "acc" {
+  name = "acc-set-env"
+  pools = [
+    { name = "acc-set-pool", cidr = "10.7.0.0/16" }
+  ]
+}
+
+resource "ipam_block" "acc" {
+  name           = "acc-set-block"
+  cidr           = "10.7.0.0/16"
+  environment_id = ipam_environment.acc.id
+  pool_id        = ipam_environment.acc.pool_ids[0]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: base + `
+resource "ipam_allocation_set" "acc" {
+  block_name = ipam_block.acc.name
+  tiers = [
+    { name = "public", prefix_length = 24, count = 2 },
+    { name = "private", prefix_length = 20, count = 2 },
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("ipam_allocation_set.acc", "id"),
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.%", "4"),
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.private-1", "10.7.0.0/20"),
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.private-2", "10.7.16.0/20"),
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.public-1", "10.7.32.0/24"),
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.public-2", "10.7.33.0/24"),
+				),
+			},
+			{
+				// Removing public-1 and adding a member must not renumber the others.
+				Config: base + `
+resource "ipam_allocation_set" "acc" {
+  block_name  = ipam_block.acc.name
+  allocations = { "transit" = 28 }
+  tiers = [
+    { name = "private", prefix_length = 20, count = 2 },
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.%", "3"),
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.private-1", "10.7.0.0/20"),
+					resource.TestCheckResourceAttr("ipam_allocation_set.acc", "cidrs.private-2", "10.7.16.0/20"),
+					resource.TestCheckResourceAttrSet("ipam_allocation_set.acc", "cidrs.transit"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccReservedBlockResource(t *testing.T) {
 	testAccPreCheck(t)
 	endpoint := os.Getenv("IPAM_ENDPOINT")
@@ -308,6 +613,301 @@ resource "ipam_reserved_block" "acc" {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "ipam_reserved_block.acc",
+				ImportState:       true,
+				ImportStateId:     "10.200.0.0/24",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccDeletionProtection(t *testing.T) {
+	testAccPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	config := func(cidr string, protected bool) string {
+		return testAccProviderConfig(endpoint, token) + fmt.Sprintf(`
+resource "ipam_reserved_block" "acc" {
+  name                = "acc-protected"
+  cidr                = %q
+  reason              = "acceptance test"
+  deletion_protection = %t
+}
+`, cidr, protected)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("10.201.0.0/24", true),
+				Check:  resource.TestCheckResourceAttr("ipam_reserved_block.acc", "deletion_protection", "true"),
+			},
+			{
+				// Changing cidr forces replacement, which protection refuses at plan time.
+				Config:      config("10.201.1.0/24", true),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Deletion protection enabled`),
+			},
+			{
+				Config: config("10.201.0.0/24", false),
+				Check:  resource.TestCheckResourceAttr("ipam_reserved_block.acc", "deletion_protection", "false"),
+			},
+		},
+	})
+}
+
+func TestAccResourceIdentity(t *testing.T) {
+	testAccPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(endpoint, token) + `
+resource "ipam_environment" "acc" {
+  name = "acc-identity-env"
+  pools = [
+    { name = "acc-identity-pool", cidr = "10.10.0.0/16" }
+  ]
+}
+
+resource "ipam_block" "acc" {
+  name           = "acc-identity-block"
+  cidr           = "10.10.0.0/24"
+  environment_id = ipam_environment.acc.id
+  pool_id        = ipam_environment.acc.pool_ids[0]
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentityValueMatchesState("ipam_environment.acc", tfjsonpath.New("id")),
+					statecheck.ExpectIdentityValueMatchesState("ipam_block.acc", tfjsonpath.New("id")),
+				},
+			},
+			{
+				ResourceName:    "ipam_block.acc",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+// TestAccCIDRValidation checks that invalid CIDRs fail before apply and that normalize_cidrs
+// creates the network address without a diff on the next plan.
+func TestAccCIDRValidation(t *testing.T) {
+	testAccPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	block := func(cidr string) string {
+		return `
+resource "ipam_block" "acc" {
+  name = "acc-cidr-block"
+  cidr = "` + cidr + `"
+}
+`
+	}
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(endpoint, token) + block("10.14.0.0/33"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Invalid CIDR`),
+			},
+			{
+				Config:      testAccProviderConfig(endpoint, token) + block("10.14.0.1/24"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`did you mean 10\.14\.0\.0/24`),
+			},
+			{
+				Config: `
+provider "ipam" {
+  endpoint        = "` + endpoint + `"
+  token           = "` + token + `"
+  normalize_cidrs = true
+}
+` + block("10.14.0.1/24"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("ipam_block.acc", tfjsonpath.New("cidr"), knownvalue.StringExact("10.14.0.1/24")),
+					statecheck.ExpectKnownValue("ipam_block.acc", tfjsonpath.New("total_ips"), knownvalue.StringExact("256")),
+				},
+			},
+		},
+	})
+}
+
+// TestAccPlanConflicts checks that a block overlapping an existing block fails at plan time.
+func TestAccPlanConflicts(t *testing.T) {
+	testAccPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	base := testAccProviderConfig(endpoint, token) + `
+resource "ipam_block" "acc" {
+  name = "acc-conflict-block"
+  cidr = "10.15.0.0/24"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: base,
+			},
+			{
+				Config: base + `
+resource "ipam_block" "clash" {
+  name = "acc-conflict-clash"
+  cidr = "10.15.0.128/25"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`overlaps block "acc-conflict-block" \(10\.15\.0\.0/24\)`),
+			},
+		},
+	})
+}
+
+// TestAccPredictedCIDR checks that predict_cidr shows the CIDR in the plan, that apply allocates
+// exactly that CIDR, and that two predictions into the same range fail the plan.
+func TestAccPredictedCIDR(t *testing.T) {
+	testAccPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	base := testAccProviderConfig(endpoint, token) + `
+resource "ipam_block" "acc" {
+  name = "acc-predict-block"
+  cidr = "10.16.0.0/24"
+}
+`
+	predicted := func(name string) string {
+		return fmt.Sprintf(`
+resource "ipam_allocation" %[1]q {
+  name          = "acc-predict-%[1]s"
+  block_id      = ipam_block.acc.id
+  prefix_length = 26
+  predict_cidr  = true
+}
+`, name)
+	}
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: base,
+			},
+			{
+				Config: base + predicted("first"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("ipam_allocation.first", tfjsonpath.New("cidr"), knownvalue.StringExact("10.16.0.0/26")),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("ipam_allocation.first", tfjsonpath.New("cidr"), knownvalue.StringExact("10.16.0.0/26")),
+				},
+			},
+			{
+				Config:      base + predicted("first") + predicted("second") + predicted("third"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`planned for allocation "acc-predict-(second|third)"`),
+			},
+		},
+	})
+}
+
+// TestAccImportThenPlan imports each resource with an import block and expects an empty plan,
+// so configuration written from imported state round-trips.
+func TestAccImportThenPlan(t *testing.T) {
+	testAccPreCheck(t)
+	testAccAllocationPreCheck(t)
+	endpoint := os.Getenv("IPAM_ENDPOINT")
+	token := os.Getenv("IPAM_TOKEN")
+	importStep := func(name string) resource.TestStep {
+		return resource.TestStep{
+			ResourceName:    name,
+			ImportState:     true,
+			ImportStateKind: resource.ImportBlockWithID,
+		}
+	}
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_5_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(endpoint, token) + `
+resource "ipam_environment" "acc" {
+  name = "acc-roundtrip-env"
+  pools = [
+    { name = "acc-roundtrip-pool", cidr = "10.11.0.0/16" }
+  ]
+}
+
+resource "ipam_pool" "acc" {
+  environment_id = ipam_environment.acc.id
+  name           = "acc-roundtrip-extra"
+  cidr           = "10.12.0.0/16"
+}
+
+resource "ipam_block" "acc" {
+  name           = "acc-roundtrip-block"
+  cidr           = "10.11.0.0/24"
+  environment_id = ipam_environment.acc.id
+  pool_id        = ipam_environment.acc.pool_ids[0]
+}
+
+resource "ipam_block" "orphan" {
+  name = "acc-roundtrip-orphan"
+  cidr = "10.13.0.0/24"
+}
+
+resource "ipam_reserved_block" "acc" {
+  name   = "acc-roundtrip-reserved"
+  cidr   = "10.202.0.0/24"
+  reason = "acceptance test"
+}
+
+resource "ipam_allocation" "acc" {
+  name     = "acc-roundtrip-alloc"
+  block_id = ipam_block.acc.id
+  cidr     = "10.11.0.0/26"
+}
+
+resource "ipam_allocation_set" "acc" {
+  block_name = ipam_block.acc.name
+  allocations = {
+    "acc-roundtrip-set-a" = 27
+    "acc-roundtrip-set-b" = 28
+  }
+}
+`,
+			},
+			{
+				// The environment's own pool only; ipam_pool.acc manages the other one.
+				ResourceName:    "ipam_environment.acc",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithID,
+				ImportStateId:   "acc-roundtrip-env/acc-roundtrip-pool",
+			},
+			importStep("ipam_pool.acc"),
+			importStep("ipam_block.acc"),
+			importStep("ipam_block.orphan"),
+			importStep("ipam_reserved_block.acc"),
+			importStep("ipam_allocation.acc"),
+			{
+				ResourceName:    "ipam_allocation_set.acc",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithID,
+				ImportStateId:   "acc-roundtrip-block/acc-roundtrip-set-a,acc-roundtrip-set-b",
+			},
 		},
 	})
 }
@@ -420,6 +1020,20 @@ data "ipam_environment" "acc" {
   id = ipam_environment.acc.id
 }
 
+data "ipam_environment" "by_name" {
+  name = ipam_environment.acc.name
+}
+
+data "ipam_block" "by_name" {
+  environment_name = ipam_environment.acc.name
+  name             = ipam_block.acc.name
+}
+
+data "ipam_pool" "by_cidr" {
+  environment_id = ipam_environment.acc.id
+  cidr           = "10.5.0.0/8"
+}
+
 data "ipam_environments" "acc" {
   name = "acc-ds-noalloc"
 }
@@ -435,6 +1049,11 @@ data "ipam_blocks" "acc" {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("data.ipam_environment.acc", "name", "acc-ds-noalloc-env"),
 					resource.TestCheckResourceAttrPair("data.ipam_environment.acc", "id", "ipam_environment.acc", "id"),
+					resource.TestCheckResourceAttrPair("data.ipam_environment.by_name", "id", "ipam_environment.acc", "id"),
+					resource.TestCheckResourceAttr("data.ipam_environment.acc", "pools.#", "1"),
+					resource.TestCheckResourceAttrPair("data.ipam_environment.acc", "pools.0.id", "ipam_environment.acc", "pool_ids.0"),
+					resource.TestCheckResourceAttrPair("data.ipam_block.by_name", "id", "ipam_block.acc", "id"),
+					resource.TestCheckResourceAttrPair("data.ipam_pool.by_cidr", "id", "ipam_environment.acc", "pool_ids.0"),
 					resource.TestCheckResourceAttr("data.ipam_block.acc", "name", "acc-ds-noalloc-block"),
 					resource.TestCheckResourceAttr("data.ipam_block.acc", "cidr", "10.5.102.0/24"),
 				),
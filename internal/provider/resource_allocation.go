@@ -2,14 +2,22 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/netip"
 	"strings"
 
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
 	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -17,22 +25,37 @@ import (
 )
 
 var _ resource.Resource = &AllocationResource{}
+var _ resource.ResourceWithIdentity = &AllocationResource{}
 var _ resource.ResourceWithImportState = &AllocationResource{}
+var _ resource.ResourceWithUpgradeState = &AllocationResource{}
+var _ resource.ResourceWithModifyPlan = &AllocationResource{}
+var _ resource.ResourceWithValidateConfig = &AllocationResource{}
 
 func NewAllocationResource() resource.Resource {
 	return &AllocationResource{}
 }
 
 type AllocationResource struct {
-	api *client.Client
+	api            *client.Client
+	normalizeCIDRs bool
+	predictions    *predictionLedger
+	protections    *protectionLedger
 }
 
 type AllocationResourceModel struct {
-	Id           types.String `tfsdk:"id"`
-	Name         types.String `tfsdk:"name"`
-	BlockName    types.String `tfsdk:"block_name"`
-	Cidr         types.String `tfsdk:"cidr"`
-	PrefixLength types.Int64  `tfsdk:"prefix_length"`
+	Id                 types.String   `tfsdk:"id"`
+	Name               types.String   `tfsdk:"name"`
+	BlockName          types.String   `tfsdk:"block_name"`
+	BlockId            types.String   `tfsdk:"block_id"`
+	Cidr               cidrValue      `tfsdk:"cidr"`
+	PrefixLength       types.Int64    `tfsdk:"prefix_length"`
+	WithinCidr         types.String   `tfsdk:"within_cidr"`
+	ExcludeCidrs       types.List     `tfsdk:"exclude_cidrs"`
+	Strategy           types.String   `tfsdk:"strategy"`
+	MinGap             types.Int64    `tfsdk:"min_gap"`
+	PredictCidr        types.Bool     `tfsdk:"predict_cidr"`
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *AllocationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -41,9 +64,18 @@ func (r *AllocationResource) Metadata(ctx context.Context, req resource.Metadata
 
 func (r *AllocationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		MarkdownDescription: `IPAM allocation. An allocation is a subnet within a network block (e.g. a VPC or region).
 
-Provide either **cidr** (explicit) or **prefix_length** (auto-allocate the next available CIDR in the block using bin-packing).`,
+Identify the parent block with either **block_name** or **block_id**. Referencing the block by ID keeps the allocation in place when the block is renamed.
+
+Provide either **cidr** (explicit) or **prefix_length** (auto-allocate the next available CIDR in the block using bin-packing).
+
+Changing **cidr** or **prefix_length** resizes the allocation in place when possible: it can grow when the enclosing range is free within the block, and shrink when the smaller prefix keeps the allocation's network address. Otherwise the allocation is replaced. The plan explains which path was chosen.
+
+With **prefix_length**, the optional **within_cidr**, **exclude_cidrs**, **strategy** and **min_gap** constrain where the allocation is placed. When any of them is set the provider computes the CIDR from the block's current allocations and creates the allocation with that explicit CIDR. They are validated at plan time, and changing any of them forces replacement.
+
+With **prefix_length**, the CIDR is normally only known after apply. Set **predict_cidr** to run the packing at plan time instead: the plan shows the CIDR the allocation will get, and the allocation is created with exactly that CIDR, or fails if it was taken in the meantime.`,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -55,21 +87,77 @@ Provide either **cidr** (explicit) or **prefix_length** (auto-allocate the next
 				MarkdownDescription: "Allocation name.",
 			},
 			"block_name": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "Name of the parent network block.",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Name of the parent network block. Set `block_name` or `block_id`; if both are set they must refer to the same block. A name shared by blocks in several environments is ambiguous; use `block_id` for those. Changing this forces replacement.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
+			},
+			"block_id": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "UUID of the parent network block. Set `block_name` or `block_id`; if both are set they must refer to the same block. Unlike `block_name`, renaming the block does not affect the allocation. Changing this forces replacement.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"cidr": schema.StringAttribute{
+				CustomType:          cidrType{},
 				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "CIDR for this allocation. If omitted, set `prefix_length` to auto-allocate the next available CIDR.",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace(), stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "CIDR for this allocation. If omitted, set `prefix_length` to auto-allocate the next available CIDR. Changes are applied in place when the allocation can grow or shrink within the block; otherwise the allocation is replaced. Validated at plan time: must be a valid prefix without host bits set, unless the provider sets `normalize_cidrs`.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
 			"prefix_length": schema.Int64Attribute{
 				Optional:            true,
-				MarkdownDescription: "Desired prefix length (e.g. 24 for /24). When set without `cidr`, the API finds the next available CIDR in the block using bin-packing.",
+				MarkdownDescription: "Desired prefix length (e.g. 24 for /24). When set without `cidr`, the API finds the next available CIDR in the block using bin-packing. Changing it resizes the allocation in place (keeping its network address) when possible; otherwise the allocation is replaced.",
+			},
+			"within_cidr": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only place the allocation inside this sub-range of the block. Requires `prefix_length`. Changing this forces replacement.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"exclude_cidrs": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Ranges the allocation must never overlap. Requires `prefix_length`. Changing this forces replacement.",
+				PlanModifiers:       []planmodifier.List{listplanmodifier.RequiresReplace()},
+			},
+			"strategy": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Placement strategy: `first_fit` (lowest free address, the default), `best_fit` (smallest free range that fits), `last_fit` (highest free address) or `hash` (stable position derived from the allocation name). Requires `prefix_length`. Changing this forces replacement.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"min_gap": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Minimum number of free addresses to keep between this allocation and its neighbours (guard band). Requires `prefix_length`. Changing this forces replacement.",
 				PlanModifiers:       []planmodifier.Int64{int64planmodifier.RequiresReplace()},
 			},
+			"predict_cidr": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				MarkdownDescription: "With `prefix_length`, choose the CIDR at plan time from the block's current allocations, so the plan shows it and resources depending on it can be planned. Create then allocates exactly that CIDR and fails if it was taken since the plan. Only used when the allocation is created. Defaults to `false`.",
+			},
+			"deletion_protection": deletionProtectionAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+	}
+}
+
+func (r *AllocationResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {PriorSchema: &allocationSchemaV0, StateUpgrader: upgradeAllocationStateV0},
+	}
+}
+
+func (r *AllocationResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"id": identityIDAttribute("Allocation"),
+			"block_id": identityschema.StringAttribute{
+				OptionalForImport: true,
+				Description:       "UUID of the allocation's block.",
+			},
 		},
 	}
 }
@@ -78,12 +166,15 @@ func (r *AllocationResource) Configure(ctx context.Context, req resource.Configu
 	if req.ProviderData == nil {
 		return
 	}
-	api, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *providerData, got %T", req.ProviderData))
 		return
 	}
-	r.api = api
+	r.api = data.api
+	r.normalizeCIDRs = data.normalizeCIDRs
+	r.predictions = data.predictions
+	r.protections = data.protections
 }
 
 func (r *AllocationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -92,9 +183,16 @@ func (r *AllocationResource) Create(ctx context.Context, req resource.CreateRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 
 	name := plan.Name.ValueString()
-	blockName := plan.BlockName.ValueString()
+	blockName, blockID := blockRef(&plan)
 	hasCidr := !plan.Cidr.IsNull() && !plan.Cidr.IsUnknown()
 	hasPrefix := !plan.PrefixLength.IsNull() && !plan.PrefixLength.IsUnknown()
 
@@ -102,31 +200,393 @@ func (r *AllocationResource) Create(ctx context.Context, req resource.CreateRequ
 		resp.Diagnostics.AddError("Missing required attribute", "Either cidr or prefix_length must be specified.")
 		return
 	}
+	// A cidr planned for prefix_length comes from predict_cidr; a configured one conflicts.
+	predicted := false
 	if hasCidr && hasPrefix {
-		resp.Diagnostics.AddError("Conflicting attributes", "Specify either cidr or prefix_length, not both.")
+		var configCidr cidrValue
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cidr"), &configCidr)...)
+		predicted = plan.PredictCidr.ValueBool() && configCidr.IsNull()
+		if !predicted {
+			resp.Diagnostics.AddError("Conflicting attributes", "Specify either cidr or prefix_length, not both.")
+			return
+		}
+	}
+	constrained := !plan.WithinCidr.IsNull() || !plan.ExcludeCidrs.IsNull() || !plan.Strategy.IsNull() || !plan.MinGap.IsNull()
+	if constrained && !hasPrefix {
+		resp.Diagnostics.AddError("Conflicting attributes", "within_cidr, exclude_cidrs, strategy and min_gap can only be used with prefix_length.")
 		return
 	}
 
+	if blockID != "" && !plan.BlockName.IsNull() && !plan.BlockName.IsUnknown() {
+		block, err := r.api.GetBlock(ctx, blockID)
+		if err != nil {
+			resp.Diagnostics.Append(apiError(err))
+			return
+		}
+		if block.Name != plan.BlockName.ValueString() {
+			resp.Diagnostics.AddAttributeError(path.Root("block_name"), "Invalid block reference",
+				fmt.Sprintf("block_name %q does not match block_id %s, which is block %q.", plan.BlockName.ValueString(), blockID, block.Name))
+			return
+		}
+	}
+
 	var out *client.AllocationResponse
 	var err error
 
-	if hasPrefix {
+	if predicted {
+		tflog.Debug(ctx, "creating ipam_allocation at predicted CIDR", map[string]interface{}{"cidr": plan.Cidr.ValueString()})
+		out, err = r.api.CreateAllocation(ctx, name, blockName, blockID, plan.Cidr.ValueString())
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			resp.Diagnostics.AddAttributeError(path.Root("cidr"), "Predicted CIDR not available",
+				fmt.Sprintf("%s was predicted for allocation %q at plan time but could not be allocated, most likely because the block changed since the plan: %s\n\nRun terraform plan again for a new prediction.", plan.Cidr.ValueString(), name, err))
+			return
+		}
+	} else if hasPrefix && constrained {
+		opts, diags := r.allocationOptions(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		prefix, findErr := findInBlock(ctx, r.api, blockName, blockID, int(plan.PrefixLength.ValueInt64()), opts)
+		if findErr != nil {
+			resp.Diagnostics.AddError("No CIDR available", findErr.Error())
+			return
+		}
+		tflog.Debug(ctx, "placing constrained ipam_allocation", map[string]interface{}{"cidr": prefix.String(), "strategy": string(opts.Strategy)})
+		out, err = r.api.CreateAllocation(ctx, name, blockName, blockID, prefix.String())
+	} else if hasPrefix {
 		prefixLength := int(plan.PrefixLength.ValueInt64())
-		out, err = r.api.AutoAllocate(name, blockName, prefixLength)
+		out, err = r.api.AutoAllocate(ctx, name, blockName, blockID, prefixLength)
 	} else {
-		out, err = r.api.CreateAllocation(name, blockName, plan.Cidr.ValueString())
+		out, err = r.api.CreateAllocation(ctx, name, blockName, blockID, networkCIDR(plan.Cidr.ValueString(), r.normalizeCIDRs))
 	}
 
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	plan.Id = types.StringValue(strings.ToLower(out.Id))
 	plan.Name = types.StringValue(out.Name)
-	plan.BlockName = types.StringValue(out.BlockName)
-	plan.Cidr = types.StringValue(out.CIDR)
+	plan.Cidr = cidrStringValue(out.CIDR)
+	if err := r.setBlockRef(ctx, &plan, out); err != nil {
+		resp.Diagnostics.Append(apiError(err))
+		return
+	}
+	if p, ok := plannedPrefix(plan.Cidr); ok {
+		r.predictions.recordCreate(plan.BlockId.ValueString(), p)
+	}
 	tflog.Trace(ctx, "created ipam_allocation", map[string]interface{}{"id": plan.Id.ValueString(), "cidr": out.CIDR})
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, allocationIdentityModel{Id: plan.Id, BlockId: plan.BlockId}, &resp.Diagnostics)
+}
+
+func (r *AllocationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config AllocationResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Both may be set (configuration generated on import writes both); they must then agree,
+	// which Create checks once the values are known.
+	if config.BlockName.IsNull() && config.BlockId.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("block_name"), "Invalid block reference", "One of block_name or block_id must be set.")
+	}
+	constrained := !config.WithinCidr.IsNull() || !config.ExcludeCidrs.IsNull() || !config.Strategy.IsNull() || !config.MinGap.IsNull()
+	if constrained && config.PrefixLength.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("prefix_length"), "Conflicting attributes", "within_cidr, exclude_cidrs, strategy and min_gap can only be used with prefix_length.")
+	}
+	_, diags := r.allocationOptions(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// blockRef returns the parent block reference to send to the API. The ID is preferred because
+// it survives block renames; the name is only used when the ID is not known yet.
+func blockRef(m *AllocationResourceModel) (blockName, blockID string) {
+	if !m.BlockId.IsNull() && !m.BlockId.IsUnknown() && m.BlockId.ValueString() != "" {
+		return "", m.BlockId.ValueString()
+	}
+	return m.BlockName.ValueString(), ""
+}
+
+// setBlockRef stores the parent block of out in m. When the API does not return block_id, the ID
+// already in m is kept, or resolved from the block name.
+func (r *AllocationResource) setBlockRef(ctx context.Context, m *AllocationResourceModel, out *client.AllocationResponse) error {
+	m.BlockName = types.StringValue(out.BlockName)
+	if out.BlockID != "" {
+		m.BlockId = types.StringValue(out.BlockID)
+		return nil
+	}
+	if !m.BlockId.IsNull() && !m.BlockId.IsUnknown() && m.BlockId.ValueString() != "" {
+		return nil
+	}
+	block, err := blockByName(ctx, r.api, out.BlockName)
+	if err != nil {
+		return err
+	}
+	m.BlockId = types.StringValue(block.ID)
+	return nil
+}
+
+func (r *AllocationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.protections.recordPlan(ctx, req)
+	checkPlannedCIDR(ctx, req, resp, r.normalizeCIDRs)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.planResize(ctx, req, resp)
+	if !resp.Diagnostics.HasError() {
+		r.planPrediction(ctx, req, resp)
+	}
+	if !resp.Diagnostics.HasError() {
+		r.planConflicts(ctx, req, resp)
+	}
+	checkDeletionProtection(ctx, req, resp, "ipam_allocation")
+}
+
+// planConflicts checks a new or changed explicit cidr against its block, the block's other
+// allocations and the reserved blocks. CIDRs chosen from prefix_length are placed in free space
+// (or checked by planResize) and need no check here.
+func (r *AllocationResource) planConflicts(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.api == nil {
+		return
+	}
+	var plan, state, config AllocationResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() || config.Cidr.IsNull() || plan.BlockName.IsUnknown() && plan.BlockId.IsUnknown() {
+		return
+	}
+	prefix, ok := plannedPrefix(plan.Cidr)
+	if !ok || !req.State.Raw.IsNull() && sameNetwork(plan.Cidr.ValueString(), state.Cidr.ValueString()) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+	blockName, blockID := blockRef(&plan)
+	conflicts, err := allocationConflicts(ctx, r.api, prefix, blockName, blockID, state.Id.ValueString())
+	reportConflicts(&resp.Diagnostics, "ipam_allocation", prefix, conflicts, err)
+}
+
+// planPrediction fills in the cidr of a new allocation that sets predict_cidr by running the
+// packing against the block's current allocations. The CIDR planned for every new allocation is
+// recorded in the ledger, so a prediction overlapping another allocation planned in the same run
+// fails now instead of at apply. Blocks that do not exist yet leave the cidr unknown.
+func (r *AllocationResource) planPrediction(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.State.Raw.IsNull() || r.api == nil {
+		return
+	}
+	var plan, config AllocationResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || plan.Name.IsUnknown() || plan.BlockName.IsUnknown() && plan.BlockId.IsUnknown() {
+		return
+	}
+	predict := config.Cidr.IsNull() && plan.PredictCidr.ValueBool() && !plan.PrefixLength.IsNull() && !plan.PrefixLength.IsUnknown()
+	if predict && !plan.constraintsKnown() {
+		return
+	}
+	prefix, explicit := plannedPrefix(plan.Cidr)
+	if !predict && (!explicit || config.Cidr.IsNull()) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+	blockName, blockID := blockRef(&plan)
+	block, err := resolveBlock(ctx, r.api, blockName, blockID)
+	if err != nil {
+		if predict && !isNotFound(err) {
+			resp.Diagnostics.AddWarning("Could not predict CIDR", fmt.Sprintf("The cidr of ipam_allocation %q will be known after apply: %s", plan.Name.ValueString(), err))
+		}
+		return
+	}
+	if predict {
+		opts, diags := r.allocationOptions(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if prefix, err = placeInBlock(ctx, r.api, block, int(plan.PrefixLength.ValueInt64()), opts, r.predictions); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("prefix_length"), "No CIDR available", err.Error())
+			return
+		}
+		plan.Cidr = cidrStringValue(prefix.String())
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
+	if other, otherPrefix, ok := r.predictions.plan(block.ID, plan.Name.ValueString(), prefix); !ok {
+		resp.Diagnostics.AddAttributeError(path.Root("cidr"), "CIDR conflict",
+			fmt.Sprintf("ipam_allocation %q is planned at %s, which overlaps %s planned for allocation %q in block %q in the same run. A predicted CIDR cannot account for other allocations added to the block in the same run: give them distinct explicit cidrs, add them in separate runs, or manage them together with ipam_allocation_set.",
+				plan.Name.ValueString(), prefix, otherPrefix, other, block.Name))
+	}
+}
+
+// planResize decides whether a cidr or prefix_length change can be applied in place or needs a
+// replacement, and reports the chosen path as a warning in the plan. A change that cannot be
+// checked, because the provider is not configured or a value is not known yet, replaces the
+// allocation.
+func (r *AllocationResource) planResize(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+	var plan, state, config AllocationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	replace := func(p path.Path, reason string) {
+		if p.Equal(path.Root("prefix_length")) {
+			plan.Cidr = cidrUnknown()
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		}
+		resp.RequiresReplace = append(resp.RequiresReplace, p)
+		resp.Diagnostics.AddWarning("Allocation must be replaced", fmt.Sprintf("%s cannot be resized in place: %s. The allocation will be deleted and recreated.", state.Name.ValueString(), reason))
+	}
+
+	from, err := netip.ParsePrefix(state.Cidr.ValueString())
+	var changed path.Path
+	switch {
+	case !config.Cidr.IsNull():
+		if same, _ := plan.Cidr.StringSemanticEquals(ctx, state.Cidr); same && !plan.Cidr.IsUnknown() {
+			return
+		}
+		changed = path.Root("cidr")
+	case !plan.PrefixLength.IsNull():
+		if err != nil && plan.PrefixLength.Equal(state.PrefixLength) {
+			return
+		}
+		if err == nil && !plan.PrefixLength.IsUnknown() && plan.PrefixLength.ValueInt64() == int64(from.Bits()) {
+			return
+		}
+		changed = path.Root("prefix_length")
+	default:
+		return
+	}
+	if err != nil {
+		replace(changed, "its current CIDR is not known")
+		return
+	}
+
+	var to netip.Prefix
+	if changed.Equal(path.Root("cidr")) {
+		if plan.Cidr.IsUnknown() {
+			replace(changed, "the new CIDR is not known until apply")
+			return
+		}
+		if to, err = netip.ParsePrefix(plan.Cidr.ValueString()); err != nil {
+			replace(changed, fmt.Sprintf("%q is not a valid CIDR", plan.Cidr.ValueString()))
+			return
+		}
+		if to = to.Masked(); to == from.Masked() {
+			return
+		}
+	} else {
+		if plan.PrefixLength.IsUnknown() {
+			replace(changed, "the new prefix_length is not known until apply")
+			return
+		}
+		bits := int(plan.PrefixLength.ValueInt64())
+		if bits < 0 || bits > from.Addr().BitLen() {
+			replace(changed, fmt.Sprintf("/%d is not a valid prefix length for %s", bits, from))
+			return
+		}
+		// Keep the network address: grow to the enclosing supernet, shrink to the first subnet.
+		to = netip.PrefixFrom(from.Addr(), bits).Masked()
+	}
+	if r.api == nil {
+		replace(changed, "the provider is not configured, so the block cannot be checked")
+		return
+	}
+	if !plan.constraintsKnown() {
+		replace(changed, "its placement constraints are not known until apply")
+		return
+	}
+	opts, diags := r.allocationOptions(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	inPlace, reason, err := canResizeInPlace(ctx, r.api, state.BlockName.ValueString(), state.BlockId.ValueString(), state.Id.ValueString(), from, to, opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Could not plan allocation resize", err.Error())
+		return
+	}
+	if !inPlace {
+		replace(changed, reason)
+		return
+	}
+	// A configured cidr is planned as written; only a prefix_length change plans a new value.
+	if config.Cidr.IsNull() {
+		plan.Cidr = cidrStringValue(to.String())
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
+	resp.Diagnostics.AddWarning("Allocation will be resized in place", fmt.Sprintf("%s: %s.", state.Name.ValueString(), reason))
+}
+
+// constraintsKnown reports whether the placement constraints, including every exclude_cidrs
+// element, are known.
+func (m *AllocationResourceModel) constraintsKnown() bool {
+	if m.WithinCidr.IsUnknown() || m.ExcludeCidrs.IsUnknown() || m.Strategy.IsUnknown() || m.MinGap.IsUnknown() {
+		return false
+	}
+	for _, x := range m.ExcludeCidrs.Elements() {
+		if x.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// allocationOptions converts the placement constraints in the plan into packing options. Unknown
+// values are skipped, so ValidateConfig can check whatever is already known.
+func (r *AllocationResource) allocationOptions(ctx context.Context, plan *AllocationResourceModel) (cidr.Options, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	opts := cidr.Options{Strategy: cidr.FirstFit, Key: plan.Name.ValueString()}
+	if !plan.Strategy.IsNull() && !plan.Strategy.IsUnknown() {
+		if !cidr.ValidStrategy(plan.Strategy.ValueString()) {
+			diags.AddAttributeError(path.Root("strategy"), "Invalid strategy", fmt.Sprintf("strategy must be one of %v, got %q.", cidr.Strategies, plan.Strategy.ValueString()))
+			return opts, diags
+		}
+		opts.Strategy = cidr.Strategy(plan.Strategy.ValueString())
+	}
+	if !plan.WithinCidr.IsNull() && !plan.WithinCidr.IsUnknown() {
+		within, err := cidr.Parse(plan.WithinCidr.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("within_cidr"), "Invalid within_cidr", err.Error())
+			return opts, diags
+		}
+		opts.Within = within
+	}
+	if !plan.ExcludeCidrs.IsNull() && !plan.ExcludeCidrs.IsUnknown() {
+		var excludes []types.String
+		diags.Append(plan.ExcludeCidrs.ElementsAs(ctx, &excludes, false)...)
+		if diags.HasError() {
+			return opts, diags
+		}
+		for i, x := range excludes {
+			if x.IsUnknown() {
+				continue
+			}
+			p, err := cidr.Parse(x.ValueString())
+			if err != nil {
+				diags.AddAttributeError(path.Root("exclude_cidrs").AtListIndex(i), "Invalid exclude_cidrs", err.Error())
+				return opts, diags
+			}
+			opts.Exclude = append(opts.Exclude, p)
+		}
+	}
+	if !plan.MinGap.IsNull() && !plan.MinGap.IsUnknown() {
+		if plan.MinGap.ValueInt64() < 0 {
+			diags.AddAttributeError(path.Root("min_gap"), "Invalid min_gap", "min_gap must not be negative.")
+			return opts, diags
+		}
+		opts.MinGap = uint64(plan.MinGap.ValueInt64())
+	}
+	return opts, diags
 }
 
 func (r *AllocationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -135,16 +595,24 @@ func (r *AllocationResource) Read(ctx context.Context, req resource.ReadRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := r.api.GetAllocation(state.Id.ValueString())
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+	out, err := r.api.GetAllocation(ctx, state.Id.ValueString())
 	if err != nil {
 		if !strings.Contains(strings.ToLower(err.Error()), "not found") {
-			resp.Diagnostics.AddError("API error", err.Error())
+			resp.Diagnostics.Append(apiError(err))
 			return
 		}
-		// Fallback: some IPAM APIs do not support GET /api/allocations/{id}; find by block_name + name.
-		list, listErr := r.api.ListAllocations(state.Name.ValueString(), state.BlockName.ValueString(), 0, 0)
+		// Fallback: some IPAM APIs do not support GET /api/allocations/{id}; find by block + name.
+		blockName, blockID := blockRef(&state)
+		list, listErr := r.api.ListAllocations(ctx, state.Name.ValueString(), blockName, blockID, 0, 0)
 		if listErr != nil {
-			resp.Diagnostics.AddError("API error", listErr.Error())
+			resp.Diagnostics.Append(apiError(listErr))
 			return
 		}
 		switch n := len(list.Allocations); n {
@@ -167,9 +635,19 @@ func (r *AllocationResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 	state.Id = types.StringValue(strings.ToLower(out.Id))
 	state.Name = types.StringValue(out.Name)
-	state.BlockName = types.StringValue(out.BlockName)
-	state.Cidr = types.StringValue(out.CIDR)
+	state.Cidr = cidrStringValue(out.CIDR)
+	if err := r.setBlockRef(ctx, &state, out); err != nil {
+		resp.Diagnostics.Append(apiError(err))
+		return
+	}
+	if state.PredictCidr.IsNull() {
+		state.PredictCidr = types.BoolValue(false)
+	}
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	setIdentity(ctx, resp.Identity, allocationIdentityModel{Id: state.Id, BlockId: state.BlockId}, &resp.Diagnostics)
 }
 
 func (r *AllocationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -178,36 +656,61 @@ func (r *AllocationResource) Update(ctx context.Context, req resource.UpdateRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
 	var state AllocationResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 	id := plan.Id.ValueString()
-	out, err := r.api.UpdateAllocation(id, plan.Name.ValueString())
+	// A CIDR change only reaches Update when ModifyPlan found an in-place resize possible.
+	newCidr := ""
+	if same, _ := plan.Cidr.StringSemanticEquals(ctx, state.Cidr); !same {
+		newCidr = networkCIDR(plan.Cidr.ValueString(), r.normalizeCIDRs)
+	}
+	out, err := r.api.UpdateAllocation(ctx, id, plan.Name.ValueString(), newCidr)
 	if err != nil && strings.Contains(strings.ToLower(err.Error()), "not found") {
-		// Fallback: resolve allocation by block_name + prior name (current name on server before update).
-		list, listErr := r.api.ListAllocations(state.Name.ValueString(), plan.BlockName.ValueString(), 0, 0)
+		// Fallback: resolve allocation by block + prior name (current name on server before update).
+		blockName, blockID := blockRef(&state)
+		list, listErr := r.api.ListAllocations(ctx, state.Name.ValueString(), blockName, blockID, 0, 0)
 		if listErr != nil {
-			resp.Diagnostics.AddError("API error", listErr.Error())
+			resp.Diagnostics.Append(apiError(listErr))
 			return
 		}
 		if len(list.Allocations) != 1 {
-			resp.Diagnostics.AddError("API error", err.Error())
+			resp.Diagnostics.Append(apiError(err))
 			return
 		}
 		id = list.Allocations[0].Id
-		out, err = r.api.UpdateAllocation(id, plan.Name.ValueString())
+		out, err = r.api.UpdateAllocation(ctx, id, plan.Name.ValueString(), newCidr)
 	}
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	plan.Id = types.StringValue(strings.ToLower(out.Id))
 	plan.Name = types.StringValue(out.Name)
-	plan.BlockName = types.StringValue(out.BlockName)
-	plan.Cidr = types.StringValue(out.CIDR)
+	plan.Cidr = cidrStringValue(out.CIDR)
+	if err := r.setBlockRef(ctx, &plan, out); err != nil {
+		resp.Diagnostics.Append(apiError(err))
+		return
+	}
+	if newCidr != "" {
+		if from, ok := plannedPrefix(state.Cidr); ok {
+			r.predictions.recordDelete(plan.BlockId.ValueString(), from)
+		}
+		if to, ok := plannedPrefix(plan.Cidr); ok {
+			r.predictions.recordCreate(plan.BlockId.ValueString(), to)
+		}
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, allocationIdentityModel{Id: plan.Id, BlockId: plan.BlockId}, &resp.Diagnostics)
 }
 
 func (r *AllocationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -216,14 +719,29 @@ func (r *AllocationResource) Delete(ctx context.Context, req resource.DeleteRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := r.api.DeleteAllocation(state.Id.ValueString()); err != nil {
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(deletionProtected("ipam_allocation", state.Name.ValueString(), "deleted"))
+		return
+	}
+	if err := r.api.DeleteAllocation(ctx, state.Id.ValueString()); err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "not found") {
 			return
 		}
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
+		return
+	}
+	if p, ok := plannedPrefix(state.Cidr); ok {
+		r.predictions.recordDelete(state.BlockId.ValueString(), p)
 	}
 }
 
 func (r *AllocationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importByKey(ctx, "ipam_allocation", r.api, req, resp, resolveAllocationImport)
 }
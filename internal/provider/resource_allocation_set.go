@@ -0,0 +1,630 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/cidr"
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &AllocationSetResource{}
+var _ resource.ResourceWithIdentity = &AllocationSetResource{}
+var _ resource.ResourceWithModifyPlan = &AllocationSetResource{}
+var _ resource.ResourceWithValidateConfig = &AllocationSetResource{}
+var _ resource.ResourceWithImportState = &AllocationSetResource{}
+
+func NewAllocationSetResource() resource.Resource {
+	return &AllocationSetResource{}
+}
+
+type AllocationSetResource struct {
+	api         *client.Client
+	protections *protectionLedger
+}
+
+type AllocationSetResourceModel struct {
+	Id                 types.String   `tfsdk:"id"`
+	BlockName          types.String   `tfsdk:"block_name"`
+	Allocations        types.Map      `tfsdk:"allocations"` // name => prefix_length
+	Tiers              types.List     `tfsdk:"tiers"`       // list of { name, prefix_length, count }
+	Cidrs              types.Map      `tfsdk:"cidrs"`       // computed: name => CIDR
+	Ids                types.Map      `tfsdk:"ids"`         // computed: name => allocation UUID
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
+}
+
+type allocationTierModel struct {
+	Name         types.String `tfsdk:"name"`
+	PrefixLength types.Int64  `tfsdk:"prefix_length"`
+	Count        types.Int64  `tfsdk:"count"`
+}
+
+func (r *AllocationSetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_allocation_set"
+}
+
+func (r *AllocationSetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: `Carves many allocations out of one block in a single operation.
+
+Members come from **allocations** (a map of name to prefix length) and/or **tiers** (for example 3 public /24 and 3 private /20, named ` + "`public-1`" + `, ` + "`public-2`" + `, ...). New members are packed deterministically (largest prefixes first, then by name, lowest free address first) and created together; if any create fails, the members created in that apply are deleted again. Adding or removing members never renumbers the others.`,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Allocation set identifier (generated by the provider).",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"block_name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the parent network block. Block names can repeat across environments; the name must match exactly one block.",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"allocations": schema.MapAttribute{
+				ElementType:         types.Int64Type,
+				Optional:            true,
+				MarkdownDescription: "Map of allocation name to prefix length (e.g. `{ web = 24, db = 26 }`).",
+			},
+			"tiers": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Groups of equally sized allocations. Each tier creates `count` allocations named `<name>-1` to `<name>-<count>`.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Tier name, used as the allocation name prefix.",
+						},
+						"prefix_length": schema.Int64Attribute{
+							Required:            true,
+							MarkdownDescription: "Prefix length of every allocation in the tier.",
+						},
+						"count": schema.Int64Attribute{
+							Required:            true,
+							MarkdownDescription: "Number of allocations in the tier.",
+						},
+					},
+				},
+			},
+			"cidrs": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Map of allocation name to CIDR.",
+			},
+			"ids": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Map of allocation name to allocation UUID.",
+			},
+			"deletion_protection": deletionProtectionAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+	}
+}
+
+func (r *AllocationSetResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = idIdentitySchema("Allocation set")
+}
+
+func (r *AllocationSetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *providerData, got %T", req.ProviderData))
+		return
+	}
+	r.api = data.api
+	r.protections = data.protections
+}
+
+// members expands allocations and tiers into the full list of named prefixes. Entries that are
+// not known yet are skipped; see membersKnown.
+func (m *AllocationSetResourceModel) members(ctx context.Context) ([]cidr.Request, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var out []cidr.Request
+	seen := map[string]bool{}
+	add := func(p path.Path, name string, bits int64) {
+		if bits < 0 || bits > 128 {
+			diags.AddAttributeError(p, "Invalid prefix length", fmt.Sprintf("%q: prefix length %d must be between 0 and 128.", name, bits))
+			return
+		}
+		if seen[name] {
+			diags.AddAttributeError(p, "Duplicate allocation name", fmt.Sprintf("%q is defined more than once in allocations/tiers.", name))
+			return
+		}
+		seen[name] = true
+		out = append(out, cidr.Request{Name: name, Bits: int(bits)})
+	}
+	if !m.Allocations.IsNull() && !m.Allocations.IsUnknown() {
+		var allocs map[string]types.Int64
+		diags.Append(m.Allocations.ElementsAs(ctx, &allocs, false)...)
+		names := make([]string, 0, len(allocs))
+		for name := range allocs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if bits := allocs[name]; !bits.IsUnknown() {
+				add(path.Root("allocations").AtMapKey(name), name, bits.ValueInt64())
+			}
+		}
+	}
+	if !m.Tiers.IsNull() && !m.Tiers.IsUnknown() {
+		var tiers []allocationTierModel
+		diags.Append(m.Tiers.ElementsAs(ctx, &tiers, false)...)
+		for i, t := range tiers {
+			if t.Name.IsUnknown() || t.PrefixLength.IsUnknown() || t.Count.IsUnknown() {
+				continue
+			}
+			if t.Count.ValueInt64() < 1 {
+				diags.AddAttributeError(path.Root("tiers").AtListIndex(i).AtName("count"), "Invalid tier", fmt.Sprintf("tier %q: count must be at least 1.", t.Name.ValueString()))
+				continue
+			}
+			for n := int64(1); n <= t.Count.ValueInt64(); n++ {
+				add(path.Root("tiers").AtListIndex(i).AtName("prefix_length"), fmt.Sprintf("%s-%d", t.Name.ValueString(), n), t.PrefixLength.ValueInt64())
+			}
+		}
+	}
+	if len(out) == 0 && !diags.HasError() && m.membersKnown(ctx) {
+		diags.AddError("Missing allocations", "Set at least one entry in allocations or tiers.")
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, diags
+}
+
+// membersKnown reports whether allocations and tiers are fully known, so members lists every
+// member.
+func (m *AllocationSetResourceModel) membersKnown(ctx context.Context) bool {
+	for _, v := range []attr.Value{m.Allocations, m.Tiers} {
+		tv, err := v.ToTerraformValue(ctx)
+		if err != nil || !tv.IsFullyKnown() {
+			return false
+		}
+	}
+	return true
+}
+
+// stringMap reads a computed name => string map, treating null and unknown as empty.
+func stringMap(ctx context.Context, m types.Map) (map[string]string, diag.Diagnostics) {
+	out := map[string]string{}
+	if m.IsNull() || m.IsUnknown() {
+		return out, nil
+	}
+	diags := m.ElementsAs(ctx, &out, false)
+	return out, diags
+}
+
+// retained reports whether an existing member can be kept as is for the desired prefix length.
+func retained(existingCidr string, bits int) bool {
+	p, err := netip.ParsePrefix(existingCidr)
+	return err == nil && p.Bits() == bits
+}
+
+func (r *AllocationSetResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config AllocationSetResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, diags := config.members(ctx)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *AllocationSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.protections.recordPlan(ctx, req)
+	r.planMembers(ctx, req, resp)
+	checkDeletionProtection(ctx, req, resp, "ipam_allocation_set")
+}
+
+// planMembers keeps the CIDRs and IDs of retained members known in the plan.
+func (r *AllocationSetResource) planMembers(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+	var plan, state AllocationSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || !plan.membersKnown(ctx) {
+		return
+	}
+	members, diags := plan.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	cidrs, d := stringMap(ctx, state.Cidrs)
+	resp.Diagnostics.Append(d...)
+	ids, d := stringMap(ctx, state.Ids)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Members that stay keep their CIDR and ID in the plan, so only new members show as unknown.
+	planCidrs := make(map[string]attr.Value, len(members))
+	planIds := make(map[string]attr.Value, len(members))
+	for _, m := range members {
+		if c, ok := cidrs[m.Name]; ok && retained(c, m.Bits) {
+			planCidrs[m.Name] = types.StringValue(c)
+			planIds[m.Name] = types.StringValue(ids[m.Name])
+			continue
+		}
+		planCidrs[m.Name] = types.StringUnknown()
+		planIds[m.Name] = types.StringUnknown()
+	}
+	plan.Cidrs = types.MapValueMust(types.StringType, planCidrs)
+	plan.Ids = types.MapValueMust(types.StringType, planIds)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// createMembers packs and creates the given members in the block. On failure every allocation
+// created by this call is deleted again and the error describes both the failure and the rollback.
+func (r *AllocationSetResource) createMembers(ctx context.Context, blockName string, members []cidr.Request) (map[string]client.AllocationResponse, error) {
+	block, err := blockByName(ctx, r.api, blockName)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := netip.ParsePrefix(block.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("block %q has invalid CIDR %q: %w", block.Name, block.CIDR, err)
+	}
+	used, err := usedInBlock(ctx, r.api, block)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := cidr.Carve(parent, used, members)
+	if err != nil {
+		return nil, fmt.Errorf("block %q (%s): %w", block.Name, block.CIDR, err)
+	}
+	created := map[string]client.AllocationResponse{}
+	for _, m := range members {
+		out, err := r.api.CreateAllocation(ctx, m.Name, block.Name, block.ID, layout[m.Name].String())
+		if err != nil {
+			if _, rbErr := r.deleteMembers(ctx, created); rbErr != nil {
+				return nil, fmt.Errorf("create %s (%s): %w; rollback failed: %v", m.Name, layout[m.Name], err, rbErr)
+			}
+			return nil, fmt.Errorf("create %s (%s): %w; rolled back %d allocation(s) created in this apply", m.Name, layout[m.Name], err, len(created))
+		}
+		created[m.Name] = *out
+		tflog.Trace(ctx, "created ipam_allocation_set member", map[string]interface{}{"name": m.Name, "cidr": out.CIDR})
+	}
+	return created, nil
+}
+
+// deleteMembers deletes allocations by ID, ignoring ones that are already gone. It returns the
+// members that could not be deleted.
+func (r *AllocationSetResource) deleteMembers(ctx context.Context, members map[string]client.AllocationResponse) (map[string]client.AllocationResponse, error) {
+	remaining := map[string]client.AllocationResponse{}
+	var failed []string
+	for name, a := range members {
+		if err := r.api.DeleteAllocation(ctx, a.Id); err != nil && !strings.Contains(strings.ToLower(err.Error()), "not found") {
+			remaining[name] = a
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return remaining, fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return remaining, nil
+}
+
+// restoreMembers re-creates deleted members at their previous CIDRs and returns the ones it
+// restored.
+func (r *AllocationSetResource) restoreMembers(ctx context.Context, blockName string, members map[string]client.AllocationResponse) (map[string]client.AllocationResponse, error) {
+	restored := map[string]client.AllocationResponse{}
+	if len(members) == 0 {
+		return restored, nil
+	}
+	block, err := blockByName(ctx, r.api, blockName)
+	if err != nil {
+		return restored, err
+	}
+	var failed []string
+	for name, a := range members {
+		out, err := r.api.CreateAllocation(ctx, name, block.Name, block.ID, a.CIDR)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s): %v", name, a.CIDR, err))
+			continue
+		}
+		restored[name] = *out
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return restored, fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return restored, nil
+}
+
+// setMembers stores the name => CIDR and name => ID maps in the model.
+func setMembers(m *AllocationSetResourceModel, members map[string]client.AllocationResponse) {
+	cidrs := make(map[string]attr.Value, len(members))
+	ids := make(map[string]attr.Value, len(members))
+	for name, a := range members {
+		cidrs[name] = types.StringValue(a.CIDR)
+		ids[name] = types.StringValue(strings.ToLower(a.Id))
+	}
+	m.Cidrs = types.MapValueMust(types.StringType, cidrs)
+	m.Ids = types.MapValueMust(types.StringType, ids)
+}
+
+func (r *AllocationSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan AllocationSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+	members, diags := plan.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	created, err := r.createMembers(ctx, plan.BlockName.ValueString(), members)
+	if err != nil {
+		resp.Diagnostics.Append(apiError(err))
+		return
+	}
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		resp.Diagnostics.AddError("Could not generate ID", err.Error())
+		return
+	}
+	plan.Id = types.StringValue(id)
+	setMembers(&plan, created)
+	tflog.Trace(ctx, "created ipam_allocation_set", map[string]interface{}{"id": id, "members": len(created)})
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: plan.Id}, &resp.Diagnostics)
+}
+
+func (r *AllocationSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state AllocationSetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+	ids, diags := stringMap(ctx, state.Ids)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var allocs []client.AllocationResponse
+	block, err := blockByName(ctx, r.api, state.BlockName.ValueString())
+	if err == nil {
+		allocs, err = blockAllocations(ctx, r.api, block)
+	}
+	// A deleted block leaves no members; they drop out of state below.
+	if err != nil && !isNotFound(err) {
+		resp.Diagnostics.Append(apiError(err))
+		return
+	}
+	byID := make(map[string]client.AllocationResponse, len(allocs))
+	for _, a := range allocs {
+		byID[strings.ToLower(a.Id)] = a
+	}
+	// Members deleted outside Terraform drop out of state so the next plan recreates them.
+	members := map[string]client.AllocationResponse{}
+	for name, id := range ids {
+		if a, ok := byID[strings.ToLower(id)]; ok {
+			members[name] = a
+		}
+	}
+	setMembers(&state, members)
+	if state.Allocations.IsNull() && state.Tiers.IsNull() {
+		// Imported: describe the members as an allocations map so generated config round-trips.
+		state.Allocations = memberPrefixLengths(members)
+	}
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: state.Id}, &resp.Diagnostics)
+}
+
+// memberPrefixLengths returns the allocations map (name => prefix length) describing members.
+func memberPrefixLengths(members map[string]client.AllocationResponse) types.Map {
+	bits := make(map[string]attr.Value, len(members))
+	for name, a := range members {
+		if p, err := netip.ParsePrefix(a.CIDR); err == nil {
+			bits[name] = types.Int64Value(int64(p.Bits()))
+		}
+	}
+	return types.MapValueMust(types.Int64Type, bits)
+}
+
+func (r *AllocationSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state AllocationSetResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+	desired, diags := plan.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	cidrs, d := stringMap(ctx, state.Cidrs)
+	resp.Diagnostics.Append(d...)
+	ids, d := stringMap(ctx, state.Ids)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, err := r.updateMembers(ctx, plan.BlockName.ValueString(), state.BlockName.ValueString(), desired, cidrs, ids)
+	if err != nil {
+		resp.Diagnostics.Append(apiError(err))
+	}
+	setMembers(&plan, members)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: plan.Id}, &resp.Diagnostics)
+}
+
+// updateMembers changes the members in cidrs and ids (name => CIDR and name => ID) to desired
+// and returns the members that exist afterwards, also when it fails part way.
+//
+// A resized member keeps its name and the API may require allocation names to be unique within a
+// block, so its old allocation is deleted before the new one is created. If the additions fail,
+// the resized members are re-created at their old CIDRs. Members dropped from the set are only
+// deleted once the additions have succeeded. A member leaves state only once its delete has
+// succeeded.
+func (r *AllocationSetResource) updateMembers(ctx context.Context, blockName, stateBlockName string, desired []cidr.Request, cidrs, ids map[string]string) (map[string]client.AllocationResponse, error) {
+	kept := map[string]client.AllocationResponse{}
+	var added []cidr.Request
+	for _, m := range desired {
+		if c, ok := cidrs[m.Name]; ok && retained(c, m.Bits) {
+			kept[m.Name] = client.AllocationResponse{Id: ids[m.Name], Name: m.Name, BlockName: stateBlockName, CIDR: c}
+			continue
+		}
+		added = append(added, m)
+	}
+	removed := map[string]client.AllocationResponse{}
+	for name, id := range ids {
+		if _, ok := kept[name]; !ok {
+			removed[name] = client.AllocationResponse{Id: id, Name: name, BlockName: stateBlockName, CIDR: cidrs[name]}
+		}
+	}
+	resized := map[string]client.AllocationResponse{}
+	for _, m := range added {
+		if a, ok := removed[m.Name]; ok {
+			resized[m.Name] = a
+			delete(removed, m.Name)
+		}
+	}
+	keep := func(members map[string]client.AllocationResponse) {
+		for name, a := range members {
+			kept[name] = a
+		}
+	}
+	// rollback re-creates the resized members that were deleted and adds the restore failures,
+	// if any, to err.
+	rollback := func(err error) error {
+		deleted := map[string]client.AllocationResponse{}
+		for name, a := range resized {
+			if _, ok := kept[name]; !ok {
+				deleted[name] = a
+			}
+		}
+		restored, rErr := r.restoreMembers(ctx, blockName, deleted)
+		keep(restored)
+		if rErr != nil {
+			return fmt.Errorf("%w; restoring resized members failed: %v", err, rErr)
+		}
+		return err
+	}
+
+	remaining, err := r.deleteMembers(ctx, resized)
+	keep(remaining)
+	if err != nil {
+		keep(removed)
+		return kept, rollback(fmt.Errorf("delete resized members: %w", err))
+	}
+	if len(added) > 0 {
+		created, err := r.createMembers(ctx, blockName, added)
+		if err != nil {
+			keep(removed)
+			return kept, rollback(err)
+		}
+		keep(created)
+	}
+	remaining, err = r.deleteMembers(ctx, removed)
+	keep(remaining)
+	if err != nil {
+		return kept, fmt.Errorf("delete removed members: %w", err)
+	}
+	return kept, nil
+}
+
+func (r *AllocationSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state AllocationSetResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(deletionProtected("ipam_allocation_set", state.Id.ValueString(), "deleted"))
+		return
+	}
+	ids, diags := stringMap(ctx, state.Ids)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	members := make(map[string]client.AllocationResponse, len(ids))
+	for name, id := range ids {
+		members[name] = client.AllocationResponse{Id: id, Name: name}
+	}
+	if _, err := r.deleteMembers(ctx, members); err != nil {
+		resp.Diagnostics.Append(apiError(err))
+	}
+}
+
+// ImportState adopts existing allocations as members of a new set. The import ID is
+// "block-name/member-a,member-b"; the set's members are described by an allocations map.
+func (r *AllocationSetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID == "" {
+		resp.Diagnostics.AddError("Cannot import ipam_allocation_set", "The set's ID is generated by the provider; import it by ID as block-name/member-a,member-b instead of by identity.")
+		return
+	}
+	blockName, names, err := splitImportID(strings.TrimSpace(req.ID), "block-name/member-a,member-b")
+	if err != nil {
+		resp.Diagnostics.AddError("Cannot import ipam_allocation_set", err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+	ids, err := resolveAllocationSetMembers(ctx, r.api, blockName, names)
+	if err != nil {
+		resp.Diagnostics.AddError("Cannot import ipam_allocation_set", err.Error())
+		return
+	}
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		resp.Diagnostics.AddError("Could not generate ID", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("block_name"), blockName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ids"), ids)...)
+}
@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// resourceValue returns an object of r's schema with the given non-null attributes (the others are
+// null) and the schema.
+func resourceValue(t *testing.T, r resource.Resource, vals map[string]tftypes.Value) (schema.Schema, tftypes.Value) {
+	t.Helper()
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	typ := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	all := map[string]tftypes.Value{}
+	for name, attrType := range typ.AttributeTypes {
+		all[name] = tftypes.NewValue(attrType, nil)
+		if v, ok := vals[name]; ok && !v.IsNull() {
+			all[name] = v
+		}
+	}
+	return schemaResp.Schema, tftypes.NewValue(typ, all)
+}
+
+// validateAllocation runs the ipam_allocation ValidateConfig with the given config attributes.
+func validateAllocation(t *testing.T, config map[string]tftypes.Value) []string {
+	t.Helper()
+	s, raw := resourceValue(t, NewAllocationResource(), config)
+	var resp resource.ValidateConfigResponse
+	NewAllocationResource().(*AllocationResource).ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: s, Raw: raw}}, &resp)
+	var summaries []string
+	for _, d := range resp.Diagnostics.Errors() {
+		summaries = append(summaries, d.Summary())
+	}
+	return summaries
+}
+
+func TestAllocationValidateConfig(t *testing.T) {
+	str := func(v interface{}) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+	list := func(v ...tftypes.Value) tftypes.Value {
+		return tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, v)
+	}
+	prefix := tftypes.NewValue(tftypes.Number, 24)
+	tests := []struct {
+		name   string
+		config map[string]tftypes.Value
+		want   string
+	}{
+		{
+			name:   "valid constraints",
+			config: map[string]tftypes.Value{"prefix_length": prefix, "strategy": str("best_fit"), "exclude_cidrs": list(str("10.0.0.0/24"))},
+		},
+		{
+			name:   "exclude_cidrs element not known yet",
+			config: map[string]tftypes.Value{"prefix_length": prefix, "exclude_cidrs": list(str("10.0.0.0/24"), str(tftypes.UnknownValue))},
+		},
+		{
+			name:   "invalid exclude_cidrs element",
+			config: map[string]tftypes.Value{"prefix_length": prefix, "exclude_cidrs": list(str(tftypes.UnknownValue), str("10.0.0.1/24"))},
+			want:   "Invalid exclude_cidrs",
+		},
+		{
+			name:   "invalid strategy",
+			config: map[string]tftypes.Value{"prefix_length": prefix, "strategy": str("worst_fit")},
+			want:   "Invalid strategy",
+		},
+		{
+			name:   "invalid within_cidr",
+			config: map[string]tftypes.Value{"prefix_length": prefix, "within_cidr": str("10.0.0.0/33")},
+			want:   "Invalid within_cidr",
+		},
+		{
+			name:   "negative min_gap",
+			config: map[string]tftypes.Value{"prefix_length": prefix, "min_gap": tftypes.NewValue(tftypes.Number, -1)},
+			want:   "Invalid min_gap",
+		},
+		{
+			name:   "constraint without prefix_length",
+			config: map[string]tftypes.Value{"cidr": str("10.0.0.0/24"), "strategy": str("first_fit")},
+			want:   "Conflicting attributes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["name"] = str("web")
+			tt.config["block_name"] = str("app")
+			got := strings.Join(validateAllocation(t, tt.config), "; ")
+			if got != tt.want {
+				t.Errorf("errors %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocationPlanResize(t *testing.T) {
+	ctx := context.Background()
+	str := func(v interface{}) tftypes.Value { return tftypes.NewValue(tftypes.String, v) }
+	num := func(v interface{}) tftypes.Value { return tftypes.NewValue(tftypes.Number, v) }
+	// web is 10.0.0.0/26 in block app; see prodFixture.
+	object := func(cidr, prefixLength, within tftypes.Value) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"id": str("a1"), "name": str("web"), "block_name": str("app"), "block_id": str("b1"),
+			"cidr": cidr, "prefix_length": prefixLength, "within_cidr": within,
+			"predict_cidr": tftypes.NewValue(tftypes.Bool, false), "deletion_protection": tftypes.NewValue(tftypes.Bool, false),
+		}
+	}
+	null := str(nil)
+	tests := []struct {
+		name        string
+		api         bool
+		state       map[string]tftypes.Value
+		config      map[string]tftypes.Value
+		wantReplace string
+		wantCidr    string
+	}{
+		{
+			name:     "grow in place",
+			api:      true,
+			state:    object(str("10.0.0.0/26"), num(26), null),
+			config:   object(null, num(25), null),
+			wantCidr: "10.0.0.0/25",
+		},
+		{
+			name:        "grow out of within_cidr",
+			api:         true,
+			state:       object(str("10.0.0.0/26"), num(26), str("10.0.0.0/26")),
+			config:      object(null, num(25), str("10.0.0.0/26")),
+			wantReplace: "prefix_length",
+		},
+		{
+			name:        "provider not configured",
+			state:       object(str("10.0.0.0/26"), num(26), null),
+			config:      object(null, num(25), null),
+			wantReplace: "prefix_length",
+		},
+		{
+			name:        "current cidr not known",
+			api:         true,
+			state:       object(null, num(26), null),
+			config:      object(null, num(25), null),
+			wantReplace: "prefix_length",
+		},
+		{
+			name:        "new cidr not known",
+			api:         true,
+			state:       object(str("10.0.0.0/26"), null, null),
+			config:      object(str(tftypes.UnknownValue), null, null),
+			wantReplace: "cidr",
+		},
+		{
+			name:     "unchanged",
+			api:      true,
+			state:    object(str("10.0.0.0/26"), num(26), null),
+			config:   object(null, num(26), null),
+			wantCidr: "10.0.0.0/26",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &AllocationResource{}
+			if tt.api {
+				r.api = newFakeAPI(t, prodFixture())
+			}
+			s, state := resourceValue(t, NewAllocationResource(), tt.state)
+			_, config := resourceValue(t, NewAllocationResource(), tt.config)
+			// The plan keeps the prior cidr unless it is configured, as UseStateForUnknown does.
+			planned := map[string]tftypes.Value{}
+			for k, v := range tt.config {
+				planned[k] = v
+			}
+			if tt.config["cidr"].IsNull() {
+				planned["cidr"] = tt.state["cidr"]
+			}
+			_, plan := resourceValue(t, NewAllocationResource(), planned)
+			req := resource.ModifyPlanRequest{
+				State:  tfsdk.State{Schema: s, Raw: state},
+				Plan:   tfsdk.Plan{Schema: s, Raw: plan},
+				Config: tfsdk.Config{Schema: s, Raw: config},
+			}
+			resp := resource.ModifyPlanResponse{Plan: req.Plan}
+			r.planResize(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			var replaced []string
+			for _, p := range resp.RequiresReplace {
+				replaced = append(replaced, p.String())
+			}
+			if got := strings.Join(replaced, ","); got != tt.wantReplace {
+				t.Errorf("requires replace %q, want %q", got, tt.wantReplace)
+			}
+			var cidr cidrValue
+			resp.Plan.GetAttribute(ctx, path.Root("cidr"), &cidr)
+			if tt.wantReplace == "prefix_length" && !cidr.IsUnknown() {
+				t.Errorf("planned cidr %s, want unknown for a replacement", cidr)
+			}
+			if tt.wantCidr != "" && cidr.ValueString() != tt.wantCidr {
+				t.Errorf("planned cidr %s, want %s", cidr, tt.wantCidr)
+			}
+		})
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -15,25 +15,33 @@ import (
 )
 
 var _ resource.Resource = &BlockResource{}
+var _ resource.ResourceWithIdentity = &BlockResource{}
+var _ resource.ResourceWithModifyPlan = &BlockResource{}
 var _ resource.ResourceWithImportState = &BlockResource{}
+var _ resource.ResourceWithUpgradeState = &BlockResource{}
 
 func NewBlockResource() resource.Resource {
 	return &BlockResource{}
 }
 
 type BlockResource struct {
-	api *client.Client
+	api            *client.Client
+	normalizeCIDRs bool
+	protections    *protectionLedger
 }
 
 type BlockResourceModel struct {
-	Id            types.String `tfsdk:"id"`
-	Name          types.String `tfsdk:"name"`
-	Cidr          types.String `tfsdk:"cidr"`
-	TotalIps      types.String `tfsdk:"total_ips"`   // string: derive-only, supports IPv6 /64 etc.
-	UsedIps       types.String `tfsdk:"used_ips"`
-	AvailableIps  types.String `tfsdk:"available_ips"`
-	EnvironmentId types.String `tfsdk:"environment_id"`
-	PoolId        types.String `tfsdk:"pool_id"`
+	Id                 types.String   `tfsdk:"id"`
+	Name               types.String   `tfsdk:"name"`
+	Cidr               cidrValue      `tfsdk:"cidr"`
+	TotalIps           types.String   `tfsdk:"total_ips"` // string: derive-only, supports IPv6 /64 etc.
+	UsedIps            types.String   `tfsdk:"used_ips"`
+	AvailableIps       types.String   `tfsdk:"available_ips"`
+	EnvironmentId      types.String   `tfsdk:"environment_id"`
+	PoolId             types.String   `tfsdk:"pool_id"`
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	ForceDestroy       types.Bool     `tfsdk:"force_destroy"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *BlockResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -42,6 +50,7 @@ func (r *BlockResource) Metadata(ctx context.Context, req resource.MetadataReque
 
 func (r *BlockResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             1,
 		MarkdownDescription: "IPAM network block. A block is a CIDR range assigned to an environment; allocations are subnets within a block.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -54,9 +63,10 @@ func (r *BlockResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				MarkdownDescription: "Block name.",
 			},
 			"cidr": schema.StringAttribute{
+				CustomType:          cidrType{},
 				Required:            true,
-				MarkdownDescription: "CIDR range (e.g. 10.0.0.0/8).",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				MarkdownDescription: "CIDR range (e.g. 10.0.0.0/8). Validated at plan time: must be a valid prefix without host bits set, unless the provider sets `normalize_cidrs`.",
+				PlanModifiers:       []planmodifier.String{cidrRequiresReplace()},
 			},
 			"environment_id": schema.StringAttribute{
 				Optional:            true,
@@ -78,20 +88,37 @@ func (r *BlockResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Computed:            true,
 				MarkdownDescription: "Available IPs.",
 			},
+			"deletion_protection": deletionProtectionAttribute(),
+			"force_destroy":       forceDestroyAttribute("allocations"),
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+	}
+}
+
+func (r *BlockResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {PriorSchema: &blockSchemaV0, StateUpgrader: upgradeBlockStateV0},
 	}
 }
 
+func (r *BlockResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = idIdentitySchema("Block")
+}
+
 func (r *BlockResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
-	api, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *providerData, got %T", req.ProviderData))
 		return
 	}
-	r.api = api
+	r.api = data.api
+	r.normalizeCIDRs = data.normalizeCIDRs
+	r.protections = data.protections
 }
 
 func (r *BlockResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -100,20 +127,69 @@ func (r *BlockResource) Create(ctx context.Context, req resource.CreateRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 	envID := plan.EnvironmentId.ValueString()
 	var poolID *string
 	if !plan.PoolId.IsNull() && plan.PoolId.ValueString() != "" {
 		v := plan.PoolId.ValueString()
 		poolID = &v
 	}
-	out, err := r.api.CreateBlock(plan.Name.ValueString(), plan.Cidr.ValueString(), envID, poolID)
+	out, err := r.api.CreateBlock(ctx, plan.Name.ValueString(), networkCIDR(plan.Cidr.ValueString(), r.normalizeCIDRs), envID, poolID)
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	r.setModelFromAPI(&plan, out)
 	tflog.Trace(ctx, "created ipam_block", map[string]interface{}{"id": out.ID})
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: plan.Id}, &resp.Diagnostics)
+}
+
+// ModifyPlan checks the CIDR and enforces deletion_protection at plan time.
+func (r *BlockResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.protections.recordPlan(ctx, req)
+	checkPlannedCIDR(ctx, req, resp, r.normalizeCIDRs)
+	if !resp.Diagnostics.HasError() {
+		r.planConflicts(ctx, req, resp)
+	}
+	checkDeletionProtection(ctx, req, resp, "ipam_block")
+}
+
+// planConflicts checks a new or changed block CIDR against its pool, the existing blocks of its
+// environment and the reserved blocks.
+func (r *BlockResource) planConflicts(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.api == nil {
+		return
+	}
+	var plan, state BlockResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	prefix, ok := plannedPrefix(plan.Cidr)
+	if !ok || plan.EnvironmentId.IsUnknown() {
+		return
+	}
+	if !req.State.Raw.IsNull() && sameNetwork(plan.Cidr.ValueString(), state.Cidr.ValueString()) && plan.PoolId.Equal(state.PoolId) && plan.EnvironmentId.Equal(state.EnvironmentId) {
+		return
+	}
+	poolID := ""
+	if !plan.PoolId.IsUnknown() {
+		poolID = plan.PoolId.ValueString()
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+	conflicts, err := blockConflicts(ctx, r.api, prefix, state.Id.ValueString(), plan.EnvironmentId.ValueString(), poolID)
+	reportConflicts(&resp.Diagnostics, "ipam_block", prefix, conflicts, err)
 }
 
 func (r *BlockResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -122,13 +198,27 @@ func (r *BlockResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := r.api.GetBlock(state.Id.ValueString())
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+	out, err := r.api.GetBlock(ctx, state.Id.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	r.setModelFromAPI(&state, out)
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+	if state.ForceDestroy.IsNull() {
+		state.ForceDestroy = types.BoolValue(false)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: state.Id}, &resp.Diagnostics)
 }
 
 func (r *BlockResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -137,6 +227,13 @@ func (r *BlockResource) Update(ctx context.Context, req resource.UpdateRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
 	var envID *string
 	if !plan.EnvironmentId.IsNull() && plan.EnvironmentId.ValueString() != "" {
 		v := plan.EnvironmentId.ValueString()
@@ -147,13 +244,14 @@ func (r *BlockResource) Update(ctx context.Context, req resource.UpdateRequest,
 		v := plan.PoolId.ValueString()
 		poolID = &v
 	}
-	out, err := r.api.UpdateBlock(plan.Id.ValueString(), plan.Name.ValueString(), envID, poolID)
+	out, err := r.api.UpdateBlock(ctx, plan.Id.ValueString(), plan.Name.ValueString(), envID, poolID)
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	r.setModelFromAPI(&plan, out)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: plan.Id}, &resp.Diagnostics)
 }
 
 func (r *BlockResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -162,20 +260,47 @@ func (r *BlockResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := r.api.DeleteBlock(state.Id.ValueString()); err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(deletionProtected("ipam_block", state.Name.ValueString(), "deleted"))
+		return
+	}
+	if state.ForceDestroy.ValueBool() {
+		var c cascade
+		block := client.BlockResponse{ID: state.Id.ValueString(), Name: state.Name.ValueString(), CIDR: state.Cidr.ValueString()}
+		if err := c.addBlock(ctx, r.api, block, false); err != nil {
+			resp.Diagnostics.AddError("Force destroy stopped", fmt.Sprintf("ipam_block %q: %s", state.Name.ValueString(), err))
+			return
+		}
+		resp.Diagnostics.Append(c.destroy(ctx, r.api, r.protections, "ipam_block", state.Name.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if err := r.api.DeleteBlock(ctx, state.Id.ValueString()); err != nil {
+		resp.Diagnostics.Append(apiError(err))
 	}
 }
 
 func (r *BlockResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importByKey(ctx, "ipam_block", r.api, req, resp, resolveBlockImport)
 }
 
 func (r *BlockResource) setModelFromAPI(m *BlockResourceModel, out *client.BlockResponse) {
 	m.Id = types.StringValue(out.ID)
 	m.Name = types.StringValue(out.Name)
-	m.Cidr = types.StringValue(out.CIDR)
-	m.EnvironmentId = types.StringValue(out.EnvironmentID)
+	m.Cidr = cidrStringValue(out.CIDR)
+	if out.EnvironmentID != "" {
+		m.EnvironmentId = types.StringValue(out.EnvironmentID)
+	} else {
+		m.EnvironmentId = types.StringNull()
+	}
 	if out.PoolID != nil && *out.PoolID != "" {
 		m.PoolId = types.StringValue(*out.PoolID)
 	} else {
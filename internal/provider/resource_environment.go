@@ -3,9 +3,12 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -16,34 +19,49 @@ import (
 )
 
 var _ resource.Resource = &EnvironmentResource{}
+var _ resource.ResourceWithIdentity = &EnvironmentResource{}
+var _ resource.ResourceWithModifyPlan = &EnvironmentResource{}
 var _ resource.ResourceWithImportState = &EnvironmentResource{}
+var _ resource.ResourceWithUpgradeState = &EnvironmentResource{}
 
 func NewEnvironmentResource() resource.Resource {
 	return &EnvironmentResource{}
 }
 
 type EnvironmentResource struct {
-	api *client.Client
+	api            *client.Client
+	normalizeCIDRs bool
+	protections    *protectionLedger
 }
 
 type EnvironmentResourceModel struct {
-	Id       types.String `tfsdk:"id"`
-	Name     types.String `tfsdk:"name"`
-	Pools    types.List   `tfsdk:"pools"`     // list of { name, cidr }
-	PoolIds  types.List   `tfsdk:"pool_ids"` // computed: UUIDs of created pools
+	Id                 types.String   `tfsdk:"id"`
+	Name               types.String   `tfsdk:"name"`
+	Pools              types.List     `tfsdk:"pools"`    // list of { name, cidr }
+	PoolIds            types.List     `tfsdk:"pool_ids"` // computed: UUIDs of created pools
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	ForceDestroy       types.Bool     `tfsdk:"force_destroy"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 type poolBlockModel struct {
 	Name types.String `tfsdk:"name"`
-	Cidr types.String `tfsdk:"cidr"`
+	Cidr cidrValue    `tfsdk:"cidr"`
 }
 
+// poolBlockType is the element type of the pools attribute.
+var poolBlockType = types.ObjectType{AttrTypes: map[string]attr.Type{
+	"name": types.StringType,
+	"cidr": cidrType{},
+}}
+
 func (r *EnvironmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_environment"
 }
 
 func (r *EnvironmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             1,
 		MarkdownDescription: "IPAM environment. Environments group network blocks (e.g. prod, staging). Requires at least one pool.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -65,8 +83,9 @@ func (r *EnvironmentResource) Schema(ctx context.Context, req resource.SchemaReq
 							MarkdownDescription: "Pool name.",
 						},
 						"cidr": schema.StringAttribute{
+							CustomType:          cidrType{},
 							Required:            true,
-							MarkdownDescription: "Pool CIDR (e.g. 10.0.0.0/8).",
+							MarkdownDescription: "Pool CIDR (e.g. 10.0.0.0/8). Validated at plan time: must be a valid prefix without host bits set, unless the provider sets `normalize_cidrs`.",
 						},
 					},
 				},
@@ -76,20 +95,37 @@ func (r *EnvironmentResource) Schema(ctx context.Context, req resource.SchemaReq
 				Computed:            true,
 				MarkdownDescription: "UUIDs of pools created with this environment (same order as `pools`).",
 			},
+			"deletion_protection": deletionProtectionAttribute(),
+			"force_destroy":       forceDestroyAttribute("blocks, their allocations and its pools"),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
 		},
 	}
 }
 
+func (r *EnvironmentResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {PriorSchema: &environmentSchemaV0, StateUpgrader: upgradeEnvironmentStateV0},
+	}
+}
+
+func (r *EnvironmentResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = idIdentitySchema("Environment")
+}
+
 func (r *EnvironmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
-	api, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *providerData, got %T", req.ProviderData))
 		return
 	}
-	r.api = api
+	r.api = data.api
+	r.normalizeCIDRs = data.normalizeCIDRs
+	r.protections = data.protections
 }
 
 func (r *EnvironmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -98,6 +134,13 @@ func (r *EnvironmentResource) Create(ctx context.Context, req resource.CreateReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 	var poolBlocks []poolBlockModel
 	resp.Diagnostics.Append(plan.Pools.ElementsAs(ctx, &poolBlocks, false)...)
 	if resp.Diagnostics.HasError() {
@@ -105,15 +148,15 @@ func (r *EnvironmentResource) Create(ctx context.Context, req resource.CreateReq
 	}
 	poolList := make([]client.PoolInput, 0, len(poolBlocks))
 	for _, pm := range poolBlocks {
-		poolList = append(poolList, client.PoolInput{Name: pm.Name.ValueString(), CIDR: pm.Cidr.ValueString()})
+		poolList = append(poolList, client.PoolInput{Name: pm.Name.ValueString(), CIDR: networkCIDR(pm.Cidr.ValueString(), r.normalizeCIDRs)})
 	}
 	if len(poolList) == 0 {
 		resp.Diagnostics.AddError("Invalid config", "at least one pool is required")
 		return
 	}
-	out, err := r.api.CreateEnvironment(plan.Name.ValueString(), poolList)
+	out, err := r.api.CreateEnvironment(ctx, plan.Name.ValueString(), poolList)
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	plan.Id = types.StringValue(out.Id)
@@ -127,6 +170,25 @@ func (r *EnvironmentResource) Create(ctx context.Context, req resource.CreateReq
 	}
 	tflog.Trace(ctx, "created ipam_environment", map[string]interface{}{"id": out.Id})
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: plan.Id}, &resp.Diagnostics)
+}
+
+// ModifyPlan checks the pool CIDRs and enforces deletion_protection at plan time.
+func (r *EnvironmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.Plan.Raw.IsNull() {
+		var pools types.List
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("pools"), &pools)...)
+		for i, elem := range pools.Elements() {
+			obj, ok := elem.(types.Object)
+			if !ok {
+				continue
+			}
+			if cidr, ok := obj.Attributes()["cidr"].(cidrValue); ok {
+				checkHostBits(path.Root("pools").AtListIndex(i).AtName("cidr"), cidr, r.normalizeCIDRs, &resp.Diagnostics)
+			}
+		}
+	}
+	checkDeletionProtection(ctx, req, resp, "ipam_environment")
 }
 
 func (r *EnvironmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -135,33 +197,77 @@ func (r *EnvironmentResource) Read(ctx context.Context, req resource.ReadRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := r.api.GetEnvironment(state.Id.ValueString())
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+	out, err := r.api.GetEnvironment(ctx, state.Id.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	state.Id = types.StringValue(out.Id)
 	state.Name = types.StringValue(out.Name)
-	poolsResp, err := r.api.ListPools(state.Id.ValueString())
-	if err == nil && len(poolsResp.Pools) > 0 {
-		objType := types.ObjectType{AttrTypes: map[string]attr.Type{
-			"name": types.StringType,
-			"cidr": types.StringType,
-		}}
-		elems := make([]attr.Value, 0, len(poolsResp.Pools))
-		poolIdVals := make([]types.String, 0, len(poolsResp.Pools))
-		for _, p := range poolsResp.Pools {
-			obj, _ := types.ObjectValue(objType.AttrTypes, map[string]attr.Value{
-				"name": types.StringValue(p.Name),
-				"cidr": types.StringValue(p.CIDR),
-			})
-			elems = append(elems, obj)
-			poolIdVals = append(poolIdVals, types.StringValue(p.ID))
-		}
-		state.Pools = types.ListValueMust(objType, elems)
-		state.PoolIds, _ = types.ListValueFrom(ctx, types.StringType, poolIdVals)
+	poolsResp, err := r.api.ListPools(ctx, state.Id.ValueString())
+	if err != nil {
+		resp.Diagnostics.Append(apiError(err))
+		return
+	}
+	owned, diags := ownedPools(ctx, poolsResp.Pools, state.PoolIds)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Pools, state.PoolIds = environmentPools(owned)
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
+	if state.ForceDestroy.IsNull() {
+		state.ForceDestroy = types.BoolValue(false)
 	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: state.Id}, &resp.Diagnostics)
+}
+
+// ownedPools returns the pools listed in poolIDs, in that order, so pools managed by separate
+// ipam_pool resources are left out. Pools deleted outside Terraform are dropped. A null poolIDs
+// (an import without a pool list) adopts every pool in the environment.
+func ownedPools(ctx context.Context, pools []client.PoolResponse, poolIDs types.List) ([]client.PoolResponse, diag.Diagnostics) {
+	if poolIDs.IsNull() || poolIDs.IsUnknown() {
+		return pools, nil
+	}
+	var ids []string
+	if diags := poolIDs.ElementsAs(ctx, &ids, false); diags.HasError() {
+		return nil, diags
+	}
+	byID := make(map[string]client.PoolResponse, len(pools))
+	for _, p := range pools {
+		byID[p.ID] = p
+	}
+	owned := make([]client.PoolResponse, 0, len(ids))
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			owned = append(owned, p)
+		}
+	}
+	return owned, nil
+}
+
+// environmentPools converts an environment's pools into the pools and pool_ids attribute values.
+func environmentPools(pools []client.PoolResponse) (types.List, types.List) {
+	elems := make([]attr.Value, 0, len(pools))
+	ids := make([]attr.Value, 0, len(pools))
+	for _, p := range pools {
+		elems = append(elems, types.ObjectValueMust(poolBlockType.AttrTypes, map[string]attr.Value{
+			"name": types.StringValue(p.Name),
+			"cidr": cidrStringValue(p.CIDR),
+		}))
+		ids = append(ids, types.StringValue(p.ID))
+	}
+	return types.ListValueMust(poolBlockType, elems), types.ListValueMust(types.StringType, ids)
 }
 
 func (r *EnvironmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -171,21 +277,97 @@ func (r *EnvironmentResource) Update(ctx context.Context, req resource.UpdateReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := r.api.UpdateEnvironment(plan.Id.ValueString(), plan.Name.ValueString())
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+	out, err := r.api.UpdateEnvironment(ctx, plan.Id.ValueString(), plan.Name.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
-	// Preserve computed pool_ids and pools from state so they remain known after apply.
 	plan.Id = types.StringValue(out.Id)
 	plan.Name = types.StringValue(out.Name)
-	if !state.PoolIds.IsNull() && !state.PoolIds.IsUnknown() {
-		plan.PoolIds = state.PoolIds
-	}
-	if !state.Pools.IsNull() && !state.Pools.IsUnknown() {
-		plan.Pools = state.Pools
+	poolIDs, diags := r.updatePools(ctx, plan, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	plan.PoolIds = poolIDs
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: plan.Id}, &resp.Diagnostics)
+}
+
+// updatePools applies changes to the pools attribute, matching pools by name: a pool whose CIDR
+// changed is updated in place, new names are created and names no longer listed are deleted once
+// the others are in place. Renaming a pool therefore replaces it. It returns the new pool_ids, in
+// the order of pools.
+func (r *EnvironmentResource) updatePools(ctx context.Context, plan, state EnvironmentResourceModel) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var planned, prior []poolBlockModel
+	var priorIDs []string
+	diags.Append(plan.Pools.ElementsAs(ctx, &planned, false)...)
+	diags.Append(state.Pools.ElementsAs(ctx, &prior, false)...)
+	diags.Append(state.PoolIds.ElementsAs(ctx, &priorIDs, false)...)
+	if diags.HasError() {
+		return types.ListNull(types.StringType), diags
+	}
+	if len(planned) == 0 {
+		diags.AddError("Invalid config", "at least one pool is required")
+		return types.ListNull(types.StringType), diags
+	}
+	seen := make(map[string]bool, len(planned))
+	for _, pm := range planned {
+		if seen[pm.Name.ValueString()] {
+			diags.AddAttributeError(path.Root("pools"), "Invalid config", fmt.Sprintf("pool name %q is used more than once.", pm.Name.ValueString()))
+			return types.ListNull(types.StringType), diags
+		}
+		seen[pm.Name.ValueString()] = true
+	}
+	// pool_ids[i] is the pool for pools[i] in state.
+	existing := make(map[string]int, len(prior))
+	for i, pm := range prior {
+		if i < len(priorIDs) {
+			existing[pm.Name.ValueString()] = i
+		}
+	}
+
+	ids := make([]attr.Value, 0, len(planned))
+	kept := make(map[int]bool, len(planned))
+	for _, pm := range planned {
+		name, cidr := pm.Name.ValueString(), networkCIDR(pm.Cidr.ValueString(), r.normalizeCIDRs)
+		i, ok := existing[name]
+		if !ok {
+			out, err := r.api.CreatePool(ctx, plan.Id.ValueString(), name, cidr)
+			if err != nil {
+				diags.Append(apiError(err))
+				return types.ListNull(types.StringType), diags
+			}
+			ids = append(ids, types.StringValue(out.ID))
+			continue
+		}
+		if networkCIDR(prior[i].Cidr.ValueString(), r.normalizeCIDRs) != cidr {
+			if _, err := r.api.UpdatePool(ctx, priorIDs[i], name, cidr); err != nil {
+				diags.Append(apiError(err))
+				return types.ListNull(types.StringType), diags
+			}
+		}
+		kept[i] = true
+		ids = append(ids, types.StringValue(priorIDs[i]))
+	}
+	for i := range priorIDs {
+		if kept[i] {
+			continue
+		}
+		if err := r.api.DeletePool(ctx, priorIDs[i]); err != nil {
+			diags.Append(apiError(err))
+			return types.ListNull(types.StringType), diags
+		}
+	}
+	return types.ListValueMust(types.StringType, ids), diags
 }
 
 func (r *EnvironmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -194,11 +376,55 @@ func (r *EnvironmentResource) Delete(ctx context.Context, req resource.DeleteReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := r.api.DeleteEnvironment(state.Id.ValueString()); err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(deletionProtected("ipam_environment", state.Name.ValueString(), "deleted"))
+		return
+	}
+	if state.ForceDestroy.ValueBool() {
+		var c cascade
+		if err := c.addEnvironment(ctx, r.api, state.Id.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Force destroy stopped", fmt.Sprintf("ipam_environment %q: %s", state.Name.ValueString(), err))
+			return
+		}
+		resp.Diagnostics.Append(c.destroy(ctx, r.api, r.protections, "ipam_environment", state.Name.ValueString())...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if err := r.api.DeleteEnvironment(ctx, state.Id.ValueString()); err != nil {
+		resp.Diagnostics.Append(apiError(err))
 	}
 }
 
+// ImportState imports by UUID or name. A "/pool-a,pool-b" suffix names the pools this resource
+// manages; the environment's other pools are left to ipam_pool resources.
 func (r *EnvironmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	envKey, poolNames, withPools := strings.Cut(req.ID, "/")
+	if !withPools {
+		importByKey(ctx, "ipam_environment", r.api, req, resp, resolveEnvironmentImport)
+		return
+	}
+	req.ID = envKey
+	importByKey(ctx, "ipam_environment", r.api, req, resp, resolveEnvironmentImport)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var id string
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	poolIDs, err := resolveEnvironmentPools(ctx, r.api, id, poolNames)
+	if err != nil {
+		resp.Diagnostics.AddError("Cannot import ipam_environment", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("pool_ids"), poolIDs)...)
 }
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestUpdatePools(t *testing.T) {
+	ctx := context.Background()
+	pools := func(nameCIDRs ...string) types.List {
+		var elems []poolBlockModel
+		for i := 0; i < len(nameCIDRs); i += 2 {
+			elems = append(elems, poolBlockModel{Name: types.StringValue(nameCIDRs[i]), Cidr: cidrStringValue(nameCIDRs[i+1])})
+		}
+		v, diags := types.ListValueFrom(ctx, poolBlockType, elems)
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+		return v
+	}
+	state := EnvironmentResourceModel{
+		Id:      types.StringValue("env-1"),
+		Pools:   pools("a", "10.0.0.0/16", "b", "10.1.0.0/16", "c", "10.2.0.0/16"),
+		PoolIds: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("p-a"), types.StringValue("p-b"), types.StringValue("p-c")}),
+	}
+	tests := []struct {
+		name    string
+		pools   types.List
+		wantIDs []string
+		wantOps []string
+		wantErr bool
+	}{
+		{
+			name:    "remove the first pool",
+			pools:   pools("b", "10.1.0.0/16", "c", "10.2.0.0/16"),
+			wantIDs: []string{"p-b", "p-c"},
+			wantOps: []string{"delete pool a"},
+		},
+		{
+			name:    "reorder",
+			pools:   pools("c", "10.2.0.0/16", "a", "10.0.0.0/16", "b", "10.1.0.0/16"),
+			wantIDs: []string{"p-c", "p-a", "p-b"},
+		},
+		{
+			name:    "change a CIDR, rename and add",
+			pools:   pools("a", "10.3.0.0/16", "b2", "10.1.0.0/16", "c", "10.2.0.0/16", "d", "10.4.0.0/16"),
+			wantIDs: []string{"p-a", "new-1", "p-c", "new-2"},
+			wantOps: []string{"update pool a", "create pool b2", "create pool d", "delete pool b"},
+		},
+		{
+			name:    "duplicate name",
+			pools:   pools("a", "10.0.0.0/16", "a", "10.5.0.0/16"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeAPI{Pools: []client.PoolResponse{
+				{ID: "p-a", EnvironmentID: "env-1", Name: "a", CIDR: "10.0.0.0/16"},
+				{ID: "p-b", EnvironmentID: "env-1", Name: "b", CIDR: "10.1.0.0/16"},
+				{ID: "p-c", EnvironmentID: "env-1", Name: "c", CIDR: "10.2.0.0/16"},
+			}}
+			r := &EnvironmentResource{api: newFakeAPI(t, f)}
+			plan := state
+			plan.Pools = tt.pools
+			got, diags := r.updatePools(ctx, plan, state)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("diags = %v, want error %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				if ops := f.operations(); len(ops) != 0 {
+					t.Errorf("an invalid pools list changed %v", ops)
+				}
+				return
+			}
+			var ids []string
+			got.ElementsAs(ctx, &ids, false)
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("pool_ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if ops := f.operations(); !reflect.DeepEqual(ops, tt.wantOps) {
+				t.Errorf("operations %v, want %v", ops, tt.wantOps)
+			}
+		})
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"fmt"
 
 	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
@@ -15,21 +16,28 @@ import (
 )
 
 var _ resource.Resource = &PoolResource{}
+var _ resource.ResourceWithIdentity = &PoolResource{}
+var _ resource.ResourceWithModifyPlan = &PoolResource{}
 var _ resource.ResourceWithImportState = &PoolResource{}
+var _ resource.ResourceWithUpgradeState = &PoolResource{}
 
 func NewPoolResource() resource.Resource {
 	return &PoolResource{}
 }
 
 type PoolResource struct {
-	api *client.Client
+	api            *client.Client
+	normalizeCIDRs bool
+	protections    *protectionLedger
 }
 
 type PoolResourceModel struct {
-	Id            types.String `tfsdk:"id"`
-	EnvironmentId types.String `tfsdk:"environment_id"`
-	Name          types.String `tfsdk:"name"`
-	Cidr          types.String `tfsdk:"cidr"`
+	Id                 types.String   `tfsdk:"id"`
+	EnvironmentId      types.String   `tfsdk:"environment_id"`
+	Name               types.String   `tfsdk:"name"`
+	Cidr               cidrValue      `tfsdk:"cidr"`
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *PoolResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -38,6 +46,7 @@ func (r *PoolResource) Metadata(ctx context.Context, req resource.MetadataReques
 
 func (r *PoolResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             1,
 		MarkdownDescription: "IPAM environment pool. A pool is a CIDR range that network blocks in an environment can draw from (Pool → Blocks → Allocations).",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -55,8 +64,31 @@ func (r *PoolResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				MarkdownDescription: "Pool name.",
 			},
 			"cidr": schema.StringAttribute{
+				CustomType:          cidrType{},
 				Required:            true,
-				MarkdownDescription: "CIDR range that blocks in this environment can draw from (e.g. 10.0.0.0/8).",
+				MarkdownDescription: "CIDR range that blocks in this environment can draw from (e.g. 10.0.0.0/8). Validated at plan time: must be a valid prefix without host bits set, unless the provider sets `normalize_cidrs`.",
+			},
+			"deletion_protection": deletionProtectionAttribute(),
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+	}
+}
+
+func (r *PoolResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {PriorSchema: &poolSchemaV0, StateUpgrader: upgradePoolStateV0},
+	}
+}
+
+func (r *PoolResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"id": identityIDAttribute("Pool"),
+			"environment_id": identityschema.StringAttribute{
+				OptionalForImport: true,
+				Description:       "UUID of the pool's environment.",
 			},
 		},
 	}
@@ -66,12 +98,14 @@ func (r *PoolResource) Configure(ctx context.Context, req resource.ConfigureRequ
 	if req.ProviderData == nil {
 		return
 	}
-	api, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *providerData, got %T", req.ProviderData))
 		return
 	}
-	r.api = api
+	r.api = data.api
+	r.normalizeCIDRs = data.normalizeCIDRs
+	r.protections = data.protections
 }
 
 func (r *PoolResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -80,17 +114,68 @@ func (r *PoolResource) Create(ctx context.Context, req resource.CreateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := r.api.CreatePool(plan.EnvironmentId.ValueString(), plan.Name.ValueString(), plan.Cidr.ValueString())
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+	out, err := r.api.CreatePool(ctx, plan.EnvironmentId.ValueString(), plan.Name.ValueString(), networkCIDR(plan.Cidr.ValueString(), r.normalizeCIDRs))
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	plan.Id = types.StringValue(out.ID)
 	plan.EnvironmentId = types.StringValue(out.EnvironmentID)
 	plan.Name = types.StringValue(out.Name)
-	plan.Cidr = types.StringValue(out.CIDR)
+	plan.Cidr = cidrStringValue(out.CIDR)
 	tflog.Trace(ctx, "created ipam_pool", map[string]interface{}{"id": out.ID})
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, poolIdentityModel{Id: plan.Id, EnvironmentId: plan.EnvironmentId}, &resp.Diagnostics)
+}
+
+// ModifyPlan checks the CIDR and enforces deletion_protection at plan time.
+func (r *PoolResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.protections.recordPlan(ctx, req)
+	checkPlannedCIDR(ctx, req, resp, r.normalizeCIDRs)
+	if !resp.Diagnostics.HasError() {
+		r.planConflicts(ctx, req, resp)
+	}
+	checkDeletionProtection(ctx, req, resp, "ipam_pool")
+}
+
+// planConflicts checks a new or changed pool CIDR against the environment's other pools and the
+// blocks already drawn from the pool.
+func (r *PoolResource) planConflicts(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.api == nil {
+		return
+	}
+	var plan, state PoolResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	prefix, ok := plannedPrefix(plan.Cidr)
+	if !ok || plan.EnvironmentId.IsUnknown() {
+		return
+	}
+	selfID := ""
+	if !req.State.Raw.IsNull() {
+		if sameNetwork(plan.Cidr.ValueString(), state.Cidr.ValueString()) && plan.EnvironmentId.Equal(state.EnvironmentId) {
+			return
+		}
+		if plan.EnvironmentId.Equal(state.EnvironmentId) {
+			selfID = state.Id.ValueString()
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+	conflicts, err := poolConflicts(ctx, r.api, prefix, selfID, plan.EnvironmentId.ValueString())
+	reportConflicts(&resp.Diagnostics, "ipam_pool", prefix, conflicts, err)
 }
 
 func (r *PoolResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -99,16 +184,27 @@ func (r *PoolResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := r.api.GetPool(state.Id.ValueString())
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+	out, err := r.api.GetPool(ctx, state.Id.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	state.Id = types.StringValue(out.ID)
 	state.EnvironmentId = types.StringValue(out.EnvironmentID)
 	state.Name = types.StringValue(out.Name)
-	state.Cidr = types.StringValue(out.CIDR)
+	state.Cidr = cidrStringValue(out.CIDR)
+	if state.DeletionProtection.IsNull() {
+		state.DeletionProtection = types.BoolValue(false)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	setIdentity(ctx, resp.Identity, poolIdentityModel{Id: state.Id, EnvironmentId: state.EnvironmentId}, &resp.Diagnostics)
 }
 
 func (r *PoolResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -117,16 +213,24 @@ func (r *PoolResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	out, err := r.api.UpdatePool(plan.Id.ValueString(), plan.Name.ValueString(), plan.Cidr.ValueString())
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+	out, err := r.api.UpdatePool(ctx, plan.Id.ValueString(), plan.Name.ValueString(), networkCIDR(plan.Cidr.ValueString(), r.normalizeCIDRs))
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	plan.Id = types.StringValue(out.ID)
 	plan.EnvironmentId = types.StringValue(out.EnvironmentID)
 	plan.Name = types.StringValue(out.Name)
-	plan.Cidr = types.StringValue(out.CIDR)
+	plan.Cidr = cidrStringValue(out.CIDR)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, poolIdentityModel{Id: plan.Id, EnvironmentId: plan.EnvironmentId}, &resp.Diagnostics)
 }
 
 func (r *PoolResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -135,11 +239,22 @@ func (r *PoolResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := r.api.DeletePool(state.Id.ValueString()); err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(deletionProtected("ipam_pool", state.Name.ValueString(), "deleted"))
+		return
+	}
+	if err := r.api.DeletePool(ctx, state.Id.ValueString()); err != nil {
+		resp.Diagnostics.Append(apiError(err))
 	}
 }
 
 func (r *PoolResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importByKey(ctx, "ipam_pool", r.api, req, resp, resolvePoolImport)
 }
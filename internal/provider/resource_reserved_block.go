@@ -6,7 +6,7 @@ import (
 	"strings"
 
 	"github.com/JakeNeyer/terraform-provider-ipam/internal/client"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -16,22 +16,28 @@ import (
 )
 
 var _ resource.Resource = &ReservedBlockResource{}
+var _ resource.ResourceWithIdentity = &ReservedBlockResource{}
+var _ resource.ResourceWithModifyPlan = &ReservedBlockResource{}
 var _ resource.ResourceWithImportState = &ReservedBlockResource{}
+var _ resource.ResourceWithUpgradeState = &ReservedBlockResource{}
 
 func NewReservedBlockResource() resource.Resource {
 	return &ReservedBlockResource{}
 }
 
 type ReservedBlockResource struct {
-	api *client.Client
+	api            *client.Client
+	normalizeCIDRs bool
 }
 
 type ReservedBlockResourceModel struct {
-	Id        types.String `tfsdk:"id"`
-	Name      types.String `tfsdk:"name"`
-	Cidr      types.String `tfsdk:"cidr"`
-	Reason    types.String `tfsdk:"reason"`
-	CreatedAt types.String `tfsdk:"created_at"`
+	Id                 types.String   `tfsdk:"id"`
+	Name               types.String   `tfsdk:"name"`
+	Cidr               cidrValue      `tfsdk:"cidr"`
+	Reason             types.String   `tfsdk:"reason"`
+	CreatedAt          types.String   `tfsdk:"created_at"`
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *ReservedBlockResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -40,6 +46,7 @@ func (r *ReservedBlockResource) Metadata(ctx context.Context, req resource.Metad
 
 func (r *ReservedBlockResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             1,
 		MarkdownDescription: "Reserved CIDR block. Reserved blocks cannot be used as network blocks or allocations (admin only).",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -52,9 +59,10 @@ func (r *ReservedBlockResource) Schema(ctx context.Context, req resource.SchemaR
 				MarkdownDescription: "Optional name for the reserved range.",
 			},
 			"cidr": schema.StringAttribute{
+				CustomType:          cidrType{},
 				Required:            true,
-				MarkdownDescription: "CIDR range to reserve (e.g. 10.0.0.0/8).",
-				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				MarkdownDescription: "CIDR range to reserve (e.g. 10.0.0.0/8). Validated at plan time: must be a valid prefix without host bits set, unless the provider sets `normalize_cidrs`.",
+				PlanModifiers:       []planmodifier.String{cidrRequiresReplace()},
 			},
 			"reason": schema.StringAttribute{
 				Optional:            true,
@@ -65,20 +73,35 @@ func (r *ReservedBlockResource) Schema(ctx context.Context, req resource.SchemaR
 				MarkdownDescription: "Creation time (RFC3339).",
 				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
+			"deletion_protection": deletionProtectionAttribute(),
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
+	}
+}
+
+func (r *ReservedBlockResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {PriorSchema: &reservedBlockSchemaV0, StateUpgrader: upgradeReservedBlockStateV0},
 	}
 }
 
+func (r *ReservedBlockResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = idIdentitySchema("Reserved block")
+}
+
 func (r *ReservedBlockResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
-	api, ok := req.ProviderData.(*client.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
-		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *client.Client, got %T", req.ProviderData))
+		resp.Diagnostics.AddError("Unexpected provider type", fmt.Sprintf("Expected *providerData, got %T", req.ProviderData))
 		return
 	}
-	r.api = api
+	r.api = data.api
+	r.normalizeCIDRs = data.normalizeCIDRs
 }
 
 func (r *ReservedBlockResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -87,21 +110,35 @@ func (r *ReservedBlockResource) Create(ctx context.Context, req resource.CreateR
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
 	name := plan.Name.ValueString()
-	cidr := strings.TrimSpace(plan.Cidr.ValueString())
+	cidr := networkCIDR(strings.TrimSpace(plan.Cidr.ValueString()), r.normalizeCIDRs)
 	reason := plan.Reason.ValueString()
-	out, err := r.api.CreateReservedBlock(name, cidr, reason)
+	out, err := r.api.CreateReservedBlock(ctx, name, cidr, reason)
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	plan.Id = types.StringValue(out.ID)
 	plan.Name = types.StringValue(out.Name)
-	plan.Cidr = types.StringValue(out.CIDR)
+	plan.Cidr = cidrStringValue(out.CIDR)
 	plan.Reason = types.StringValue(out.Reason)
 	plan.CreatedAt = types.StringValue(out.CreatedAt)
 	tflog.Trace(ctx, "created ipam_reserved_block", map[string]interface{}{"id": out.ID})
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: plan.Id}, &resp.Diagnostics)
+}
+
+// ModifyPlan checks the CIDR and enforces deletion_protection at plan time.
+func (r *ReservedBlockResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	checkPlannedCIDR(ctx, req, resp, r.normalizeCIDRs)
+	checkDeletionProtection(ctx, req, resp, "ipam_reserved_block")
 }
 
 func (r *ReservedBlockResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -110,9 +147,16 @@ func (r *ReservedBlockResource) Read(ctx context.Context, req resource.ReadReque
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	list, err := r.api.ListReservedBlocks("")
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+	list, err := r.api.ListReservedBlocks(ctx, "")
 	if err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+		resp.Diagnostics.Append(apiError(err))
 		return
 	}
 	id := state.Id.ValueString()
@@ -120,10 +164,14 @@ func (r *ReservedBlockResource) Read(ctx context.Context, req resource.ReadReque
 		if b.ID == id {
 			state.Id = types.StringValue(b.ID)
 			state.Name = types.StringValue(b.Name)
-			state.Cidr = types.StringValue(b.CIDR)
+			state.Cidr = cidrStringValue(b.CIDR)
 			state.Reason = types.StringValue(b.Reason)
 			state.CreatedAt = types.StringValue(b.CreatedAt)
+			if state.DeletionProtection.IsNull() {
+				state.DeletionProtection = types.BoolValue(false)
+			}
 			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			setIdentity(ctx, resp.Identity, idIdentityModel{Id: state.Id}, &resp.Diagnostics)
 			return
 		}
 	}
@@ -137,11 +185,18 @@ func (r *ReservedBlockResource) Update(ctx context.Context, req resource.UpdateR
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
 	// API supports in-place update of name only; cidr and reason are create-only.
 	if plan.Name.ValueString() != state.Name.ValueString() {
-		out, err := r.api.UpdateReservedBlock(state.Id.ValueString(), plan.Name.ValueString())
+		out, err := r.api.UpdateReservedBlock(ctx, state.Id.ValueString(), plan.Name.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError("API error", err.Error())
+			resp.Diagnostics.Append(apiError(err))
 			return
 		}
 		state.Name = types.StringValue(out.Name)
@@ -150,7 +205,10 @@ func (r *ReservedBlockResource) Update(ctx context.Context, req resource.UpdateR
 	if !plan.Reason.IsNull() {
 		state.Reason = plan.Reason
 	}
+	state.DeletionProtection = plan.DeletionProtection
+	state.Timeouts = plan.Timeouts
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	setIdentity(ctx, resp.Identity, idIdentityModel{Id: state.Id}, &resp.Diagnostics)
 }
 
 func (r *ReservedBlockResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -159,11 +217,22 @@ func (r *ReservedBlockResource) Delete(ctx context.Context, req resource.DeleteR
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := r.api.DeleteReservedBlock(state.Id.ValueString()); err != nil {
-		resp.Diagnostics.AddError("API error", err.Error())
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.Append(deletionProtected("ipam_reserved_block", state.Name.ValueString(), "deleted"))
+		return
+	}
+	if err := r.api.DeleteReservedBlock(ctx, state.Id.ValueString()); err != nil {
+		resp.Diagnostics.Append(apiError(err))
 	}
 }
 
 func (r *ReservedBlockResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importByKey(ctx, "ipam_reserved_block", r.api, req, resp, resolveReservedBlockImport)
 }
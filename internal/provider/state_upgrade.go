@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Version 0 is every resource schema as released before schema versions were declared: no
+// deletion_protection, force_destroy or timeouts, and allocations referenced their block by name
+// only. The v0 upgraders copy the old attributes and fill the new ones with the values Read or
+// the schema defaults would give them, so the first plan after upgrading is empty.
+// ipam_allocation_set was added after that release, so it has no version 0 state and no upgrader.
+//
+// When a schema changes shape, bump its Version, add a frozen copy of the previous schema and
+// model here and an upgrader from it, plus a fixture under testdata/state.
+
+type environmentResourceModelV0 struct {
+	Id      types.String `tfsdk:"id"`
+	Name    types.String `tfsdk:"name"`
+	Pools   types.List   `tfsdk:"pools"`
+	PoolIds types.List   `tfsdk:"pool_ids"`
+}
+
+type poolBlockModelV0 struct {
+	Name types.String `tfsdk:"name"`
+	Cidr types.String `tfsdk:"cidr"`
+}
+
+var environmentSchemaV0 = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"id":   schema.StringAttribute{Computed: true},
+		"name": schema.StringAttribute{Required: true},
+		"pools": schema.ListNestedAttribute{
+			Required: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"name": schema.StringAttribute{Required: true},
+					"cidr": schema.StringAttribute{Required: true},
+				},
+			},
+		},
+		"pool_ids": schema.ListAttribute{ElementType: types.StringType, Computed: true},
+	},
+}
+
+func upgradeEnvironmentStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior environmentResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	pools := types.ListNull(poolBlockType)
+	if !prior.Pools.IsNull() {
+		var priorPools []poolBlockModelV0
+		resp.Diagnostics.Append(prior.Pools.ElementsAs(ctx, &priorPools, false)...)
+		upgraded := make([]poolBlockModel, 0, len(priorPools))
+		for _, p := range priorPools {
+			upgraded = append(upgraded, poolBlockModel{Name: p.Name, Cidr: cidrValue{StringValue: p.Cidr}})
+		}
+		var diags diag.Diagnostics
+		pools, diags = types.ListValueFrom(ctx, poolBlockType, upgraded)
+		resp.Diagnostics.Append(diags...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, EnvironmentResourceModel{
+		Id:                 prior.Id,
+		Name:               prior.Name,
+		Pools:              pools,
+		PoolIds:            prior.PoolIds,
+		DeletionProtection: types.BoolValue(false),
+		ForceDestroy:       types.BoolValue(false),
+		Timeouts:           nullTimeouts(),
+	})...)
+}
+
+type poolResourceModelV0 struct {
+	Id            types.String `tfsdk:"id"`
+	EnvironmentId types.String `tfsdk:"environment_id"`
+	Name          types.String `tfsdk:"name"`
+	Cidr          types.String `tfsdk:"cidr"`
+}
+
+var poolSchemaV0 = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"id":             schema.StringAttribute{Computed: true},
+		"environment_id": schema.StringAttribute{Required: true},
+		"name":           schema.StringAttribute{Required: true},
+		"cidr":           schema.StringAttribute{Required: true},
+	},
+}
+
+func upgradePoolStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior poolResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, PoolResourceModel{
+		Id:                 prior.Id,
+		EnvironmentId:      prior.EnvironmentId,
+		Name:               prior.Name,
+		Cidr:               cidrValue{StringValue: prior.Cidr},
+		DeletionProtection: types.BoolValue(false),
+		Timeouts:           nullTimeouts(),
+	})...)
+}
+
+type blockResourceModelV0 struct {
+	Id            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	Cidr          types.String `tfsdk:"cidr"`
+	TotalIps      types.String `tfsdk:"total_ips"`
+	UsedIps       types.String `tfsdk:"used_ips"`
+	AvailableIps  types.String `tfsdk:"available_ips"`
+	EnvironmentId types.String `tfsdk:"environment_id"`
+	PoolId        types.String `tfsdk:"pool_id"`
+}
+
+var blockSchemaV0 = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"id":             schema.StringAttribute{Computed: true},
+		"name":           schema.StringAttribute{Required: true},
+		"cidr":           schema.StringAttribute{Required: true},
+		"environment_id": schema.StringAttribute{Optional: true},
+		"pool_id":        schema.StringAttribute{Optional: true},
+		"total_ips":      schema.StringAttribute{Computed: true},
+		"used_ips":       schema.StringAttribute{Computed: true},
+		"available_ips":  schema.StringAttribute{Computed: true},
+	},
+}
+
+func upgradeBlockStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior blockResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Version 0 stored orphaned blocks with an empty environment_id; Read now leaves it null.
+	if prior.EnvironmentId.ValueString() == "" {
+		prior.EnvironmentId = types.StringNull()
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, BlockResourceModel{
+		Id:                 prior.Id,
+		Name:               prior.Name,
+		Cidr:               cidrValue{StringValue: prior.Cidr},
+		TotalIps:           prior.TotalIps,
+		UsedIps:            prior.UsedIps,
+		AvailableIps:       prior.AvailableIps,
+		EnvironmentId:      prior.EnvironmentId,
+		PoolId:             prior.PoolId,
+		DeletionProtection: types.BoolValue(false),
+		ForceDestroy:       types.BoolValue(false),
+		Timeouts:           nullTimeouts(),
+	})...)
+}
+
+type allocationResourceModelV0 struct {
+	Id           types.String `tfsdk:"id"`
+	Name         types.String `tfsdk:"name"`
+	BlockName    types.String `tfsdk:"block_name"`
+	Cidr         types.String `tfsdk:"cidr"`
+	PrefixLength types.Int64  `tfsdk:"prefix_length"`
+}
+
+var allocationSchemaV0 = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"id":            schema.StringAttribute{Computed: true},
+		"name":          schema.StringAttribute{Required: true},
+		"block_name":    schema.StringAttribute{Required: true},
+		"cidr":          schema.StringAttribute{Optional: true, Computed: true},
+		"prefix_length": schema.Int64Attribute{Optional: true},
+	},
+}
+
+// upgradeAllocationStateV0 leaves block_id null; the next Read resolves it from the API.
+func upgradeAllocationStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior allocationResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, AllocationResourceModel{
+		Id:                 prior.Id,
+		Name:               prior.Name,
+		BlockName:          prior.BlockName,
+		BlockId:            types.StringNull(),
+		Cidr:               cidrValue{StringValue: prior.Cidr},
+		PrefixLength:       prior.PrefixLength,
+		WithinCidr:         types.StringNull(),
+		ExcludeCidrs:       types.ListNull(types.StringType),
+		Strategy:           types.StringNull(),
+		MinGap:             types.Int64Null(),
+		PredictCidr:        types.BoolValue(false),
+		DeletionProtection: types.BoolValue(false),
+		Timeouts:           nullTimeouts(),
+	})...)
+}
+
+type reservedBlockResourceModelV0 struct {
+	Id        types.String `tfsdk:"id"`
+	Name      types.String `tfsdk:"name"`
+	Cidr      types.String `tfsdk:"cidr"`
+	Reason    types.String `tfsdk:"reason"`
+	CreatedAt types.String `tfsdk:"created_at"`
+}
+
+var reservedBlockSchemaV0 = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"id":         schema.StringAttribute{Computed: true},
+		"name":       schema.StringAttribute{Optional: true},
+		"cidr":       schema.StringAttribute{Required: true},
+		"reason":     schema.StringAttribute{Optional: true},
+		"created_at": schema.StringAttribute{Computed: true},
+	},
+}
+
+func upgradeReservedBlockStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior reservedBlockResourceModelV0
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, ReservedBlockResourceModel{
+		Id:                 prior.Id,
+		Name:               prior.Name,
+		Cidr:               cidrValue{StringValue: prior.Cidr},
+		Reason:             prior.Reason,
+		CreatedAt:          prior.CreatedAt,
+		DeletionProtection: types.BoolValue(false),
+		Timeouts:           nullTimeouts(),
+	})...)
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// upgradeFixture sends a state fixture written by an older provider version through the
+// provider's UpgradeResourceState and returns the result in the current schema.
+func upgradeFixture(t *testing.T, r resource.Resource, typeName, fixture string, version int64) tfsdk.State {
+	t.Helper()
+	ctx := context.Background()
+	raw, err := os.ReadFile(filepath.Join("testdata", "state", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := providerserver.NewProtocol6(New("test")())()
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: raw},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("%s: %s", d.Summary, d.Detail)
+	}
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	val, err := resp.UpgradedState.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tfsdk.State{Schema: schemaResp.Schema, Raw: val}
+}
+
+// checkNewDefaults checks the attributes added since version 0 got their default values.
+func checkNewDefaults(t *testing.T, deletionProtection types.Bool, to timeouts.Value) {
+	t.Helper()
+	if deletionProtection != types.BoolValue(false) {
+		t.Errorf("deletion_protection = %s, want false", deletionProtection)
+	}
+	if !to.IsNull() {
+		t.Error("timeouts should be null")
+	}
+}
+
+func TestUpgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ipam_environment", func(t *testing.T) {
+		var m EnvironmentResourceModel
+		state := upgradeFixture(t, NewEnvironmentResource(), "ipam_environment", "v0/ipam_environment.json", 0)
+		if diags := state.Get(ctx, &m); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if m.Name.ValueString() != "prod" || len(m.Pools.Elements()) != 1 || len(m.PoolIds.Elements()) != 1 {
+			t.Errorf("prior attributes not kept: %+v", m)
+		}
+		var pools []poolBlockModel
+		if diags := m.Pools.ElementsAs(ctx, &pools, false); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if pools[0].Name.ValueString() != "prod-pool" || pools[0].Cidr.ValueString() != "10.0.0.0/8" {
+			t.Errorf("pools = %+v", pools)
+		}
+		if m.ForceDestroy != types.BoolValue(false) {
+			t.Errorf("force_destroy = %s, want false", m.ForceDestroy)
+		}
+		checkNewDefaults(t, m.DeletionProtection, m.Timeouts)
+	})
+
+	t.Run("ipam_pool", func(t *testing.T) {
+		var m PoolResourceModel
+		state := upgradeFixture(t, NewPoolResource(), "ipam_pool", "v0/ipam_pool.json", 0)
+		if diags := state.Get(ctx, &m); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if m.EnvironmentId.ValueString() != "0b7a3c1e-5d2f-4e8a-9c61-2f4d8e7b1a90" || m.Cidr.ValueString() != "172.16.0.0/12" {
+			t.Errorf("prior attributes not kept: %+v", m)
+		}
+		checkNewDefaults(t, m.DeletionProtection, m.Timeouts)
+	})
+
+	t.Run("ipam_block", func(t *testing.T) {
+		var m BlockResourceModel
+		state := upgradeFixture(t, NewBlockResource(), "ipam_block", "v0/ipam_block.json", 0)
+		if diags := state.Get(ctx, &m); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if m.Cidr.ValueString() != "10.1.0.0/16" || m.UsedIps.ValueString() != "256" || m.PoolId.ValueString() != "5e1c9a2b-7f3d-4b6e-8a01-c2d3e4f5a6b7" {
+			t.Errorf("prior attributes not kept: %+v", m)
+		}
+		if m.ForceDestroy != types.BoolValue(false) {
+			t.Errorf("force_destroy = %s, want false", m.ForceDestroy)
+		}
+		checkNewDefaults(t, m.DeletionProtection, m.Timeouts)
+	})
+
+	t.Run("ipam_block orphaned", func(t *testing.T) {
+		var m BlockResourceModel
+		state := upgradeFixture(t, NewBlockResource(), "ipam_block", "v0/ipam_block_orphaned.json", 0)
+		if diags := state.Get(ctx, &m); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if !m.EnvironmentId.IsNull() {
+			t.Errorf("environment_id = %s, want null", m.EnvironmentId)
+		}
+	})
+
+	t.Run("ipam_allocation", func(t *testing.T) {
+		var m AllocationResourceModel
+		state := upgradeFixture(t, NewAllocationResource(), "ipam_allocation", "v0/ipam_allocation.json", 0)
+		if diags := state.Get(ctx, &m); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if m.BlockName.ValueString() != "prod-vpc" || m.Cidr.ValueString() != "10.1.0.0/24" || m.PrefixLength.ValueInt64() != 24 {
+			t.Errorf("prior attributes not kept: %+v", m)
+		}
+		if !m.BlockId.IsNull() || !m.WithinCidr.IsNull() || !m.ExcludeCidrs.IsNull() || !m.Strategy.IsNull() || !m.MinGap.IsNull() {
+			t.Errorf("new attributes should be null: %+v", m)
+		}
+		if m.PredictCidr.IsNull() || m.PredictCidr.ValueBool() {
+			t.Errorf("predict_cidr = %s, want false", m.PredictCidr)
+		}
+		checkNewDefaults(t, m.DeletionProtection, m.Timeouts)
+	})
+
+	t.Run("ipam_reserved_block", func(t *testing.T) {
+		var m ReservedBlockResourceModel
+		state := upgradeFixture(t, NewReservedBlockResource(), "ipam_reserved_block", "v0/ipam_reserved_block.json", 0)
+		if diags := state.Get(ctx, &m); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if m.Reason.ValueString() != "Corporate VPN range" || m.CreatedAt.ValueString() != "2025-11-03T09:14:22Z" {
+			t.Errorf("prior attributes not kept: %+v", m)
+		}
+		checkNewDefaults(t, m.DeletionProtection, m.Timeouts)
+	})
+}